@@ -0,0 +1,1268 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.1
+// 	protoc        (unknown)
+// source: topaz/authorizer/v1/authorizer.proto
+
+package authorizer
+
+import (
+	v2 "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	status "google.golang.org/genproto/googleapis/rpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SQLDialect int32
+
+const (
+	SQLDialect_SQL_DIALECT_UNKNOWN  SQLDialect = 0
+	SQLDialect_SQL_DIALECT_POSTGRES SQLDialect = 1
+	SQLDialect_SQL_DIALECT_SQLITE   SQLDialect = 2
+)
+
+// Enum value maps for SQLDialect.
+var (
+	SQLDialect_name = map[int32]string{
+		0: "SQL_DIALECT_UNKNOWN",
+		1: "SQL_DIALECT_POSTGRES",
+		2: "SQL_DIALECT_SQLITE",
+	}
+	SQLDialect_value = map[string]int32{
+		"SQL_DIALECT_UNKNOWN":  0,
+		"SQL_DIALECT_POSTGRES": 1,
+		"SQL_DIALECT_SQLITE":   2,
+	}
+)
+
+func (x SQLDialect) Enum() *SQLDialect {
+	p := new(SQLDialect)
+	*p = x
+	return p
+}
+
+func (x SQLDialect) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SQLDialect) Descriptor() protoreflect.EnumDescriptor {
+	return file_topaz_authorizer_v1_authorizer_proto_enumTypes[0].Descriptor()
+}
+
+func (SQLDialect) Type() protoreflect.EnumType {
+	return &file_topaz_authorizer_v1_authorizer_proto_enumTypes[0]
+}
+
+func (x SQLDialect) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SQLDialect.Descriptor instead.
+func (SQLDialect) EnumDescriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{0}
+}
+
+type FilterOperator int32
+
+const (
+	FilterOperator_FILTER_OPERATOR_UNKNOWN FilterOperator = 0
+	FilterOperator_FILTER_OPERATOR_AND     FilterOperator = 1
+	FilterOperator_FILTER_OPERATOR_OR      FilterOperator = 2
+	FilterOperator_FILTER_OPERATOR_NOT     FilterOperator = 3
+	FilterOperator_FILTER_OPERATOR_EQ      FilterOperator = 4
+	FilterOperator_FILTER_OPERATOR_NE      FilterOperator = 5
+	FilterOperator_FILTER_OPERATOR_LT      FilterOperator = 6
+	FilterOperator_FILTER_OPERATOR_LTE     FilterOperator = 7
+	FilterOperator_FILTER_OPERATOR_GT      FilterOperator = 8
+	FilterOperator_FILTER_OPERATOR_GTE     FilterOperator = 9
+	FilterOperator_FILTER_OPERATOR_IN      FilterOperator = 10
+	FilterOperator_FILTER_OPERATOR_TRUE    FilterOperator = 11
+	FilterOperator_FILTER_OPERATOR_FALSE   FilterOperator = 12
+)
+
+// Enum value maps for FilterOperator.
+var (
+	FilterOperator_name = map[int32]string{
+		0:  "FILTER_OPERATOR_UNKNOWN",
+		1:  "FILTER_OPERATOR_AND",
+		2:  "FILTER_OPERATOR_OR",
+		3:  "FILTER_OPERATOR_NOT",
+		4:  "FILTER_OPERATOR_EQ",
+		5:  "FILTER_OPERATOR_NE",
+		6:  "FILTER_OPERATOR_LT",
+		7:  "FILTER_OPERATOR_LTE",
+		8:  "FILTER_OPERATOR_GT",
+		9:  "FILTER_OPERATOR_GTE",
+		10: "FILTER_OPERATOR_IN",
+		11: "FILTER_OPERATOR_TRUE",
+		12: "FILTER_OPERATOR_FALSE",
+	}
+	FilterOperator_value = map[string]int32{
+		"FILTER_OPERATOR_UNKNOWN": 0,
+		"FILTER_OPERATOR_AND":     1,
+		"FILTER_OPERATOR_OR":      2,
+		"FILTER_OPERATOR_NOT":     3,
+		"FILTER_OPERATOR_EQ":      4,
+		"FILTER_OPERATOR_NE":      5,
+		"FILTER_OPERATOR_LT":      6,
+		"FILTER_OPERATOR_LTE":     7,
+		"FILTER_OPERATOR_GT":      8,
+		"FILTER_OPERATOR_GTE":     9,
+		"FILTER_OPERATOR_IN":      10,
+		"FILTER_OPERATOR_TRUE":    11,
+		"FILTER_OPERATOR_FALSE":   12,
+	}
+)
+
+func (x FilterOperator) Enum() *FilterOperator {
+	p := new(FilterOperator)
+	*p = x
+	return p
+}
+
+func (x FilterOperator) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FilterOperator) Descriptor() protoreflect.EnumDescriptor {
+	return file_topaz_authorizer_v1_authorizer_proto_enumTypes[1].Descriptor()
+}
+
+func (FilterOperator) Type() protoreflect.EnumType {
+	return &file_topaz_authorizer_v1_authorizer_proto_enumTypes[1]
+}
+
+func (x FilterOperator) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FilterOperator.Descriptor instead.
+func (FilterOperator) EnumDescriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{1}
+}
+
+type IsBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// requests to evaluate, each request carries its own identity, policy and resource context.
+	Requests []*v2.IsRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *IsBatchRequest) Reset() {
+	*x = IsBatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsBatchRequest) ProtoMessage() {}
+
+func (x *IsBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsBatchRequest.ProtoReflect.Descriptor instead.
+func (*IsBatchRequest) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IsBatchRequest) GetRequests() []*v2.IsRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type IsBatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// results in request order.
+	Results []*IsBatchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *IsBatchResponse) Reset() {
+	*x = IsBatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsBatchResponse) ProtoMessage() {}
+
+func (x *IsBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsBatchResponse.ProtoReflect.Descriptor instead.
+func (*IsBatchResponse) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IsBatchResponse) GetResults() []*IsBatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type IsBatchResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Result:
+	//	*IsBatchResult_Response
+	//	*IsBatchResult_Error
+	Result isIsBatchResult_Result `protobuf_oneof:"result"`
+}
+
+func (x *IsBatchResult) Reset() {
+	*x = IsBatchResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsBatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsBatchResult) ProtoMessage() {}
+
+func (x *IsBatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsBatchResult.ProtoReflect.Descriptor instead.
+func (*IsBatchResult) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{2}
+}
+
+func (m *IsBatchResult) GetResult() isIsBatchResult_Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (x *IsBatchResult) GetResponse() *v2.IsResponse {
+	if x, ok := x.GetResult().(*IsBatchResult_Response); ok {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *IsBatchResult) GetError() *status.Status {
+	if x, ok := x.GetResult().(*IsBatchResult_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+type isIsBatchResult_Result interface {
+	isIsBatchResult_Result()
+}
+
+type IsBatchResult_Response struct {
+	// decisions of the request.
+	Response *v2.IsResponse `protobuf:"bytes,1,opt,name=response,proto3,oneof"`
+}
+
+type IsBatchResult_Error struct {
+	// error of the request, a failed request does not fail the batch.
+	Error *status.Status `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*IsBatchResult_Response) isIsBatchResult_Result() {}
+
+func (*IsBatchResult_Error) isIsBatchResult_Result() {}
+
+type IsExplainRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// request to evaluate.
+	Request *v2.IsRequest `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	// explanation detail, full (default) reports all rule bodies, fails only the rule bodies
+	// that evaluated false and notes only the directory checks and trace notes.
+	Trace v2.TraceLevel `protobuf:"varint,2,opt,name=trace,proto3,enum=aserto.authorizer.v2.TraceLevel" json:"trace,omitempty"`
+}
+
+func (x *IsExplainRequest) Reset() {
+	*x = IsExplainRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsExplainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsExplainRequest) ProtoMessage() {}
+
+func (x *IsExplainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsExplainRequest.ProtoReflect.Descriptor instead.
+func (*IsExplainRequest) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IsExplainRequest) GetRequest() *v2.IsRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *IsExplainRequest) GetTrace() v2.TraceLevel {
+	if x != nil {
+		return x.Trace
+	}
+	return v2.TraceLevel(0)
+}
+
+type IsExplainResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// decisions of the request.
+	Decisions []*v2.Decision `protobuf:"bytes,1,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	// explanation of the decisions.
+	Explanation *structpb.Struct `protobuf:"bytes,2,opt,name=explanation,proto3" json:"explanation,omitempty"`
+}
+
+func (x *IsExplainResponse) Reset() {
+	*x = IsExplainResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsExplainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsExplainResponse) ProtoMessage() {}
+
+func (x *IsExplainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsExplainResponse.ProtoReflect.Descriptor instead.
+func (*IsExplainResponse) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *IsExplainResponse) GetDecisions() []*v2.Decision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+func (x *IsExplainResponse) GetExplanation() *structpb.Struct {
+	if x != nil {
+		return x.Explanation
+	}
+	return nil
+}
+
+type IsStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// correlation id of the request, returned in its response.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// request to evaluate.
+	Request *v2.IsRequest `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+}
+
+func (x *IsStreamRequest) Reset() {
+	*x = IsStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsStreamRequest) ProtoMessage() {}
+
+func (x *IsStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsStreamRequest.ProtoReflect.Descriptor instead.
+func (*IsStreamRequest) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *IsStreamRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *IsStreamRequest) GetRequest() *v2.IsRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+type IsStreamResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// correlation id of the request.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are assignable to Result:
+	//	*IsStreamResponse_Response
+	//	*IsStreamResponse_Error
+	Result isIsStreamResponse_Result `protobuf_oneof:"result"`
+}
+
+func (x *IsStreamResponse) Reset() {
+	*x = IsStreamResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsStreamResponse) ProtoMessage() {}
+
+func (x *IsStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsStreamResponse.ProtoReflect.Descriptor instead.
+func (*IsStreamResponse) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *IsStreamResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (m *IsStreamResponse) GetResult() isIsStreamResponse_Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (x *IsStreamResponse) GetResponse() *v2.IsResponse {
+	if x, ok := x.GetResult().(*IsStreamResponse_Response); ok {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *IsStreamResponse) GetError() *status.Status {
+	if x, ok := x.GetResult().(*IsStreamResponse_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+type isIsStreamResponse_Result interface {
+	isIsStreamResponse_Result()
+}
+
+type IsStreamResponse_Response struct {
+	// decisions of the request.
+	Response *v2.IsResponse `protobuf:"bytes,2,opt,name=response,proto3,oneof"`
+}
+
+type IsStreamResponse_Error struct {
+	// error of the request, a failed request does not end the stream.
+	Error *status.Status `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*IsStreamResponse_Response) isIsStreamResponse_Result() {}
+
+func (*IsStreamResponse_Error) isIsStreamResponse_Result() {}
+
+type CompileFilterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// compile request, the unknowns default to input.resource.
+	Compile *v2.CompileRequest `protobuf:"bytes,1,opt,name=compile,proto3" json:"compile,omitempty"`
+	// column names of the resource fields, keyed by reference (e.g. input.resource.owner_id),
+	// unmapped top level resource fields use the field name as column name.
+	Columns map[string]string `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// SQL dialect of the WHERE clause, no WHERE clause is returned when not set.
+	Dialect SQLDialect `protobuf:"varint,3,opt,name=dialect,proto3,enum=topaz.authorizer.v1.SQLDialect" json:"dialect,omitempty"`
+}
+
+func (x *CompileFilterRequest) Reset() {
+	*x = CompileFilterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompileFilterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompileFilterRequest) ProtoMessage() {}
+
+func (x *CompileFilterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompileFilterRequest.ProtoReflect.Descriptor instead.
+func (*CompileFilterRequest) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CompileFilterRequest) GetCompile() *v2.CompileRequest {
+	if x != nil {
+		return x.Compile
+	}
+	return nil
+}
+
+func (x *CompileFilterRequest) GetColumns() map[string]string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *CompileFilterRequest) GetDialect() SQLDialect {
+	if x != nil {
+		return x.Dialect
+	}
+	return SQLDialect_SQL_DIALECT_UNKNOWN
+}
+
+type CompileFilterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// filter expression, rows matching the expression satisfy the query.
+	Filter *FilterExpression `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// SQL WHERE clause of the filter expression, set when a dialect is requested.
+	Sql *SQLFilter `protobuf:"bytes,2,opt,name=sql,proto3" json:"sql,omitempty"`
+}
+
+func (x *CompileFilterResponse) Reset() {
+	*x = CompileFilterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompileFilterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompileFilterResponse) ProtoMessage() {}
+
+func (x *CompileFilterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompileFilterResponse.ProtoReflect.Descriptor instead.
+func (*CompileFilterResponse) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CompileFilterResponse) GetFilter() *FilterExpression {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *CompileFilterResponse) GetSql() *SQLFilter {
+	if x != nil {
+		return x.Sql
+	}
+	return nil
+}
+
+type FilterExpression struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Operator FilterOperator `protobuf:"varint,1,opt,name=operator,proto3,enum=topaz.authorizer.v1.FilterOperator" json:"operator,omitempty"`
+	// column of a comparison or in expression.
+	Column string `protobuf:"bytes,2,opt,name=column,proto3" json:"column,omitempty"`
+	// value compared with the column, the list of values of an in expression.
+	Value *structpb.Value `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	// operands of and, or and not expressions.
+	Operands []*FilterExpression `protobuf:"bytes,4,rep,name=operands,proto3" json:"operands,omitempty"`
+}
+
+func (x *FilterExpression) Reset() {
+	*x = FilterExpression{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FilterExpression) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterExpression) ProtoMessage() {}
+
+func (x *FilterExpression) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterExpression.ProtoReflect.Descriptor instead.
+func (*FilterExpression) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FilterExpression) GetOperator() FilterOperator {
+	if x != nil {
+		return x.Operator
+	}
+	return FilterOperator_FILTER_OPERATOR_UNKNOWN
+}
+
+func (x *FilterExpression) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *FilterExpression) GetValue() *structpb.Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *FilterExpression) GetOperands() []*FilterExpression {
+	if x != nil {
+		return x.Operands
+	}
+	return nil
+}
+
+type SQLFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// WHERE clause, without the WHERE keyword, values are passed as positional parameters.
+	Where string `protobuf:"bytes,1,opt,name=where,proto3" json:"where,omitempty"`
+	// parameter values, in placeholder order.
+	Args []*structpb.Value `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+}
+
+func (x *SQLFilter) Reset() {
+	*x = SQLFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQLFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQLFilter) ProtoMessage() {}
+
+func (x *SQLFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_topaz_authorizer_v1_authorizer_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQLFilter.ProtoReflect.Descriptor instead.
+func (*SQLFilter) Descriptor() ([]byte, []int) {
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SQLFilter) GetWhere() string {
+	if x != nil {
+		return x.Where
+	}
+	return ""
+}
+
+func (x *SQLFilter) GetArgs() []*structpb.Value {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+var File_topaz_authorizer_v1_authorizer_proto protoreflect.FileDescriptor
+
+var file_topaz_authorizer_v1_authorizer_proto_rawDesc = []byte{
+	0x0a, 0x24, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a,
+	0x65, 0x72, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x13, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75,
+	0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x1a, 0x25, 0x61, 0x73, 0x65,
+	0x72, 0x74, 0x6f, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2f, 0x76,
+	0x32, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61,
+	0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x17,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x4d, 0x0a, 0x0e, 0x49, 0x73, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3b, 0x0a, 0x08, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x73,
+	0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e,
+	0x76, 0x32, 0x2e, 0x49, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x22, 0x4f, 0x0a, 0x0f, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x07, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x74, 0x6f, 0x70,
+	0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x85, 0x01, 0x0a, 0x0d, 0x49, 0x73, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x3e, 0x0a, 0x08, 0x72, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x61, 0x73,
+	0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e,
+	0x76, 0x32, 0x2e, 0x49, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52,
+	0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x48, 0x00, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22,
+	0x85, 0x01, 0x0a, 0x10, 0x49, 0x73, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x39, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61,
+	0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x49, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x36, 0x0a, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20,
+	0x2e, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a,
+	0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x65, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x52, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x22, 0x8c, 0x01, 0x0a, 0x11, 0x49, 0x73, 0x45, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a,
+	0x09, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72,
+	0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x09, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x39, 0x0a, 0x0b, 0x65,
+	0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x0b, 0x65, 0x78, 0x70, 0x6c, 0x61,
+	0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x5c, 0x0a, 0x0f, 0x49, 0x73, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x39, 0x0a, 0x07, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x73, 0x65,
+	0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76,
+	0x32, 0x2e, 0x49, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x98, 0x01, 0x0a, 0x10, 0x49, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x3e, 0x0a, 0x08, 0x72, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x61, 0x73,
+	0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e,
+	0x76, 0x32, 0x2e, 0x49, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52,
+	0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x48, 0x00, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22,
+	0x9f, 0x02, 0x0a, 0x14, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3e, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x70,
+	0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x61, 0x73, 0x65, 0x72,
+	0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32,
+	0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x12, 0x50, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75,
+	0x6d, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x36, 0x2e, 0x74, 0x6f, 0x70, 0x61,
+	0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x39, 0x0a, 0x07, 0x64, 0x69,
+	0x61, 0x6c, 0x65, 0x63, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x74, 0x6f,
+	0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x51, 0x4c, 0x44, 0x69, 0x61, 0x6c, 0x65, 0x63, 0x74, 0x52, 0x07, 0x64, 0x69,
+	0x61, 0x6c, 0x65, 0x63, 0x74, 0x1a, 0x3a, 0x0a, 0x0c, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0x88, 0x01, 0x0a, 0x15, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x06, 0x66,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x74, 0x6f,
+	0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x30, 0x0a, 0x03, 0x73, 0x71,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e,
+	0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x51,
+	0x4c, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x03, 0x73, 0x71, 0x6c, 0x22, 0xdc, 0x01, 0x0a,
+	0x10, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x3f, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x23, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68,
+	0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74,
+	0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x2c, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x41, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x72,
+	0x61, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x74, 0x6f, 0x70,
+	0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x6e, 0x64, 0x73, 0x22, 0x4d, 0x0a, 0x09, 0x53,
+	0x51, 0x4c, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x68, 0x65, 0x72,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x77, 0x68, 0x65, 0x72, 0x65, 0x12, 0x2a,
+	0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x2a, 0x57, 0x0a, 0x0a, 0x53, 0x51,
+	0x4c, 0x44, 0x69, 0x61, 0x6c, 0x65, 0x63, 0x74, 0x12, 0x17, 0x0a, 0x13, 0x53, 0x51, 0x4c, 0x5f,
+	0x44, 0x49, 0x41, 0x4c, 0x45, 0x43, 0x54, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10,
+	0x00, 0x12, 0x18, 0x0a, 0x14, 0x53, 0x51, 0x4c, 0x5f, 0x44, 0x49, 0x41, 0x4c, 0x45, 0x43, 0x54,
+	0x5f, 0x50, 0x4f, 0x53, 0x54, 0x47, 0x52, 0x45, 0x53, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x53,
+	0x51, 0x4c, 0x5f, 0x44, 0x49, 0x41, 0x4c, 0x45, 0x43, 0x54, 0x5f, 0x53, 0x51, 0x4c, 0x49, 0x54,
+	0x45, 0x10, 0x02, 0x2a, 0xd6, 0x02, 0x0a, 0x0e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1b, 0x0a, 0x17, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52,
+	0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57,
+	0x4e, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f, 0x4f, 0x50,
+	0x45, 0x52, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x41, 0x4e, 0x44, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12,
+	0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52, 0x5f,
+	0x4f, 0x52, 0x10, 0x02, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f, 0x4f,
+	0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x4e, 0x4f, 0x54, 0x10, 0x03, 0x12, 0x16, 0x0a,
+	0x12, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52,
+	0x5f, 0x45, 0x51, 0x10, 0x04, 0x12, 0x16, 0x0a, 0x12, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f,
+	0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x4e, 0x45, 0x10, 0x05, 0x12, 0x16, 0x0a,
+	0x12, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52,
+	0x5f, 0x4c, 0x54, 0x10, 0x06, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f,
+	0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x4c, 0x54, 0x45, 0x10, 0x07, 0x12, 0x16,
+	0x0a, 0x12, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f,
+	0x52, 0x5f, 0x47, 0x54, 0x10, 0x08, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52,
+	0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x47, 0x54, 0x45, 0x10, 0x09, 0x12,
+	0x16, 0x0a, 0x12, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54,
+	0x4f, 0x52, 0x5f, 0x49, 0x4e, 0x10, 0x0a, 0x12, 0x18, 0x0a, 0x14, 0x46, 0x49, 0x4c, 0x54, 0x45,
+	0x52, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x54, 0x52, 0x55, 0x45, 0x10,
+	0x0b, 0x12, 0x19, 0x0a, 0x15, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f, 0x4f, 0x50, 0x45, 0x52,
+	0x41, 0x54, 0x4f, 0x52, 0x5f, 0x46, 0x41, 0x4c, 0x53, 0x45, 0x10, 0x0c, 0x32, 0xf5, 0x03, 0x0a,
+	0x0a, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x12, 0x77, 0x0a, 0x07, 0x49,
+	0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x23, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61,
+	0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x74, 0x6f,
+	0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x3a, 0x01, 0x2a, 0x22, 0x16, 0x2f, 0x61,
+	0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2f, 0x69, 0x73, 0x2f, 0x62,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x7f, 0x0a, 0x09, 0x49, 0x73, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69,
+	0x6e, 0x12, 0x25, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72,
+	0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a,
+	0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x49,
+	0x73, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x3a, 0x01, 0x2a, 0x22, 0x18, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2f, 0x69, 0x73, 0x2f, 0x65, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x12, 0x5b, 0x0a, 0x08, 0x49, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x12, 0x24, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72,
+	0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e,
+	0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01,
+	0x30, 0x01, 0x12, 0x8f, 0x01, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x46, 0x69,
+	0x6c, 0x74, 0x65, 0x72, 0x12, 0x29, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74,
+	0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69,
+	0x6c, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x2a, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x27, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x21, 0x3a, 0x01, 0x2a, 0x22, 0x1c, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f,
+	0x61, 0x75, 0x74, 0x68, 0x7a, 0x2f, 0x63, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x2f, 0x66, 0x69,
+	0x6c, 0x74, 0x65, 0x72, 0x42, 0x40, 0x5a, 0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2d, 0x64, 0x65, 0x76, 0x2f, 0x74, 0x6f,
+	0x70, 0x61, 0x7a, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2f, 0x61, 0x75,
+	0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x3b, 0x61, 0x75, 0x74, 0x68,
+	0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_topaz_authorizer_v1_authorizer_proto_rawDescOnce sync.Once
+	file_topaz_authorizer_v1_authorizer_proto_rawDescData = file_topaz_authorizer_v1_authorizer_proto_rawDesc
+)
+
+func file_topaz_authorizer_v1_authorizer_proto_rawDescGZIP() []byte {
+	file_topaz_authorizer_v1_authorizer_proto_rawDescOnce.Do(func() {
+		file_topaz_authorizer_v1_authorizer_proto_rawDescData = protoimpl.X.CompressGZIP(file_topaz_authorizer_v1_authorizer_proto_rawDescData)
+	})
+	return file_topaz_authorizer_v1_authorizer_proto_rawDescData
+}
+
+var file_topaz_authorizer_v1_authorizer_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_topaz_authorizer_v1_authorizer_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_topaz_authorizer_v1_authorizer_proto_goTypes = []interface{}{
+	(SQLDialect)(0),               // 0: topaz.authorizer.v1.SQLDialect
+	(FilterOperator)(0),           // 1: topaz.authorizer.v1.FilterOperator
+	(*IsBatchRequest)(nil),        // 2: topaz.authorizer.v1.IsBatchRequest
+	(*IsBatchResponse)(nil),       // 3: topaz.authorizer.v1.IsBatchResponse
+	(*IsBatchResult)(nil),         // 4: topaz.authorizer.v1.IsBatchResult
+	(*IsExplainRequest)(nil),      // 5: topaz.authorizer.v1.IsExplainRequest
+	(*IsExplainResponse)(nil),     // 6: topaz.authorizer.v1.IsExplainResponse
+	(*IsStreamRequest)(nil),       // 7: topaz.authorizer.v1.IsStreamRequest
+	(*IsStreamResponse)(nil),      // 8: topaz.authorizer.v1.IsStreamResponse
+	(*CompileFilterRequest)(nil),  // 9: topaz.authorizer.v1.CompileFilterRequest
+	(*CompileFilterResponse)(nil), // 10: topaz.authorizer.v1.CompileFilterResponse
+	(*FilterExpression)(nil),      // 11: topaz.authorizer.v1.FilterExpression
+	(*SQLFilter)(nil),             // 12: topaz.authorizer.v1.SQLFilter
+	nil,                           // 13: topaz.authorizer.v1.CompileFilterRequest.ColumnsEntry
+	(*v2.IsRequest)(nil),          // 14: aserto.authorizer.v2.IsRequest
+	(*v2.IsResponse)(nil),         // 15: aserto.authorizer.v2.IsResponse
+	(*status.Status)(nil),         // 16: google.rpc.Status
+	(v2.TraceLevel)(0),            // 17: aserto.authorizer.v2.TraceLevel
+	(*v2.Decision)(nil),           // 18: aserto.authorizer.v2.Decision
+	(*structpb.Struct)(nil),       // 19: google.protobuf.Struct
+	(*v2.CompileRequest)(nil),     // 20: aserto.authorizer.v2.CompileRequest
+	(*structpb.Value)(nil),        // 21: google.protobuf.Value
+}
+var file_topaz_authorizer_v1_authorizer_proto_depIdxs = []int32{
+	14, // 0: topaz.authorizer.v1.IsBatchRequest.requests:type_name -> aserto.authorizer.v2.IsRequest
+	4,  // 1: topaz.authorizer.v1.IsBatchResponse.results:type_name -> topaz.authorizer.v1.IsBatchResult
+	15, // 2: topaz.authorizer.v1.IsBatchResult.response:type_name -> aserto.authorizer.v2.IsResponse
+	16, // 3: topaz.authorizer.v1.IsBatchResult.error:type_name -> google.rpc.Status
+	14, // 4: topaz.authorizer.v1.IsExplainRequest.request:type_name -> aserto.authorizer.v2.IsRequest
+	17, // 5: topaz.authorizer.v1.IsExplainRequest.trace:type_name -> aserto.authorizer.v2.TraceLevel
+	18, // 6: topaz.authorizer.v1.IsExplainResponse.decisions:type_name -> aserto.authorizer.v2.Decision
+	19, // 7: topaz.authorizer.v1.IsExplainResponse.explanation:type_name -> google.protobuf.Struct
+	14, // 8: topaz.authorizer.v1.IsStreamRequest.request:type_name -> aserto.authorizer.v2.IsRequest
+	15, // 9: topaz.authorizer.v1.IsStreamResponse.response:type_name -> aserto.authorizer.v2.IsResponse
+	16, // 10: topaz.authorizer.v1.IsStreamResponse.error:type_name -> google.rpc.Status
+	20, // 11: topaz.authorizer.v1.CompileFilterRequest.compile:type_name -> aserto.authorizer.v2.CompileRequest
+	13, // 12: topaz.authorizer.v1.CompileFilterRequest.columns:type_name -> topaz.authorizer.v1.CompileFilterRequest.ColumnsEntry
+	0,  // 13: topaz.authorizer.v1.CompileFilterRequest.dialect:type_name -> topaz.authorizer.v1.SQLDialect
+	11, // 14: topaz.authorizer.v1.CompileFilterResponse.filter:type_name -> topaz.authorizer.v1.FilterExpression
+	12, // 15: topaz.authorizer.v1.CompileFilterResponse.sql:type_name -> topaz.authorizer.v1.SQLFilter
+	1,  // 16: topaz.authorizer.v1.FilterExpression.operator:type_name -> topaz.authorizer.v1.FilterOperator
+	21, // 17: topaz.authorizer.v1.FilterExpression.value:type_name -> google.protobuf.Value
+	11, // 18: topaz.authorizer.v1.FilterExpression.operands:type_name -> topaz.authorizer.v1.FilterExpression
+	21, // 19: topaz.authorizer.v1.SQLFilter.args:type_name -> google.protobuf.Value
+	2,  // 20: topaz.authorizer.v1.Authorizer.IsBatch:input_type -> topaz.authorizer.v1.IsBatchRequest
+	5,  // 21: topaz.authorizer.v1.Authorizer.IsExplain:input_type -> topaz.authorizer.v1.IsExplainRequest
+	7,  // 22: topaz.authorizer.v1.Authorizer.IsStream:input_type -> topaz.authorizer.v1.IsStreamRequest
+	9,  // 23: topaz.authorizer.v1.Authorizer.CompileFilter:input_type -> topaz.authorizer.v1.CompileFilterRequest
+	3,  // 24: topaz.authorizer.v1.Authorizer.IsBatch:output_type -> topaz.authorizer.v1.IsBatchResponse
+	6,  // 25: topaz.authorizer.v1.Authorizer.IsExplain:output_type -> topaz.authorizer.v1.IsExplainResponse
+	8,  // 26: topaz.authorizer.v1.Authorizer.IsStream:output_type -> topaz.authorizer.v1.IsStreamResponse
+	10, // 27: topaz.authorizer.v1.Authorizer.CompileFilter:output_type -> topaz.authorizer.v1.CompileFilterResponse
+	24, // [24:28] is the sub-list for method output_type
+	20, // [20:24] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_topaz_authorizer_v1_authorizer_proto_init() }
+func file_topaz_authorizer_v1_authorizer_proto_init() {
+	if File_topaz_authorizer_v1_authorizer_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsBatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsBatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsBatchResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsExplainRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsExplainResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsStreamResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompileFilterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompileFilterResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FilterExpression); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_topaz_authorizer_v1_authorizer_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQLFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_topaz_authorizer_v1_authorizer_proto_msgTypes[2].OneofWrappers = []interface{}{
+		(*IsBatchResult_Response)(nil),
+		(*IsBatchResult_Error)(nil),
+	}
+	file_topaz_authorizer_v1_authorizer_proto_msgTypes[6].OneofWrappers = []interface{}{
+		(*IsStreamResponse_Response)(nil),
+		(*IsStreamResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_topaz_authorizer_v1_authorizer_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_topaz_authorizer_v1_authorizer_proto_goTypes,
+		DependencyIndexes: file_topaz_authorizer_v1_authorizer_proto_depIdxs,
+		EnumInfos:         file_topaz_authorizer_v1_authorizer_proto_enumTypes,
+		MessageInfos:      file_topaz_authorizer_v1_authorizer_proto_msgTypes,
+	}.Build()
+	File_topaz_authorizer_v1_authorizer_proto = out.File
+	file_topaz_authorizer_v1_authorizer_proto_rawDesc = nil
+	file_topaz_authorizer_v1_authorizer_proto_goTypes = nil
+	file_topaz_authorizer_v1_authorizer_proto_depIdxs = nil
+}
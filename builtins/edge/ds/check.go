@@ -50,12 +50,13 @@ func RegisterCheck(logger *zerolog.Logger, fnName string, dr resolvers.Directory
 				})
 			}
 
-			client, err := dr.GetDS(bctx.Context)
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
 			if err != nil {
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
 			resp, err := client.Check(bctx.Context, &args)
+			traceCall(&bctx, fnName, &args, resp.GetCheck(), err)
 			if err != nil {
 				traceError(&bctx, fnName, err)
 				return nil, err
@@ -142,12 +143,13 @@ func RegisterCheckRelation(logger *zerolog.Logger, fnName string, dr resolvers.D
 				})
 			}
 
-			client, err := dr.GetDS(bctx.Context)
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
 			if err != nil {
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
 			resp, err := client.CheckRelation(bctx.Context, &args)
+			traceCall(&bctx, fnName, &args, resp.GetCheck(), err)
 			if err != nil {
 				traceError(&bctx, fnName, err)
 				return nil, err
@@ -230,12 +232,13 @@ func RegisterCheckPermission(logger *zerolog.Logger, fnName string, dr resolvers
 				})
 			}
 
-			client, err := dr.GetDS(bctx.Context)
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
 			if err != nil {
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
 			resp, err := client.CheckPermission(bctx.Context, &args)
+			traceCall(&bctx, fnName, &args, resp.GetCheck(), err)
 			if err != nil {
 				traceError(&bctx, fnName, err)
 				return nil, err
@@ -0,0 +1,129 @@
+package ds
+
+import (
+	dsr3 "github.com/aserto-dev/go-directory/aserto/directory/reader/v3"
+	"github.com/aserto-dev/topaz/limits"
+	"github.com/aserto-dev/topaz/resolvers"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxChecksConcurrency is the maximum number of concurrent directory checks of a ds.checks call.
+const maxChecksConcurrency = 16
+
+// RegisterChecks - ds.checks
+//
+// evaluates the checks concurrently and returns their results in order,
+// the fields not set in a check default to the fields of the default check.
+// Each check counts as a builtin call against the evaluation limits.
+//
+//	ds.checks({
+//	  "default": {
+//	    "object_type": "",
+//	    "object_id": "",
+//	    "relation": "",
+//	    "subject_type": "",
+//	    "subject_id": ""
+//	  },
+//	  "checks": [
+//	    {
+//	      "object_type": "",
+//	      "object_id": "",
+//	      "relation": "",
+//	      "subject_type": "",
+//	      "subject_id": ""
+//	    }
+//	  ]
+//	})
+func RegisterChecks(logger *zerolog.Logger, fnName string, dr resolvers.DirectoryResolver) (*rego.Function, rego.Builtin1) {
+	return &rego.Function{
+			Name:    fnName,
+			Decl:    types.NewFunction(types.Args(types.A), types.NewArray(nil, types.B)),
+			Memoize: true,
+		},
+		func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+			type argsChecks struct {
+				Default *dsr3.CheckRequest   `json:"default"`
+				Checks  []*dsr3.CheckRequest `json:"checks"`
+			}
+
+			var args argsChecks
+
+			if err := ast.As(op1.Value, &args); err != nil {
+				return nil, err
+			}
+
+			if args.Default == nil && args.Checks == nil {
+				check, err := ProtoToInterface(&dsr3.CheckRequest{})
+				if err != nil {
+					return nil, err
+				}
+				return help(fnName, map[string]interface{}{
+					"default": check,
+					"checks":  []interface{}{check},
+				})
+			}
+
+			checks := make([]*dsr3.CheckRequest, len(args.Checks))
+			for i, check := range args.Checks {
+				req := &dsr3.CheckRequest{}
+				if args.Default != nil {
+					proto.Merge(req, args.Default)
+				}
+				if check != nil {
+					proto.Merge(req, check)
+				}
+				checks[i] = req
+			}
+
+			if len(checks) == 0 {
+				return ast.ArrayTerm(), nil
+			}
+
+			// the builtin call counts as the first check.
+			if err := limits.Count(bctx.Context, len(checks)-1); err != nil {
+				return nil, err
+			}
+
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
+			if err != nil {
+				return nil, errors.Wrapf(err, "get directory client")
+			}
+
+			results := make([]*dsr3.CheckResponse, len(checks))
+			errs := make([]error, len(checks))
+
+			g, ctx := errgroup.WithContext(bctx.Context)
+			g.SetLimit(maxChecksConcurrency)
+
+			for i := range checks {
+				i := i
+				g.Go(func() error {
+					results[i], errs[i] = client.Check(ctx, checks[i])
+					return errs[i]
+				})
+			}
+
+			waitErr := g.Wait()
+
+			terms := make([]*ast.Term, len(checks))
+			for i := range checks {
+				traceCall(&bctx, fnName, checks[i], results[i].GetCheck(), errs[i])
+				terms[i] = ast.BooleanTerm(results[i].GetCheck())
+			}
+
+			if waitErr != nil {
+				traceError(&bctx, fnName, waitErr)
+				return nil, waitErr
+			}
+
+			return ast.ArrayTerm(terms...), nil
+		}
+}
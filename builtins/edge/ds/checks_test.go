@@ -0,0 +1,116 @@
+package ds_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	"github.com/aserto-dev/topaz/limits"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksOrder(t *testing.T) {
+	reader := &testReader{allowed: map[string]bool{}}
+
+	// more checks than the concurrency of the builtin, every third one is allowed.
+	checks := []interface{}{}
+	expected := []interface{}{}
+	for i := 0; i < 50; i++ {
+		subject := fmt.Sprintf("user-%d", i)
+		if i%3 == 0 {
+			reader.allowed[checkKey("group", "admin", "member", "user", subject)] = true
+		}
+		checks = append(checks, map[string]interface{}{"subject_id": subject})
+		expected = append(expected, i%3 == 0)
+	}
+
+	result, err := evalBuiltin(context.Background(), ds.RegisterChecks, "ds.checks", reader, map[string]interface{}{
+		"default": map[string]interface{}{"object_type": "group", "object_id": "admin", "relation": "member", "subject_type": "user"},
+		"checks":  checks,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+	assert.Len(t, reader.checks, 50)
+}
+
+func TestChecksDefault(t *testing.T) {
+	reader := &testReader{allowed: map[string]bool{
+		checkKey("group", "admin", "member", "user", "alice"):     true,
+		checkKey("group", "admin", "owner", "user", "bob"):        true,
+		checkKey("folder", "docs", "viewer", "identity", "carol"): true,
+	}}
+
+	result, err := evalBuiltin(context.Background(), ds.RegisterChecks, "ds.checks", reader, map[string]interface{}{
+		"default": map[string]interface{}{"object_type": "group", "object_id": "admin", "relation": "member", "subject_type": "user"},
+		"checks": []interface{}{
+			map[string]interface{}{"subject_id": "alice"},
+			// the fields set in a check override the default check.
+			map[string]interface{}{"subject_id": "bob", "relation": "owner"},
+			map[string]interface{}{"object_type": "folder", "object_id": "docs", "relation": "viewer", "subject_type": "identity", "subject_id": "carol"},
+			map[string]interface{}{"subject_id": "bob"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{true, true, true, false}, result)
+
+	// without default, the checks are evaluated as they are.
+	result, err = evalBuiltin(context.Background(), ds.RegisterChecks, "ds.checks", reader, map[string]interface{}{
+		"checks": []interface{}{
+			map[string]interface{}{"object_type": "group", "object_id": "admin", "relation": "member", "subject_type": "user", "subject_id": "alice"},
+			map[string]interface{}{"subject_id": "alice"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{true, false}, result)
+
+	// no checks, no directory calls.
+	reader.checks = nil
+	result, err = evalBuiltin(context.Background(), ds.RegisterChecks, "ds.checks", reader, map[string]interface{}{
+		"default": map[string]interface{}{"object_type": "group"},
+		"checks":  []interface{}{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{}, result)
+	assert.Empty(t, reader.checks)
+}
+
+func TestChecksError(t *testing.T) {
+	reader := &testReader{checkErr: errors.New("directory unavailable")}
+
+	_, err := evalBuiltin(context.Background(), ds.RegisterChecks, "ds.checks", reader, map[string]interface{}{
+		"default": map[string]interface{}{"object_type": "group", "object_id": "admin", "relation": "member", "subject_type": "user"},
+		"checks": []interface{}{
+			map[string]interface{}{"subject_id": "alice"},
+			map[string]interface{}{"subject_id": "error"},
+			map[string]interface{}{"subject_id": "bob"},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "directory unavailable")
+}
+
+func TestChecksLimits(t *testing.T) {
+	reader := &testReader{}
+	input := map[string]interface{}{
+		"default": map[string]interface{}{"object_type": "group", "object_id": "admin", "relation": "member", "subject_type": "user"},
+		"checks":  []interface{}{map[string]interface{}{"subject_id": "alice"}, map[string]interface{}{"subject_id": "bob"}},
+	}
+
+	// the builtin call counts as the first check, the second one exceeds the limit.
+	ctx, e := limits.Set{MaxBuiltinCalls: 1}.Start(context.Background(), "is")
+	defer e.End()
+
+	_, err := evalBuiltin(ctx, ds.RegisterChecks, "ds.checks", reader, input)
+	require.Error(t, err)
+	assert.Empty(t, reader.checks)
+
+	ctx, e = limits.Set{MaxBuiltinCalls: 2}.Start(context.Background(), "is")
+	defer e.End()
+
+	_, err = evalBuiltin(ctx, ds.RegisterChecks, "ds.checks", reader, input)
+	require.NoError(t, err)
+	assert.Len(t, reader.checks, 2)
+}
@@ -97,7 +97,7 @@ func RegisterGraph(logger *zerolog.Logger, fnName string, dr resolvers.Directory
 				})
 			}
 
-			client, err := dr.GetDS(bctx.Context)
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
 			if err != nil {
 				return nil, errors.Wrapf(err, "get directory client")
 			}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/topdown"
@@ -69,13 +70,70 @@ func BufToProto(r io.Reader, msg proto.Message) error {
 }
 
 func traceError(bctx *topdown.BuiltinContext, fnName string, err error) {
-	if bctx.TraceEnabled {
-		if len(bctx.QueryTracers) > 0 {
-			bctx.QueryTracers[0].TraceEvent(topdown.Event{
-				Op:      topdown.FailOp,
-				Message: fmt.Sprintf("%s error:%s", fnName, err.Error()),
-			})
-		}
+	trace(bctx, topdown.Event{
+		Op:      topdown.FailOp,
+		Message: fmt.Sprintf("%s error:%s", fnName, err.Error()),
+	})
+}
+
+// CallNotePrefix prefixes the trace notes of directory builtin calls, followed by the JSON encoded Call.
+const CallNotePrefix = "ds.call "
+
+// Call is a directory builtin call, traced as a note when tracing is enabled.
+type Call struct {
+	Builtin string      `json:"builtin"`
+	Args    interface{} `json:"args"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// CallFromEvent returns the directory builtin call traced by the event.
+func CallFromEvent(evt *topdown.Event) (*Call, bool) {
+	if evt.Op != topdown.NoteOp || !strings.HasPrefix(evt.Message, CallNotePrefix) {
+		return nil, false
+	}
+
+	var call Call
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(evt.Message, CallNotePrefix)), &call); err != nil {
+		return nil, false
+	}
+
+	return &call, true
+}
+
+// traceCall traces the arguments and the result or error of a builtin call.
+func traceCall(bctx *topdown.BuiltinContext, fnName string, args proto.Message, result interface{}, err error) {
+	if !bctx.TraceEnabled || len(bctx.QueryTracers) == 0 {
+		return
+	}
+
+	call := Call{Builtin: fnName, Result: result}
+	if err != nil {
+		call.Result, call.Error = nil, err.Error()
+	}
+
+	call.Args, err = ProtoToInterface(args)
+	if err != nil {
+		return
+	}
+
+	buf, err := json.Marshal(call)
+	if err != nil {
+		return
+	}
+
+	trace(bctx, topdown.Event{
+		Op:       topdown.NoteOp,
+		Message:  CallNotePrefix + string(buf),
+		Location: bctx.Location,
+		QueryID:  bctx.QueryID,
+		ParentID: bctx.ParentID,
+	})
+}
+
+func trace(bctx *topdown.BuiltinContext, evt topdown.Event) {
+	if bctx.TraceEnabled && len(bctx.QueryTracers) > 0 {
+		bctx.QueryTracers[0].TraceEvent(evt)
 	}
 }
 
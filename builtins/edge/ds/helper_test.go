@@ -0,0 +1,159 @@
+package ds_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	dsc3 "github.com/aserto-dev/go-directory/aserto/directory/common/v3"
+	dsr3 "github.com/aserto-dev/go-directory/aserto/directory/reader/v3"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	"github.com/aserto-dev/topaz/limits"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/topdown"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCallFromEvent(t *testing.T) {
+	call, ok := ds.CallFromEvent(&topdown.Event{
+		Op:      topdown.NoteOp,
+		Message: ds.CallNotePrefix + `{"builtin":"ds.check","args":{"relation":"member"},"result":true}`,
+	})
+	require.True(t, ok)
+	assert.Equal(t, "ds.check", call.Builtin)
+	assert.Equal(t, map[string]interface{}{"relation": "member"}, call.Args)
+	assert.Equal(t, true, call.Result)
+	assert.Empty(t, call.Error)
+
+	call, ok = ds.CallFromEvent(&topdown.Event{
+		Op:      topdown.NoteOp,
+		Message: ds.CallNotePrefix + `{"builtin":"ds.object","args":{},"error":"not found"}`,
+	})
+	require.True(t, ok)
+	assert.Nil(t, call.Result)
+	assert.Equal(t, "not found", call.Error)
+
+	for name, evt := range map[string]*topdown.Event{
+		"trace note":    {Op: topdown.NoteOp, Message: "visible"},
+		"invalid call":  {Op: topdown.NoteOp, Message: ds.CallNotePrefix + "{"},
+		"other op":      {Op: topdown.FailOp, Message: ds.CallNotePrefix + `{"builtin":"ds.check"}`},
+		"empty message": {Op: topdown.NoteOp},
+	} {
+		_, ok := ds.CallFromEvent(evt)
+		assert.False(t, ok, name)
+	}
+}
+
+// testReader is a directory reader serving checks of its allowed relations, the checks of the subject "error" fail
+// with checkErr. It lists its objects and relations in pages and records the requests it receives.
+type testReader struct {
+	dsr3.ReaderClient
+
+	allowed   map[string]bool
+	checkErr  error
+	objects   []*dsc3.Object
+	relations []*dsc3.Relation
+
+	mu       sync.Mutex
+	checks   []*dsr3.CheckRequest
+	pages    []*dsc3.PaginationRequest
+	relation *dsr3.GetRelationsRequest
+}
+
+func checkKey(objectType, objectID, relation, subjectType, subjectID string) string {
+	return objectType + ":" + objectID + "#" + relation + "@" + subjectType + ":" + subjectID
+}
+
+func (r *testReader) Check(ctx context.Context, req *dsr3.CheckRequest, _ ...grpc.CallOption) (*dsr3.CheckResponse, error) {
+	r.mu.Lock()
+	r.checks = append(r.checks, req)
+	r.mu.Unlock()
+
+	if r.checkErr != nil && req.SubjectId == "error" {
+		return nil, r.checkErr
+	}
+
+	return &dsr3.CheckResponse{Check: r.allowed[checkKey(req.ObjectType, req.ObjectId, req.Relation, req.SubjectType, req.SubjectId)]}, nil
+}
+
+func (r *testReader) GetObjects(ctx context.Context, req *dsr3.GetObjectsRequest, _ ...grpc.CallOption) (*dsr3.GetObjectsResponse, error) {
+	items, page := testPage(r, r.objects, req.Page)
+	return &dsr3.GetObjectsResponse{Results: items, Page: page}, nil
+}
+
+func (r *testReader) GetRelations(ctx context.Context, req *dsr3.GetRelationsRequest, _ ...grpc.CallOption) (*dsr3.GetRelationsResponse, error) {
+	r.mu.Lock()
+	r.relation = proto.Clone(req).(*dsr3.GetRelationsRequest)
+	r.mu.Unlock()
+
+	items, page := testPage(r, r.relations, req.Page)
+	resp := &dsr3.GetRelationsResponse{Results: items, Page: page}
+
+	if req.WithObjects {
+		resp.Objects = map[string]*dsc3.Object{}
+		for _, rel := range items {
+			resp.Objects[rel.ObjectType+":"+rel.ObjectId] = &dsc3.Object{Type: rel.ObjectType, Id: rel.ObjectId}
+			resp.Objects[rel.SubjectType+":"+rel.SubjectId] = &dsc3.Object{Type: rel.SubjectType, Id: rel.SubjectId}
+		}
+	}
+
+	return resp, nil
+}
+
+// testPage returns the page of the items, the token of a page is the index of its first item.
+func testPage[T any](r *testReader, items []T, req *dsc3.PaginationRequest) ([]T, *dsc3.PaginationResponse) {
+	r.mu.Lock()
+	r.pages = append(r.pages, proto.Clone(req).(*dsc3.PaginationRequest))
+	r.mu.Unlock()
+
+	start := 0
+	if req.Token != "" {
+		start, _ = strconv.Atoi(req.Token)
+	}
+	end := min(start+int(req.Size), len(items))
+
+	page := &dsc3.PaginationResponse{}
+	if end < len(items) {
+		page.NextToken = strconv.Itoa(end)
+	}
+
+	return items[start:end], page
+}
+
+type testDirectory struct {
+	reader dsr3.ReaderClient
+}
+
+func (d *testDirectory) GetDS(context.Context) (dsr3.ReaderClient, error) {
+	return d.reader, nil
+}
+
+type registerFunc func(*zerolog.Logger, string, resolvers.DirectoryResolver) (*rego.Function, rego.Builtin1)
+
+// evalBuiltin evaluates the builtin registered with the reader on the input, it returns the builtin result.
+// As in the runtimes, the builtin calls count against the limits of the evaluation.
+func evalBuiltin(ctx context.Context, register registerFunc, fnName string, reader dsr3.ReaderClient, input interface{}) (interface{}, error) {
+	logger := zerolog.Nop()
+
+	rs, err := rego.New(
+		rego.Query("x = "+fnName+"(input)"),
+		rego.Input(input),
+		rego.Function1(limits.Builtin1(register(&logger, fnName, &testDirectory{reader: reader}))),
+		rego.StrictBuiltinErrors(true),
+	).Eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(rs) != 1 {
+		return nil, errors.Errorf("%d results", len(rs))
+	}
+
+	return rs[0].Bindings["x"], nil
+}
@@ -55,7 +55,7 @@ func RegisterIdentity(logger *zerolog.Logger, fnName string, dr resolvers.Direct
 				return help(fnName, argsV3{})
 			}
 
-			client, err := dr.GetDS(bctx.Context)
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
 			if err != nil {
 				return nil, errors.Wrapf(err, "get directory client")
 			}
@@ -0,0 +1,106 @@
+package ds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/aserto-dev/topaz/limits"
+
+	"github.com/open-policy-agent/opa/ast"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// maxListResults is the maximum number of results returned by a listing builtin call.
+	maxListResults = 1000
+	// listPageSize is the page size of the directory calls of the listing builtins.
+	listPageSize = 100
+)
+
+// listLimit returns the maximum number of results of a listing builtin call, max_results capped by maxListResults.
+func listLimit(maxResults int) (int, error) {
+	switch {
+	case maxResults < 0:
+		return 0, errors.Errorf("max_results must be positive, got %d", maxResults)
+	case maxResults == 0 || maxResults > maxListResults:
+		return maxListResults, nil
+	default:
+		return maxResults, nil
+	}
+}
+
+// listPages calls list with the page size and token of each page until the last page or until more than limit
+// results are listed. Each page after the first counts as a builtin call against the evaluation limits.
+// It returns at most limit results, truncated is true when results were dropped.
+func listPages[T any](ctx context.Context, limit int, list func(size int32, token string) ([]T, string, error)) (results []T, truncated bool, err error) {
+	// one more result than the limit tells whether results are dropped.
+	size := int32(min(listPageSize, limit+1))
+	token := ""
+
+	for page := 0; ; page++ {
+		if page > 0 {
+			if err := limits.Count(ctx, 1); err != nil {
+				return nil, false, err
+			}
+		}
+
+		items, next, err := list(size, token)
+		if err != nil {
+			return nil, false, err
+		}
+
+		results = append(results, items...)
+
+		if len(results) > limit {
+			return results[:limit], true, nil
+		}
+
+		if next == "" {
+			return results, false, nil
+		}
+		token = next
+	}
+}
+
+// listHelp returns the help message of a listing builtin, the request without page and with max_results.
+func listHelp(fnName string, msg proto.Message) (*ast.Term, error) {
+	v, err := ProtoToInterface(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	args := v.(map[string]interface{})
+	delete(args, "page")
+	args["max_results"] = maxListResults
+
+	return help(fnName, args)
+}
+
+// listTerm returns the result of a listing builtin call, the response without page and with its truncated flag.
+func listTerm(resp proto.Message, truncated bool) (*ast.Term, error) {
+	buf := new(bytes.Buffer)
+	if err := ProtoToBuf(buf, resp); err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		return nil, err
+	}
+
+	delete(result, "page")
+	if _, ok := result["results"]; !ok {
+		result["results"] = []interface{}{}
+	}
+	result["truncated"] = truncated
+
+	v, err := ast.InterfaceToValue(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewTerm(v), nil
+}
@@ -0,0 +1,203 @@
+package ds_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dsc3 "github.com/aserto-dev/go-directory/aserto/directory/common/v3"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	"github.com/aserto-dev/topaz/limits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testObjects(n int) []*dsc3.Object {
+	objects := make([]*dsc3.Object, n)
+	for i := range objects {
+		objects[i] = &dsc3.Object{Type: "user", Id: fmt.Sprintf("user-%03d", i)}
+	}
+	return objects
+}
+
+// testMembers returns the member relations of the admin group with n users.
+func testMembers(n int) []*dsc3.Relation {
+	relations := make([]*dsc3.Relation, n)
+	for i := range relations {
+		relations[i] = &dsc3.Relation{
+			ObjectType:  "group",
+			ObjectId:    "admin",
+			Relation:    "member",
+			SubjectType: "user",
+			SubjectId:   fmt.Sprintf("user-%03d", i),
+		}
+	}
+	return relations
+}
+
+func resultIDs(t *testing.T, result interface{}, field string) []string {
+	m, ok := result.(map[string]interface{})
+	require.True(t, ok, "result is a %T", result)
+
+	results, ok := m["results"].([]interface{})
+	require.True(t, ok, "results are a %T", m["results"])
+
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i], _ = r.(map[string]interface{})[field].(string)
+	}
+	return ids
+}
+
+func testIDs(from, to int) []string {
+	ids := []string{}
+	for i := from; i < to; i++ {
+		ids = append(ids, fmt.Sprintf("user-%03d", i))
+	}
+	return ids
+}
+
+func pageSizes(reader *testReader) []int32 {
+	sizes := make([]int32, len(reader.pages))
+	for i, page := range reader.pages {
+		sizes[i] = page.Size
+	}
+	return sizes
+}
+
+func TestObjects(t *testing.T) {
+	tests := []struct {
+		name       string
+		objects    int
+		maxResults int
+		ids        []string
+		truncated  bool
+		pages      []int32
+	}{
+		{name: "single page", objects: 30, ids: testIDs(0, 30), pages: []int32{100}},
+		{name: "all pages", objects: 250, ids: testIDs(0, 250), pages: []int32{100, 100, 100}},
+		{name: "max results", objects: 250, maxResults: 250, ids: testIDs(0, 250), pages: []int32{100, 100, 100}},
+		{name: "truncated", objects: 250, maxResults: 120, ids: testIDs(0, 120), truncated: true, pages: []int32{100, 100}},
+		// one more object than max_results tells whether objects are dropped.
+		{name: "truncated page", objects: 250, maxResults: 10, ids: testIDs(0, 10), truncated: true, pages: []int32{11}},
+		{name: "exact page", objects: 10, maxResults: 10, ids: testIDs(0, 10), pages: []int32{11}},
+		{name: "no objects", ids: []string{}, pages: []int32{100}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := &testReader{objects: testObjects(tc.objects)}
+
+			result, err := evalBuiltin(context.Background(), ds.RegisterObjects, "ds.objects", reader, map[string]interface{}{
+				"object_type": "user",
+				"max_results": tc.maxResults,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.ids, resultIDs(t, result, "id"))
+			assert.Equal(t, tc.truncated, result.(map[string]interface{})["truncated"])
+			assert.NotContains(t, result, "page")
+			assert.Equal(t, tc.pages, pageSizes(reader))
+		})
+	}
+}
+
+func TestObjectsMaxResults(t *testing.T) {
+	reader := &testReader{objects: testObjects(1500)}
+
+	// max_results is capped at 1000 objects.
+	result, err := evalBuiltin(context.Background(), ds.RegisterObjects, "ds.objects", reader, map[string]interface{}{
+		"object_type": "user",
+		"max_results": 5000,
+	})
+	require.NoError(t, err)
+	assert.Len(t, resultIDs(t, result, "id"), 1000)
+	assert.Equal(t, true, result.(map[string]interface{})["truncated"])
+
+	_, err = evalBuiltin(context.Background(), ds.RegisterObjects, "ds.objects", reader, map[string]interface{}{
+		"object_type": "user",
+		"max_results": -1,
+	})
+	assert.Error(t, err)
+}
+
+func TestObjectsLimits(t *testing.T) {
+	reader := &testReader{objects: testObjects(250)}
+	input := map[string]interface{}{"object_type": "user"}
+
+	// the builtin call counts as the first page, each following page counts as a call.
+	ctx, e := limits.Set{MaxBuiltinCalls: 2}.Start(context.Background(), "is")
+	defer e.End()
+
+	_, err := evalBuiltin(ctx, ds.RegisterObjects, "ds.objects", reader, input)
+	require.Error(t, err)
+	assert.Len(t, reader.pages, 2)
+
+	reader.pages = nil
+	ctx, e = limits.Set{MaxBuiltinCalls: 3}.Start(context.Background(), "is")
+	defer e.End()
+
+	_, err = evalBuiltin(ctx, ds.RegisterObjects, "ds.objects", reader, input)
+	require.NoError(t, err)
+	assert.Len(t, reader.pages, 3)
+}
+
+func TestRelationsAll(t *testing.T) {
+	reader := &testReader{relations: testMembers(250)}
+
+	result, err := evalBuiltin(context.Background(), ds.RegisterRelationsAll, "ds.relations_all", reader, map[string]interface{}{
+		"object_type":  "group",
+		"object_id":    "admin",
+		"relation":     "member",
+		"with_objects": true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, testIDs(0, 250), resultIDs(t, result, "subject_id"))
+	assert.Equal(t, false, result.(map[string]interface{})["truncated"])
+	assert.Equal(t, []int32{100, 100, 100}, pageSizes(reader))
+
+	// the objects of all pages are returned.
+	objects := result.(map[string]interface{})["objects"].(map[string]interface{})
+	assert.Len(t, objects, 251)
+	assert.Contains(t, objects, "group:admin")
+	assert.Contains(t, objects, "user:user-249")
+
+	// the request is passed on with the page of the call.
+	assert.Equal(t, "group", reader.relation.ObjectType)
+	assert.Equal(t, "admin", reader.relation.ObjectId)
+	assert.Equal(t, "member", reader.relation.Relation)
+	assert.Equal(t, "200", reader.relation.Page.Token)
+}
+
+func TestRelationsAllTruncated(t *testing.T) {
+	reader := &testReader{relations: testMembers(250)}
+
+	result, err := evalBuiltin(context.Background(), ds.RegisterRelationsAll, "ds.relations_all", reader, map[string]interface{}{
+		"object_type":  "group",
+		"object_id":    "admin",
+		"with_objects": true,
+		"max_results":  120,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, testIDs(0, 120), resultIDs(t, result, "subject_id"))
+	assert.Equal(t, true, result.(map[string]interface{})["truncated"])
+	assert.Equal(t, []int32{100, 100}, pageSizes(reader))
+
+	// the objects of the dropped relations are dropped too, although their page was read.
+	objects := result.(map[string]interface{})["objects"].(map[string]interface{})
+	assert.Len(t, objects, 121)
+	assert.Contains(t, objects, "group:admin")
+	assert.Contains(t, objects, "user:user-119")
+	assert.NotContains(t, objects, "user:user-120")
+
+	// without objects, none are returned.
+	result, err = evalBuiltin(context.Background(), ds.RegisterRelationsAll, "ds.relations_all", reader, map[string]interface{}{
+		"object_type": "group",
+		"max_results": 120,
+	})
+	require.NoError(t, err)
+	assert.Len(t, resultIDs(t, result, "subject_id"), 120)
+	assert.NotContains(t, result, "objects")
+}
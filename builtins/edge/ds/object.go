@@ -84,7 +84,7 @@ func RegisterObject(logger *zerolog.Logger, fnName string, dr resolvers.Director
 				})
 			}
 
-			client, err := dr.GetDS(bctx.Context)
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
 			if err != nil {
 				return nil, errors.Wrapf(err, "get directory client")
 			}
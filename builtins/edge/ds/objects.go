@@ -0,0 +1,82 @@
+package ds
+
+import (
+	dsc3 "github.com/aserto-dev/go-directory/aserto/directory/common/v3"
+	dsr3 "github.com/aserto-dev/go-directory/aserto/directory/reader/v3"
+	"github.com/aserto-dev/topaz/resolvers"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// RegisterObjects - ds.objects
+//
+// lists the objects of the object type, all pages of the directory are read.
+// At most max_results objects are returned (default and maximum: 1000), truncated is true
+// when more objects exist. Each page after the first counts as a builtin call against the evaluation limits.
+//
+// v3 (latest) request format:
+//
+//	ds.objects({
+//		"object_type": "",
+//		"max_results": 1000
+//	})
+//
+// response format:
+//
+//	{
+//		"results": [],
+//		"truncated": false
+//	}
+func RegisterObjects(logger *zerolog.Logger, fnName string, dr resolvers.DirectoryResolver) (*rego.Function, rego.Builtin1) {
+	return &rego.Function{
+			Name:    fnName,
+			Decl:    types.NewFunction(types.Args(types.A), types.A),
+			Memoize: true,
+		},
+		func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+			var args struct {
+				ObjectType string `json:"object_type"`
+				MaxResults int    `json:"max_results"`
+			}
+
+			if err := ast.As(op1.Value, &args); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse ds.objects input message")
+			}
+
+			if args.ObjectType == "" && args.MaxResults == 0 {
+				return listHelp(fnName, &dsr3.GetObjectsRequest{ObjectType: ""})
+			}
+
+			limit, err := listLimit(args.MaxResults)
+			if err != nil {
+				return nil, err
+			}
+
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
+			if err != nil {
+				return nil, errors.Wrapf(err, "get directory client")
+			}
+
+			results, truncated, err := listPages(bctx.Context, limit, func(size int32, token string) ([]*dsc3.Object, string, error) {
+				r, err := client.GetObjects(bctx.Context, &dsr3.GetObjectsRequest{
+					ObjectType: args.ObjectType,
+					Page:       &dsc3.PaginationRequest{Size: size, Token: token},
+				})
+				if err != nil {
+					return nil, "", err
+				}
+				return r.Results, r.GetPage().GetNextToken(), nil
+			})
+			if err != nil {
+				traceError(&bctx, fnName, err)
+				return nil, err
+			}
+
+			return listTerm(&dsr3.GetObjectsResponse{Results: results}, truncated)
+		}
+}
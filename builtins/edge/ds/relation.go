@@ -102,7 +102,7 @@ func RegisterRelation(logger *zerolog.Logger, fnName string, dr resolvers.Direct
 				})
 			}
 
-			client, err := dr.GetDS(bctx.Context)
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
 			if err != nil {
 				return nil, errors.Wrapf(err, "get directory client")
 			}
@@ -202,7 +202,7 @@ func RegisterRelations(logger *zerolog.Logger, fnName string, dr resolvers.Direc
 				return helpMsg(fnName, &dsr3.GetRelationsRequest{})
 			}
 
-			client, err := dr.GetDS(bctx.Context)
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
 			if err != nil {
 				return nil, errors.Wrapf(err, "get directory client")
 			}
@@ -249,3 +249,108 @@ func RegisterRelations(logger *zerolog.Logger, fnName string, dr resolvers.Direc
 			return ast.NewTerm(v), nil
 		}
 }
+
+// RegisterRelationsAll - ds.relations_all
+//
+// lists the relations matching the request, all pages of the directory are read.
+// At most max_results relations are returned (default and maximum: 1000), truncated is true
+// when more relations exist. Each page after the first counts as a builtin call against the evaluation limits.
+//
+// v3 (latest) request format:
+//
+//	ds.relations_all({
+//		"object_type": "",
+//		"object_id": "",
+//		"relation": "",
+//		"subject_type": "",
+//		"subject_id": "",
+//		"subject_relation": "",
+//		"with_objects": false,
+//		"with_empty_subject_relation": false,
+//		"max_results": 1000
+//	})
+//
+// response format:
+//
+//	{
+//		"results": [],
+//		"objects": {},
+//		"truncated": false
+//	}
+func RegisterRelationsAll(logger *zerolog.Logger, fnName string, dr resolvers.DirectoryResolver) (*rego.Function, rego.Builtin1) {
+	return &rego.Function{
+			Name:    fnName,
+			Decl:    types.NewFunction(types.Args(types.A), types.A),
+			Memoize: true,
+		},
+		func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+			var args struct {
+				*dsr3.GetRelationsRequest
+				MaxResults int `json:"max_results"`
+			}
+
+			if err := ast.As(op1.Value, &args); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse ds.relations_all input message")
+			}
+
+			req := args.GetRelationsRequest
+			if req == nil {
+				req = &dsr3.GetRelationsRequest{}
+			}
+			req.Page = nil
+
+			if proto.Equal(req, &dsr3.GetRelationsRequest{}) && args.MaxResults == 0 {
+				return listHelp(fnName, &dsr3.GetRelationsRequest{})
+			}
+
+			limit, err := listLimit(args.MaxResults)
+			if err != nil {
+				return nil, err
+			}
+
+			client, err := dr.GetDS(resolvers.WithBuiltin(bctx.Context, fnName))
+			if err != nil {
+				return nil, errors.Wrapf(err, "get directory client")
+			}
+
+			resp := &dsr3.GetRelationsResponse{}
+			if req.WithObjects {
+				resp.Objects = map[string]*dsc3.Object{}
+			}
+
+			results, truncated, err := listPages(bctx.Context, limit, func(size int32, token string) ([]*dsc3.Relation, string, error) {
+				req.Page = &dsc3.PaginationRequest{Size: size, Token: token}
+				r, err := client.GetRelations(bctx.Context, req)
+				if err != nil {
+					return nil, "", err
+				}
+				if resp.Objects != nil {
+					for k, v := range r.Objects {
+						resp.Objects[k] = v
+					}
+				}
+				return r.Results, r.GetPage().GetNextToken(), nil
+			})
+			if err != nil {
+				traceError(&bctx, fnName, err)
+				return nil, err
+			}
+
+			resp.Results = results
+
+			// drop the objects of the relations dropped from the results.
+			if truncated && resp.Objects != nil {
+				objects := map[string]*dsc3.Object{}
+				for _, r := range results {
+					for _, key := range []string{r.ObjectType + ":" + r.ObjectId, r.SubjectType + ":" + r.SubjectId} {
+						if obj, ok := resp.Objects[key]; ok {
+							objects[key] = obj
+						}
+					}
+				}
+				resp.Objects = objects
+			}
+
+			return listTerm(resp, truncated)
+		}
+}
@@ -50,9 +50,12 @@ var cmdRun = &cobra.Command{
 			}
 
 		})
+		// the decision logger is shut down once the servers stopped, no decisions are logged afterwards.
+		shutdownDecisionLogger := func() {}
 		defer func() {
 			if cleanup != nil {
 				topazApp.Manager.StopServers(topazApp.Context)
+				shutdownDecisionLogger()
 				cleanup()
 			}
 		}()
@@ -77,6 +80,8 @@ var cmdRun = &cobra.Command{
 				return err
 			}
 
+			shutdownDecisionLogger = decisionlog.Shutdown
+
 			controllerFactory := controller.NewFactory(
 				topazApp.Logger,
 				topazApp.Configuration.ControllerConfig,
@@ -0,0 +1,269 @@
+// Package datafilter translates the residual queries of a partial evaluation into
+// filter expressions over resource columns, and filter expressions into SQL WHERE clauses.
+package datafilter
+
+import (
+	"encoding/json"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/pkg/errors"
+)
+
+// Op is the operator of a filter expression.
+type Op string
+
+const (
+	OpAnd   Op = "and"
+	OpOr    Op = "or"
+	OpNot   Op = "not"
+	OpEq    Op = "eq"
+	OpNe    Op = "ne"
+	OpLt    Op = "lt"
+	OpLte   Op = "lte"
+	OpGt    Op = "gt"
+	OpGte   Op = "gte"
+	OpIn    Op = "in"
+	OpTrue  Op = "true"
+	OpFalse Op = "false"
+)
+
+// Expr is a filter expression.
+//
+// Comparisons compare Column with the scalar Value, in expressions match Column against
+// the list of scalars in Value, and, or and not expressions combine their Operands.
+type Expr struct {
+	Op       Op          `json:"op"`
+	Column   string      `json:"column,omitempty"`
+	Value    interface{} `json:"value,omitempty"`
+	Operands []*Expr     `json:"operands,omitempty"`
+}
+
+// ResourceRef is the reference of the resource fields in the residual queries.
+var ResourceRef = ast.MustParseRef("input.resource")
+
+var comparisons = map[string]Op{
+	ast.Equality.Name:      OpEq,
+	ast.Equal.Name:         OpEq,
+	ast.NotEqual.Name:      OpNe,
+	ast.LessThan.Name:      OpLt,
+	ast.LessThanEq.Name:    OpLte,
+	ast.GreaterThan.Name:   OpGt,
+	ast.GreaterThanEq.Name: OpGte,
+}
+
+// flipped returns the operator of the comparison with swapped operands.
+var flipped = map[Op]Op{
+	OpEq:  OpEq,
+	OpNe:  OpNe,
+	OpLt:  OpGt,
+	OpLte: OpGte,
+	OpGt:  OpLt,
+	OpGte: OpLte,
+}
+
+// Translate returns the filter expression of the residual queries of a partial evaluation with
+// input.resource as unknown, the expression is the disjunction of the queries.
+//
+// Columns maps resource references (e.g. input.resource.owner_id) to column names, unmapped
+// top level resource fields use the field name as column name.
+func Translate(queries []ast.Body, columns map[string]string) (*Expr, error) {
+	t := translator{columns: columns}
+
+	// no residual queries, the query is undefined for every resource.
+	if len(queries) == 0 {
+		return &Expr{Op: OpFalse}, nil
+	}
+
+	or := make([]*Expr, 0, len(queries))
+	for i, query := range queries {
+		// an empty residual query is true for every resource.
+		if len(query) == 0 {
+			return &Expr{Op: OpTrue}, nil
+		}
+
+		and := make([]*Expr, 0, len(query))
+		for _, expr := range query {
+			e, err := t.expr(expr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "query %d: %s", i, expr)
+			}
+			and = append(and, e)
+		}
+
+		or = append(or, combine(OpAnd, and))
+	}
+
+	return combine(OpOr, or), nil
+}
+
+// combine returns the conjunction or disjunction of the operands, a single operand is returned as is.
+func combine(op Op, operands []*Expr) *Expr {
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	return &Expr{Op: op, Operands: operands}
+}
+
+type translator struct {
+	columns map[string]string
+}
+
+func (t *translator) expr(expr *ast.Expr) (*Expr, error) {
+	if len(expr.With) > 0 {
+		return nil, errors.New("with modifiers are not supported")
+	}
+
+	var (
+		e   *Expr
+		err error
+	)
+
+	switch {
+	case expr.IsCall():
+		e, err = t.call(expr)
+	default:
+		e, err = t.term(expr.Terms.(*ast.Term))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Negated {
+		return &Expr{Op: OpNot, Operands: []*Expr{e}}, nil
+	}
+
+	return e, nil
+}
+
+// call translates comparisons of a resource field with a scalar and membership of a resource field in a collection of scalars.
+func (t *translator) call(expr *ast.Expr) (*Expr, error) {
+	name := expr.Operator().String()
+	operands := expr.Operands()
+
+	if op, ok := comparisons[name]; ok && len(operands) == 2 {
+		left, right := operands[0], operands[1]
+		if _, ok := left.Value.(ast.Ref); !ok {
+			left, right, op = right, left, flipped[op]
+		}
+
+		column, err := t.column(left)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := scalar(right)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Expr{Op: op, Column: column, Value: value}, nil
+	}
+
+	if name == ast.Member.Name && len(operands) == 2 {
+		column, err := t.column(operands[0])
+		if err != nil {
+			return nil, err
+		}
+
+		values, err := collection(operands[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return &Expr{Op: OpIn, Column: column, Value: values}, nil
+	}
+
+	return nil, errors.Errorf("unsupported call %s", name)
+}
+
+// term translates a boolean constant, or a resource field that must be true.
+func (t *translator) term(term *ast.Term) (*Expr, error) {
+	switch v := term.Value.(type) {
+	case ast.Boolean:
+		if v {
+			return &Expr{Op: OpTrue}, nil
+		}
+		return &Expr{Op: OpFalse}, nil
+	case ast.Ref:
+		column, err := t.column(term)
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Op: OpEq, Column: column, Value: true}, nil
+	default:
+		return nil, errors.Errorf("unsupported expression %s", term)
+	}
+}
+
+// column returns the column name of a resource field reference.
+func (t *translator) column(term *ast.Term) (string, error) {
+	ref, ok := term.Value.(ast.Ref)
+	if !ok || !ref.HasPrefix(ResourceRef) || len(ref) == len(ResourceRef) {
+		return "", errors.Errorf("unsupported operand %s, operands must be resource fields or scalars", term)
+	}
+
+	if !ref.IsGround() {
+		return "", errors.Errorf("unsupported reference %s, references must not contain variables", ref)
+	}
+
+	if column, ok := t.columns[ref.String()]; ok {
+		return column, nil
+	}
+
+	if field, ok := ref[len(ResourceRef)].Value.(ast.String); ok && len(ref) == len(ResourceRef)+1 {
+		return string(field), nil
+	}
+
+	return "", errors.Errorf("no column mapped for %s", ref)
+}
+
+func scalar(term *ast.Term) (interface{}, error) {
+	switch v := term.Value.(type) {
+	case ast.String:
+		return string(v), nil
+	case ast.Boolean:
+		return bool(v), nil
+	case ast.Null:
+		return nil, nil
+	case ast.Number:
+		return number(json.Number(v))
+	default:
+		return nil, errors.Errorf("unsupported operand %s, operands must be resource fields or scalars", term)
+	}
+}
+
+func collection(term *ast.Term) ([]interface{}, error) {
+	var terms []*ast.Term
+	switch v := term.Value.(type) {
+	case *ast.Array:
+		v.Foreach(func(t *ast.Term) { terms = append(terms, t) })
+	case ast.Set:
+		v.Foreach(func(t *ast.Term) { terms = append(terms, t) })
+	default:
+		return nil, errors.Errorf("unsupported collection %s, collections must be arrays or sets of scalars", term)
+	}
+
+	values := make([]interface{}, 0, len(terms))
+	for _, t := range terms {
+		value, err := scalar(t)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// number returns integral numbers as int64 and other numbers as float64.
+func number(n json.Number) (interface{}, error) {
+	if i, err := n.Int64(); err == nil {
+		return i, nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return nil, errors.Errorf("invalid number %s", n)
+	}
+	return f, nil
+}
@@ -0,0 +1,243 @@
+package datafilter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Dialect is the SQL dialect of a WHERE clause.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+var sqlOperators = map[Op]string{
+	OpEq:  "=",
+	OpNe:  "<>",
+	OpLt:  "<",
+	OpLte: "<=",
+	OpGt:  ">",
+	OpGte: ">=",
+}
+
+// SQL returns the WHERE clause of the filter expression, without the WHERE keyword,
+// and the values of its positional parameters.
+//
+// NULL columns are undefined resource fields: comparisons of a NULL column are false and their negations true.
+func (e *Expr) SQL(dialect Dialect) (string, []interface{}, error) {
+	if dialect != Postgres && dialect != SQLite {
+		return "", nil, errors.Errorf("unknown SQL dialect %q", dialect)
+	}
+
+	w := &sqlWriter{dialect: dialect}
+	if err := w.write(e); err != nil {
+		return "", nil, err
+	}
+
+	return w.sb.String(), w.args, nil
+}
+
+type sqlWriter struct {
+	dialect Dialect
+	sb      strings.Builder
+	args    []interface{}
+	// negated is the number of not expressions around the and/or expression being written.
+	negated int
+}
+
+func (w *sqlWriter) write(e *Expr) error {
+	switch e.Op {
+	case OpTrue:
+		w.sb.WriteString(w.boolean(true))
+	case OpFalse:
+		w.sb.WriteString(w.boolean(false))
+	case OpAnd, OpOr:
+		return w.join(e)
+	case OpNot:
+		return w.not(e)
+	default:
+		return w.leaf(e)
+	}
+
+	return nil
+}
+
+// not writes the negation of the operand. A comparison of an undefined resource field is undefined in rego,
+// and its negation is true, while a comparison of a NULL column is NULL in SQL, and so is its negation.
+func (w *sqlWriter) not(e *Expr) error {
+	if len(e.Operands) != 1 {
+		return errors.Errorf("not expression with %d operands", len(e.Operands))
+	}
+
+	operand := e.Operands[0]
+
+	if column, ok := nullable(operand); ok {
+		w.sb.WriteString("(" + quoteIdentifier(column) + " IS NULL OR NOT (")
+		if err := w.write(operand); err != nil {
+			return err
+		}
+		w.sb.WriteString("))")
+		return nil
+	}
+
+	w.negated++
+	defer func() { w.negated-- }()
+
+	w.sb.WriteString("NOT (")
+	if err := w.write(operand); err != nil {
+		return err
+	}
+	w.sb.WriteString(")")
+
+	return nil
+}
+
+// leaf writes a comparison or in expression. Within a negated and/or expression, the expression is false
+// rather than NULL when the column is NULL, so that its negation is true.
+func (w *sqlWriter) leaf(e *Expr) error {
+	column, ok := nullable(e)
+	if !ok || w.negated == 0 {
+		return w.compareOrIn(e)
+	}
+
+	w.sb.WriteString("(" + quoteIdentifier(column) + " IS NOT NULL AND ")
+	if err := w.compareOrIn(e); err != nil {
+		return err
+	}
+	w.sb.WriteString(")")
+
+	return nil
+}
+
+func (w *sqlWriter) compareOrIn(e *Expr) error {
+	if e.Op == OpIn {
+		return w.in(e)
+	}
+	return w.compare(e)
+}
+
+// nullable returns the column of a comparison or in expression that is NULL when the column is NULL.
+func nullable(e *Expr) (string, bool) {
+	switch e.Op {
+	case OpAnd, OpOr, OpNot, OpTrue, OpFalse:
+		return "", false
+	case OpIn:
+		values, _ := e.Value.([]interface{})
+		return e.Column, len(values) > 0
+	default:
+		return e.Column, e.Value != nil
+	}
+}
+
+func (w *sqlWriter) join(e *Expr) error {
+	if len(e.Operands) == 0 {
+		// an empty conjunction is true, an empty disjunction is false.
+		w.sb.WriteString(w.boolean(e.Op == OpAnd))
+		return nil
+	}
+
+	sep := " AND "
+	if e.Op == OpOr {
+		sep = " OR "
+	}
+
+	w.sb.WriteString("(")
+	for i, operand := range e.Operands {
+		if i > 0 {
+			w.sb.WriteString(sep)
+		}
+		if err := w.write(operand); err != nil {
+			return err
+		}
+	}
+	w.sb.WriteString(")")
+
+	return nil
+}
+
+func (w *sqlWriter) compare(e *Expr) error {
+	op, ok := sqlOperators[e.Op]
+	if !ok {
+		return errors.Errorf("unknown operator %q", e.Op)
+	}
+
+	w.sb.WriteString(quoteIdentifier(e.Column))
+
+	if e.Value == nil {
+		switch e.Op {
+		case OpEq:
+			w.sb.WriteString(" IS NULL")
+			return nil
+		case OpNe:
+			w.sb.WriteString(" IS NOT NULL")
+			return nil
+		default:
+			return errors.Errorf("%s comparison of %s with null", e.Op, e.Column)
+		}
+	}
+
+	w.sb.WriteString(" " + op + " ")
+	w.sb.WriteString(w.param(e.Value))
+
+	return nil
+}
+
+func (w *sqlWriter) in(e *Expr) error {
+	values, ok := e.Value.([]interface{})
+	if !ok {
+		return errors.Errorf("in expression of %s without list of values", e.Column)
+	}
+
+	if len(values) == 0 {
+		w.sb.WriteString(w.boolean(false))
+		return nil
+	}
+
+	w.sb.WriteString(quoteIdentifier(e.Column))
+	w.sb.WriteString(" IN (")
+	for i, value := range values {
+		if i > 0 {
+			w.sb.WriteString(", ")
+		}
+		w.sb.WriteString(w.param(value))
+	}
+	w.sb.WriteString(")")
+
+	return nil
+}
+
+// param adds a positional parameter and returns its placeholder.
+func (w *sqlWriter) param(value interface{}) string {
+	w.args = append(w.args, value)
+
+	if w.dialect == Postgres {
+		return "$" + strconv.Itoa(len(w.args))
+	}
+	return "?"
+}
+
+func (w *sqlWriter) boolean(b bool) string {
+	switch {
+	case w.dialect == Postgres && b:
+		return "TRUE"
+	case w.dialect == Postgres:
+		return "FALSE"
+	case b:
+		return "1 = 1"
+	default:
+		return "1 = 0"
+	}
+}
+
+// quoteIdentifier quotes each part of a, possibly table qualified, column name.
+func quoteIdentifier(column string) string {
+	parts := strings.Split(column, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
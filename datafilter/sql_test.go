@@ -0,0 +1,182 @@
+package datafilter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aserto-dev/topaz/datafilter"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// residualQueries partially evaluates data.todo.allowed with input.resource unknown.
+func residualQueries(t *testing.T, module string) []ast.Body {
+	pq, err := rego.New(
+		rego.Query("data.todo.allowed == true"),
+		rego.Module("todo.rego", module),
+		rego.Input(map[string]interface{}{"user": map[string]interface{}{"id": "u1", "level": 3}}),
+		rego.Unknowns([]string{"input.resource"}),
+	).Partial(context.Background())
+	require.NoError(t, err)
+
+	require.Empty(t, pq.Support)
+	return pq.Queries
+}
+
+func TestSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		module   string
+		columns  map[string]string
+		postgres string
+		sqlite   string
+		args     []interface{}
+	}{
+		{
+			name:     "equality",
+			module:   "package todo\n\nallowed {\n\tinput.resource.owner_id == input.user.id\n}\n",
+			postgres: `"owner_id" = $1`,
+			sqlite:   `"owner_id" = ?`,
+			args:     []interface{}{"u1"},
+		},
+		{
+			name:     "conjunction and comparison",
+			module:   "package todo\n\nallowed {\n\tinput.resource.status == \"open\"\n\tinput.resource.level <= input.user.level\n}\n",
+			postgres: `("status" = $1 AND "level" <= $2)`,
+			sqlite:   `("status" = ? AND "level" <= ?)`,
+			args:     []interface{}{"open", int64(3)},
+		},
+		{
+			name:     "disjunction",
+			module:   "package todo\n\nallowed {\n\tinput.resource.owner_id == input.user.id\n}\n\nallowed {\n\tinput.resource.public\n}\n",
+			postgres: `("owner_id" = $1 OR "public" = $2)`,
+			sqlite:   `("owner_id" = ? OR "public" = ?)`,
+			args:     []interface{}{"u1", true},
+		},
+		{
+			name:     "membership",
+			module:   "package todo\n\nimport future.keywords.in\n\nallowed {\n\tinput.resource.status in [\"open\", \"review\"]\n}\n",
+			postgres: `"status" IN ($1, $2)`,
+			sqlite:   `"status" IN (?, ?)`,
+			args:     []interface{}{"open", "review"},
+		},
+		{
+			name:     "flipped operands",
+			module:   "package todo\n\nallowed {\n\t2 < input.resource.level\n}\n",
+			postgres: `"level" > $1`,
+			sqlite:   `"level" > ?`,
+			args:     []interface{}{int64(2)},
+		},
+		{
+			name:     "mapped column",
+			module:   "package todo\n\nallowed {\n\tinput.resource.owner.id == input.user.id\n}\n",
+			columns:  map[string]string{"input.resource.owner.id": "todos.owner_id"},
+			postgres: `"todos"."owner_id" = $1`,
+			sqlite:   `"todos"."owner_id" = ?`,
+			args:     []interface{}{"u1"},
+		},
+		{
+			name:     "negation is true for null columns",
+			module:   "package todo\n\nallowed {\n\tnot input.resource.status == \"closed\"\n}\n",
+			postgres: `("status" IS NULL OR NOT ("status" = $1))`,
+			sqlite:   `("status" IS NULL OR NOT ("status" = ?))`,
+			args:     []interface{}{"closed"},
+		},
+		{
+			name:     "negated membership",
+			module:   "package todo\n\nimport future.keywords.in\n\nallowed {\n\tnot input.resource.status in {\"closed\"}\n}\n",
+			postgres: `("status" IS NULL OR NOT ("status" IN ($1)))`,
+			sqlite:   `("status" IS NULL OR NOT ("status" IN (?)))`,
+			args:     []interface{}{"closed"},
+		},
+		{
+			name:     "null comparison",
+			module:   "package todo\n\nallowed {\n\tinput.resource.deleted_at == null\n}\n",
+			postgres: `"deleted_at" IS NULL`,
+			sqlite:   `"deleted_at" IS NULL`,
+		},
+		{
+			name:     "negated null comparison",
+			module:   "package todo\n\nallowed {\n\tnot input.resource.deleted_at == null\n}\n",
+			postgres: `NOT ("deleted_at" IS NULL)`,
+			sqlite:   `NOT ("deleted_at" IS NULL)`,
+		},
+		{
+			name:     "always true",
+			module:   "package todo\n\nallowed {\n\tinput.user.id == \"u1\"\n}\n",
+			postgres: `TRUE`,
+			sqlite:   `1 = 1`,
+		},
+		{
+			name:     "never true",
+			module:   "package todo\n\nallowed {\n\tinput.user.id == \"u2\"\n}\n",
+			postgres: `FALSE`,
+			sqlite:   `1 = 0`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := datafilter.Translate(residualQueries(t, tc.module), tc.columns)
+			require.NoError(t, err)
+
+			where, args, err := filter.SQL(datafilter.Postgres)
+			require.NoError(t, err)
+			assert.Equal(t, tc.postgres, where)
+			assert.Equal(t, tc.args, args)
+
+			where, args, err = filter.SQL(datafilter.SQLite)
+			require.NoError(t, err)
+			assert.Equal(t, tc.sqlite, where)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestSQLNegatedConjunction(t *testing.T) {
+	filter := &datafilter.Expr{Op: datafilter.OpNot, Operands: []*datafilter.Expr{{
+		Op: datafilter.OpAnd,
+		Operands: []*datafilter.Expr{
+			{Op: datafilter.OpEq, Column: "status", Value: "closed"},
+			{Op: datafilter.OpEq, Column: "deleted_at", Value: nil},
+		},
+	}}}
+
+	where, args, err := filter.SQL(datafilter.Postgres)
+	require.NoError(t, err)
+
+	// the comparison of a NULL column is false, rather than NULL, within the negation.
+	assert.Equal(t, `NOT ((("status" IS NOT NULL AND "status" = $1) AND "deleted_at" IS NULL))`, where)
+	assert.Equal(t, []interface{}{"closed"}, args)
+}
+
+func TestTranslateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		module string
+	}{
+		{name: "unmapped nested field", module: "package todo\n\nallowed {\n\tinput.resource.owner.id == input.user.id\n}\n"},
+		{name: "unsupported call", module: "package todo\n\nallowed {\n\tstartswith(input.resource.name, \"a\")\n}\n"},
+		{name: "comparison of fields", module: "package todo\n\nallowed {\n\tinput.resource.a == input.resource.b\n}\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := datafilter.Translate(residualQueries(t, tc.module), nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSQLErrors(t *testing.T) {
+	_, _, err := (&datafilter.Expr{Op: datafilter.OpTrue}).SQL("mysql")
+	assert.Error(t, err)
+
+	_, _, err = (&datafilter.Expr{Op: datafilter.OpLt, Column: "level", Value: nil}).SQL(datafilter.Postgres)
+	assert.Error(t, err, "ordering comparison with null")
+
+	_, _, err = (&datafilter.Expr{Op: datafilter.OpNot}).SQL(datafilter.Postgres)
+	assert.Error(t, err)
+}
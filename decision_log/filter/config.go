@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"path"
+	"strings"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	OutcomeAllow = "allow"
+	OutcomeDeny  = "deny"
+)
+
+type Config struct {
+	// Decisions matching any of the rules are not logged.
+	Drop []Rule `json:"drop"`
+	// Percentage of the allow decisions that are logged, all allow decisions are logged when not set.
+	SampleAllowPercent *float64 `json:"sample_allow_percent"`
+	// Decision fields removed before the decision is logged, e.g. user.context.identity or resource.ssn.
+	Redact []string `json:"redact"`
+	// Decision fields replaced by their SHA-256 hash before the decision is logged, the fields must hold strings,
+	// or be nested in the resource context.
+	Hash []string `json:"hash"`
+}
+
+// Rule matches decisions by policy path and outcome.
+type Rule struct {
+	// Glob pattern matched against the policy path of the decision, e.g. todoApp.DELETE.*
+	Path string `json:"path"`
+	// Outcome of the decision, allow when all decision outcomes are true, deny otherwise.
+	Outcome string `json:"outcome"`
+}
+
+func (cfg *Config) Validate() error {
+	for i, rule := range cfg.Drop {
+		if rule.Path == "" && rule.Outcome == "" {
+			return errors.Errorf("drop[%d] - path or outcome must be set", i)
+		}
+		if _, err := path.Match(rule.Path, ""); err != nil {
+			return errors.Wrapf(err, "drop[%d].path", i)
+		}
+		if rule.Outcome != "" && rule.Outcome != OutcomeAllow && rule.Outcome != OutcomeDeny {
+			return errors.Errorf("drop[%d].outcome - unknown outcome %q, must be %s or %s", i, rule.Outcome, OutcomeAllow, OutcomeDeny)
+		}
+	}
+
+	if cfg.SampleAllowPercent != nil && (*cfg.SampleAllowPercent < 0 || *cfg.SampleAllowPercent > 100) {
+		return errors.New("sample_allow_percent must be between 0 and 100")
+	}
+
+	for i, field := range append(append([]string{}, cfg.Redact...), cfg.Hash...) {
+		if field == "" || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") || strings.Contains(field, "..") {
+			return errors.Errorf("invalid field path %q (%d)", field, i)
+		}
+	}
+
+	decision := (&api.Decision{}).ProtoReflect().Descriptor()
+	for i, field := range cfg.Hash {
+		if !hashable(decision, strings.Split(field, ".")) {
+			return errors.Errorf("hash[%d] - field %q does not hold a string", i, field)
+		}
+	}
+
+	return nil
+}
+
+// hashable reports whether the hash of the value at the field path can be stored in the decision.
+// Unknown fields are never set and are hashable.
+func hashable(md protoreflect.MessageDescriptor, field []string) bool {
+	fd := md.Fields().ByName(protoreflect.Name(field[0]))
+	if fd == nil {
+		return true
+	}
+
+	return hashableValue(fd, field[1:])
+}
+
+func hashableValue(fd protoreflect.FieldDescriptor, field []string) bool {
+	switch {
+	case fd.IsMap():
+		// the next path element is the map key.
+		return len(field) > 0 && hashableValue(fd.MapValue(), field[1:])
+	case fd.IsList():
+		return false
+	case fd.Kind() == protoreflect.StringKind:
+		return true
+	case fd.Kind() != protoreflect.MessageKind:
+		return false
+	}
+
+	switch fd.Message().FullName() {
+	case "google.protobuf.Value":
+		return true
+	case "google.protobuf.Struct":
+		// struct fields hold any JSON value, the struct itself must remain an object.
+		return len(field) > 0
+	default:
+		return len(field) > 0 && hashable(fd.Message(), field)
+	}
+}
@@ -0,0 +1,180 @@
+package filter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"path"
+	"strings"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Filter drops, samples and redacts decisions before they are logged.
+type Filter struct {
+	cfg    *Config
+	redact [][]string
+	hash   [][]string
+}
+
+func New(cfg *Config) (*Filter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	f := &Filter{cfg: cfg}
+	for _, field := range cfg.Redact {
+		f.redact = append(f.redact, strings.Split(field, "."))
+	}
+	for _, field := range cfg.Hash {
+		f.hash = append(f.hash, strings.Split(field, "."))
+	}
+
+	return f, nil
+}
+
+// Apply returns the decision to log, or nil when the decision is dropped.
+// Redacted decisions are copies, the given decision is not modified.
+func (f *Filter) Apply(d *api.Decision) (*api.Decision, error) {
+	if f == nil {
+		return d, nil
+	}
+
+	// the divergence records of shadow evaluations are never dropped or sampled.
+	if decisionlog.DecisionAPI(d.Annotations) != decisionlog.APIShadow && f.drops(d) {
+		return nil, nil
+	}
+
+	if len(f.redact) == 0 && len(f.hash) == 0 {
+		return d, nil
+	}
+
+	return f.redactFields(d)
+}
+
+// drops reports whether the decision matches a drop rule or is not sampled.
+func (f *Filter) drops(d *api.Decision) bool {
+	allowed := isAllowed(d)
+
+	for _, rule := range f.cfg.Drop {
+		if rule.matches(d, allowed) {
+			return true
+		}
+	}
+
+	return allowed && f.cfg.SampleAllowPercent != nil && rand.Float64()*100 >= *f.cfg.SampleAllowPercent //nolint:gosec // sampling does not need a secure random source.
+}
+
+func (r *Rule) matches(d *api.Decision, allowed bool) bool {
+	if r.Path != "" {
+		if ok, _ := path.Match(r.Path, d.Path); !ok {
+			return false
+		}
+	}
+
+	// Query, DecisionTree and Compile decisions have no outcomes, outcome rules only match Is decisions.
+	if r.Outcome != "" && decisionlog.DecisionAPI(d.Annotations) != decisionlog.APIIs {
+		return false
+	}
+
+	switch r.Outcome {
+	case OutcomeAllow:
+		return allowed
+	case OutcomeDeny:
+		return !allowed
+	default:
+		return true
+	}
+}
+
+// isAllowed reports whether all outcomes of the decision are true.
+func isAllowed(d *api.Decision) bool {
+	if len(d.Outcomes) == 0 {
+		return false
+	}
+
+	for _, outcome := range d.Outcomes {
+		if !outcome {
+			return false
+		}
+	}
+
+	return true
+}
+
+// redactFields removes and hashes the configured fields, using the JSON representation
+// of the decision with proto field names.
+func (f *Filter) redactFields(d *api.Decision) (*api.Decision, error) {
+	buf, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(d)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal decision")
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal decision")
+	}
+
+	for _, field := range f.redact {
+		update(fields, field, func(interface{}) (interface{}, bool) {
+			return nil, false
+		})
+	}
+
+	for _, field := range f.hash {
+		update(fields, field, func(v interface{}) (interface{}, bool) {
+			return hash(v), true
+		})
+	}
+
+	if buf, err = json.Marshal(fields); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal redacted decision")
+	}
+
+	redacted := &api.Decision{}
+	if err := protojson.Unmarshal(buf, redacted); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal redacted decision")
+	}
+
+	return redacted, nil
+}
+
+// update replaces the value at the field path, the field is removed when fn returns false.
+func update(fields map[string]interface{}, field []string, fn func(interface{}) (interface{}, bool)) {
+	for i, name := range field {
+		v, ok := fields[name]
+		if !ok {
+			return
+		}
+
+		if i == len(field)-1 {
+			if value, keep := fn(v); keep {
+				fields[name] = value
+			} else {
+				delete(fields, name)
+			}
+			return
+		}
+
+		if fields, ok = v.(map[string]interface{}); !ok {
+			return
+		}
+	}
+}
+
+func hash(v interface{}) string {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		buf = []byte(s)
+	}
+
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,139 @@
+package filter_test
+
+import (
+	"testing"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func testDecision(decisionAPI string, outcome bool) *api.Decision {
+	d := &api.Decision{
+		Id:        "1",
+		Timestamp: timestamppb.Now(),
+		Path:      "todoApp.GET.todos",
+		User: &api.DecisionUser{
+			Id:      "u1",
+			Email:   "user@test",
+			Context: &api.IdentityContext{Identity: "user@test", Type: api.IdentityType_IDENTITY_TYPE_SUB},
+		},
+		Resource: &structpb.Struct{Fields: map[string]*structpb.Value{
+			"ssn":   structpb.NewStringValue("123"),
+			"count": structpb.NewNumberValue(2),
+		}},
+		Outcomes: map[string]bool{"allowed": outcome},
+	}
+	if decisionAPI != "" {
+		d.Annotations = map[string]string{decisionlog.AnnotationAPI: decisionAPI}
+	}
+	return d
+}
+
+func TestHashValidation(t *testing.T) {
+	tests := []struct {
+		field string
+		valid bool
+	}{
+		{field: "user.email", valid: true},
+		{field: "user.context.identity", valid: true},
+		{field: "resource.ssn", valid: true},
+		{field: "resource.count", valid: true},
+		{field: "annotations.query", valid: true},
+		{field: "unknown", valid: true},
+		{field: "resource", valid: false},
+		{field: "user", valid: false},
+		{field: "user.context.type", valid: false},
+		{field: "outcomes", valid: false},
+		{field: "outcomes.allowed", valid: false},
+		{field: "timestamp", valid: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.field, func(t *testing.T) {
+			_, err := filter.New(&filter.Config{Hash: []string{tc.field}})
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	f, err := filter.New(&filter.Config{
+		Redact: []string{"user.context.identity", "resource.ssn"},
+		Hash:   []string{"user.email", "resource.count"},
+	})
+	require.NoError(t, err)
+
+	d := testDecision("", true)
+	redacted, err := f.Apply(d)
+	require.NoError(t, err)
+	require.NotNil(t, redacted)
+
+	assert.Empty(t, redacted.User.Context.Identity)
+	assert.NotContains(t, redacted.Resource.Fields, "ssn")
+	assert.Len(t, redacted.User.Email, 64)
+	assert.NotEqual(t, "user@test", redacted.User.Email)
+	assert.Len(t, redacted.Resource.Fields["count"].GetStringValue(), 64)
+	assert.Equal(t, "u1", redacted.User.Id)
+
+	// the given decision is not modified.
+	assert.Equal(t, "user@test", d.User.Email)
+	assert.Contains(t, d.Resource.Fields, "ssn")
+}
+
+func TestShadowRecordsAreNotDropped(t *testing.T) {
+	zero := 0.0
+	f, err := filter.New(&filter.Config{
+		Drop:               []filter.Rule{{Path: "todoApp.*"}},
+		SampleAllowPercent: &zero,
+		Hash:               []string{"user.email"},
+	})
+	require.NoError(t, err)
+
+	d, err := f.Apply(testDecision(decisionlog.APIIs, true))
+	require.NoError(t, err)
+	assert.Nil(t, d)
+
+	d, err = f.Apply(testDecision(decisionlog.APIShadow, true))
+	require.NoError(t, err)
+	require.NotNil(t, d, "shadow record dropped")
+	assert.NotEqual(t, "user@test", d.User.Email, "shadow record not redacted")
+}
+
+func TestDropRules(t *testing.T) {
+	zero := 0.0
+
+	tests := []struct {
+		name    string
+		cfg     filter.Config
+		d       *api.Decision
+		dropped bool
+	}{
+		{name: "path", cfg: filter.Config{Drop: []filter.Rule{{Path: "todoApp.GET.*"}}}, d: testDecision("", false), dropped: true},
+		{name: "other path", cfg: filter.Config{Drop: []filter.Rule{{Path: "todoApp.POST.*"}}}, d: testDecision("", false)},
+		{name: "allow outcome", cfg: filter.Config{Drop: []filter.Rule{{Outcome: filter.OutcomeAllow}}}, d: testDecision("", true), dropped: true},
+		{name: "deny outcome", cfg: filter.Config{Drop: []filter.Rule{{Outcome: filter.OutcomeAllow}}}, d: testDecision("", false)},
+		{name: "outcome of query decision", cfg: filter.Config{Drop: []filter.Rule{{Outcome: filter.OutcomeDeny}}}, d: testDecision(decisionlog.APIQuery, false)},
+		{name: "sampled allow", cfg: filter.Config{SampleAllowPercent: &zero}, d: testDecision("", true), dropped: true},
+		{name: "deny is not sampled", cfg: filter.Config{SampleAllowPercent: &zero}, d: testDecision("", false)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := filter.New(&tc.cfg)
+			require.NoError(t, err)
+
+			d, err := f.Apply(tc.d)
+			require.NoError(t, err)
+			assert.Equal(t, tc.dropped, d == nil)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package async
+
+import (
+	"github.com/pkg/errors"
+)
+
+// OverflowPolicy selects what happens to a decision logged while the queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for room in the queue.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest drops the oldest queued decision to make room for the new one.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowDropNewest drops the new decision.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+)
+
+type Config struct {
+	Enabled             bool           `json:"enabled"`
+	QueueSize           int            `json:"queue_size"`
+	Workers             int            `json:"workers"`
+	Overflow            OverflowPolicy `json:"overflow"`
+	FlushTimeoutSeconds int            `json:"flush_timeout_seconds"`
+}
+
+func (cfg *Config) SetDefaults() {
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.Workers == 0 {
+		cfg.Workers = 1
+	}
+	if cfg.Overflow == "" {
+		cfg.Overflow = OverflowDropOldest
+	}
+	if cfg.FlushTimeoutSeconds == 0 {
+		cfg.FlushTimeoutSeconds = 5
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.QueueSize < 0 {
+		return errors.New("queue_size must be positive")
+	}
+	if cfg.Workers < 0 {
+		return errors.New("workers must be positive")
+	}
+	if cfg.FlushTimeoutSeconds < 0 {
+		return errors.New("flush_timeout_seconds must be positive or 0")
+	}
+
+	switch cfg.Overflow {
+	case "", OverflowBlock, OverflowDropOldest, OverflowDropNewest:
+		return nil
+	default:
+		return errors.Errorf("unknown overflow policy %q, must be one of %s, %s or %s", cfg.Overflow, OverflowBlock, OverflowDropOldest, OverflowDropNewest)
+	}
+}
@@ -0,0 +1,219 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/rs/zerolog"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+var (
+	measureQueued  = stats.Int64("topaz/decision_log/queued", "Number of decisions queued", stats.UnitDimensionless)
+	measureDropped = stats.Int64("topaz/decision_log/dropped", "Number of decisions dropped", stats.UnitDimensionless)
+	measureFailed  = stats.Int64("topaz/decision_log/failed", "Number of decisions the backend failed to log", stats.UnitDimensionless)
+
+	// Views exports the decision log counters.
+	Views = []*view.View{
+		{Name: "topaz/decision_log/queued", Measure: measureQueued, Description: measureQueued.Description(), Aggregation: view.Sum()},
+		{Name: "topaz/decision_log/dropped", Measure: measureDropped, Description: measureDropped.Description(), Aggregation: view.Sum()},
+		{Name: "topaz/decision_log/failed", Measure: measureFailed, Description: measureFailed.Description(), Aggregation: view.Sum()},
+	}
+)
+
+// Stats holds the counters of an asynchronous decision logger.
+type Stats struct {
+	// Number of decisions queued since the logger started.
+	Queued uint64
+	// Number of decisions dropped because the queue was full.
+	Dropped uint64
+	// Number of decisions the backend failed to log.
+	Failed uint64
+	// Number of decisions waiting in the queue.
+	Pending int
+}
+
+// Logger is a decision logger queuing decisions in a bounded in-memory queue,
+// the decisions are logged to the backend logger by background workers.
+type Logger struct {
+	ctx     context.Context
+	cfg     *Config
+	logger  *zerolog.Logger
+	backend decisionlog.DecisionLogger
+
+	// mu guards sending to the queue against closing it, it is never held while a send is blocked.
+	mu    sync.RWMutex
+	queue chan *api.Decision
+	// closing is closed when the logger shuts down, Log calls blocked on a full queue give up.
+	closing  chan struct{}
+	shutdown sync.Once
+	// stop is closed when the flush timeout expires, the workers abandon the queued decisions.
+	stop chan struct{}
+	wg   sync.WaitGroup
+
+	queued  atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+var _ decisionlog.DecisionLogger = (*Logger)(nil)
+
+func New(ctx context.Context, cfg *Config, logger *zerolog.Logger, backend decisionlog.DecisionLogger) (*Logger, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg.SetDefaults()
+
+	newLogger := logger.With().Str("component", "decision-logger").Logger()
+
+	l := &Logger{
+		ctx:     ctx,
+		cfg:     cfg,
+		logger:  &newLogger,
+		backend: backend,
+		queue:   make(chan *api.Decision, cfg.QueueSize),
+		closing: make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+
+	for i := 0; i < cfg.Workers; i++ {
+		l.wg.Add(1)
+		go l.worker()
+	}
+
+	return l, nil
+}
+
+// Log queues the decision, applying the overflow policy when the queue is full.
+// Decisions logged while the logger shuts down are dropped, they never fail the call.
+func (l *Logger) Log(d *api.Decision) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	select {
+	case <-l.closing:
+		l.drop()
+		return nil
+	default:
+	}
+
+	switch l.cfg.Overflow {
+	case OverflowBlock:
+		select {
+		case l.queue <- d:
+		case <-l.closing:
+			l.drop()
+			return nil
+		}
+
+	case OverflowDropNewest:
+		select {
+		case l.queue <- d:
+		default:
+			l.drop()
+			return nil
+		}
+
+	default:
+		for sent := false; !sent; {
+			select {
+			case l.queue <- d:
+				sent = true
+			default:
+				select {
+				case <-l.queue:
+					l.drop()
+				default:
+				}
+			}
+		}
+	}
+
+	l.queued.Add(1)
+	stats.Record(l.ctx, measureQueued.M(1))
+
+	return nil
+}
+
+// Shutdown stops accepting decisions and waits, up to the flush timeout, for the queued decisions
+// to be logged. The backend logger is shut down once all workers have returned. When the timeout
+// expires the remaining decisions are abandoned and the backend logger is shut down without waiting
+// for the workers, a worker stuck in the backend returns once the backend gives up.
+func (l *Logger) Shutdown() {
+	l.shutdown.Do(func() {
+		// the flush timeout bounds the whole shutdown.
+		timeout := time.NewTimer(time.Duration(l.cfg.FlushTimeoutSeconds) * time.Second)
+		defer timeout.Stop()
+
+		// blocked Log calls return once closing is closed, the queue is then closed without pending sends.
+		close(l.closing)
+
+		l.mu.Lock()
+		close(l.queue)
+		l.mu.Unlock()
+
+		flushed := make(chan struct{})
+		go func() {
+			l.wg.Wait()
+			close(flushed)
+		}()
+
+		select {
+		case <-flushed:
+		case <-timeout.C:
+			l.logger.Warn().Int("pending", len(l.queue)).Msg("timeout while flushing decision logs")
+			close(l.stop)
+		}
+
+		l.backend.Shutdown()
+	})
+}
+
+// Stats returns the current counters of the logger.
+func (l *Logger) Stats() Stats {
+	return Stats{
+		Queued:  l.queued.Load(),
+		Dropped: l.dropped.Load(),
+		Failed:  l.failed.Load(),
+		Pending: len(l.queue),
+	}
+}
+
+func (l *Logger) worker() {
+	defer l.wg.Done()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		default:
+		}
+
+		var d *api.Decision
+		select {
+		case <-l.stop:
+			return
+		case next, ok := <-l.queue:
+			if !ok {
+				return
+			}
+			d = next
+		}
+
+		if err := l.backend.Log(d); err != nil {
+			l.failed.Add(1)
+			stats.Record(l.ctx, measureFailed.M(1))
+			l.logger.Error().Err(err).Str("decision_id", d.Id).Msg("failed to log decision")
+		}
+	}
+}
+
+func (l *Logger) drop() {
+	l.dropped.Add(1)
+	stats.Record(l.ctx, measureDropped.M(1))
+}
@@ -0,0 +1,279 @@
+package async_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/topaz/decision_log/logger/async"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBackend records the decisions it logs, each Log call waits for the gate to open when it is set.
+type testBackend struct {
+	gate    chan struct{}
+	started chan string
+	fail    string
+
+	mu       sync.Mutex
+	logged   []string
+	shutdown bool
+}
+
+func newTestBackend(gated bool) *testBackend {
+	b := &testBackend{started: make(chan string, 100)}
+	if gated {
+		b.gate = make(chan struct{})
+	}
+	return b
+}
+
+func (b *testBackend) Log(d *api.Decision) error {
+	b.started <- d.Id
+	if b.gate != nil {
+		<-b.gate
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.logged = append(b.logged, d.Id)
+
+	if d.Id == b.fail {
+		return errors.New("backend unavailable")
+	}
+	return nil
+}
+
+func (b *testBackend) Shutdown() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.shutdown = true
+}
+
+func (b *testBackend) state() ([]string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string{}, b.logged...), b.shutdown
+}
+
+// waitStarted waits for the backend to start logging the decision.
+func (b *testBackend) waitStarted(t *testing.T, id string) {
+	select {
+	case started := <-b.started:
+		require.Equal(t, id, started)
+	case <-time.After(time.Second):
+		require.FailNow(t, "decision not logged", id)
+	}
+}
+
+func newTestLogger(t *testing.T, cfg *async.Config, backend *testBackend) *async.Logger {
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	l, err := async.New(context.Background(), cfg, &logger, backend)
+	require.NoError(t, err)
+
+	return l
+}
+
+func logDecision(t *testing.T, l *async.Logger, id string) {
+	require.NoError(t, l.Log(&api.Decision{Id: id}))
+}
+
+// logAsync logs the decision in the background, the returned channel is closed once Log returned.
+func logAsync(t *testing.T, l *async.Logger, id string) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, l.Log(&api.Decision{Id: id}))
+	}()
+	return done
+}
+
+func returns(done chan struct{}, timeout time.Duration) bool {
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	backend := newTestBackend(true)
+	l := newTestLogger(t, &async.Config{QueueSize: 1, Overflow: async.OverflowBlock}, backend)
+
+	// the worker is busy with the first decision and the second one fills the queue.
+	logDecision(t, l, "1")
+	backend.waitStarted(t, "1")
+	logDecision(t, l, "2")
+
+	done := logAsync(t, l, "3")
+	assert.False(t, returns(done, 50*time.Millisecond), "Log did not block")
+
+	close(backend.gate)
+	assert.True(t, returns(done, time.Second), "Log still blocked")
+
+	l.Shutdown()
+
+	logged, _ := backend.state()
+	assert.Equal(t, []string{"1", "2", "3"}, logged)
+	assert.Equal(t, async.Stats{Queued: 3}, l.Stats())
+}
+
+func TestOverflowDropNewest(t *testing.T) {
+	backend := newTestBackend(true)
+	l := newTestLogger(t, &async.Config{QueueSize: 1, Overflow: async.OverflowDropNewest}, backend)
+
+	logDecision(t, l, "1")
+	backend.waitStarted(t, "1")
+	logDecision(t, l, "2")
+	logDecision(t, l, "3")
+
+	assert.Equal(t, async.Stats{Queued: 2, Dropped: 1, Pending: 1}, l.Stats())
+
+	close(backend.gate)
+	l.Shutdown()
+
+	logged, _ := backend.state()
+	assert.Equal(t, []string{"1", "2"}, logged)
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	backend := newTestBackend(true)
+	l := newTestLogger(t, &async.Config{QueueSize: 1, Overflow: async.OverflowDropOldest}, backend)
+
+	logDecision(t, l, "1")
+	backend.waitStarted(t, "1")
+	logDecision(t, l, "2")
+	logDecision(t, l, "3")
+
+	// the second decision was queued before it was dropped.
+	assert.Equal(t, async.Stats{Queued: 3, Dropped: 1, Pending: 1}, l.Stats())
+
+	close(backend.gate)
+	l.Shutdown()
+
+	logged, _ := backend.state()
+	assert.Equal(t, []string{"1", "3"}, logged)
+}
+
+func TestFailed(t *testing.T) {
+	backend := newTestBackend(false)
+	backend.fail = "2"
+	l := newTestLogger(t, &async.Config{}, backend)
+
+	for _, id := range []string{"1", "2", "3"} {
+		logDecision(t, l, id)
+	}
+	l.Shutdown()
+
+	logged, _ := backend.state()
+	assert.Equal(t, []string{"1", "2", "3"}, logged)
+	assert.Equal(t, async.Stats{Queued: 3, Failed: 1}, l.Stats())
+}
+
+func TestShutdownFlush(t *testing.T) {
+	backend := newTestBackend(true)
+	l := newTestLogger(t, &async.Config{Workers: 2}, backend)
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		logDecision(t, l, id)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Shutdown()
+	}()
+
+	// the queued decisions are logged before the backend is shut down.
+	assert.False(t, returns(done, 50*time.Millisecond), "Shutdown did not wait for the queued decisions")
+	_, shutdown := backend.state()
+	assert.False(t, shutdown)
+
+	close(backend.gate)
+	assert.True(t, returns(done, time.Second), "Shutdown did not return")
+
+	logged, shutdown := backend.state()
+	assert.ElementsMatch(t, []string{"1", "2", "3", "4"}, logged)
+	assert.True(t, shutdown)
+	assert.Equal(t, async.Stats{Queued: 4}, l.Stats())
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	backend := newTestBackend(true)
+	t.Cleanup(func() { close(backend.gate) })
+
+	l := newTestLogger(t, &async.Config{FlushTimeoutSeconds: 1}, backend)
+
+	// the backend is stuck with the first decision.
+	logDecision(t, l, "1")
+	backend.waitStarted(t, "1")
+	logDecision(t, l, "2")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Shutdown()
+	}()
+
+	assert.False(t, returns(done, 500*time.Millisecond), "Shutdown did not wait for the flush timeout")
+	assert.True(t, returns(done, 2*time.Second), "Shutdown did not return after the flush timeout")
+
+	// the backend is shut down without waiting for the stuck worker.
+	logged, shutdown := backend.state()
+	assert.Empty(t, logged)
+	assert.True(t, shutdown)
+	assert.Equal(t, async.Stats{Queued: 2, Pending: 1}, l.Stats())
+}
+
+func TestLogAfterShutdown(t *testing.T) {
+	backend := newTestBackend(false)
+	l := newTestLogger(t, &async.Config{}, backend)
+
+	logDecision(t, l, "1")
+	l.Shutdown()
+	logDecision(t, l, "2")
+
+	// a second shutdown is a no-op.
+	l.Shutdown()
+
+	logged, _ := backend.state()
+	assert.Equal(t, []string{"1"}, logged)
+	assert.Equal(t, async.Stats{Queued: 1, Dropped: 1}, l.Stats())
+}
+
+func TestShutdownBlockedLog(t *testing.T) {
+	backend := newTestBackend(true)
+	l := newTestLogger(t, &async.Config{QueueSize: 1, Overflow: async.OverflowBlock, FlushTimeoutSeconds: 5}, backend)
+
+	logDecision(t, l, "1")
+	backend.waitStarted(t, "1")
+	logDecision(t, l, "2")
+
+	blocked := logAsync(t, l, "3")
+	assert.False(t, returns(blocked, 50*time.Millisecond), "Log did not block")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Shutdown()
+	}()
+
+	// the blocked decision is dropped once the shutdown starts, the queued ones are still flushed.
+	assert.True(t, returns(blocked, time.Second), "Log still blocked")
+	assert.False(t, returns(done, 50*time.Millisecond), "Shutdown did not wait for the queued decisions")
+
+	close(backend.gate)
+	assert.True(t, returns(done, time.Second), "Shutdown did not return")
+
+	logged, _ := backend.state()
+	assert.Equal(t, []string{"1", "2"}, logged)
+	assert.Equal(t, async.Stats{Queued: 2, Dropped: 1}, l.Stats())
+}
@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	// FormatNDJSON sends a batch as newline delimited JSON records.
+	FormatNDJSON = "ndjson"
+	// FormatJSON sends a batch as a JSON array of records.
+	FormatJSON = "json"
+)
+
+type Config struct {
+	URL     string            `json:"url"`
+	Format  string            `json:"format"`
+	Headers map[string]string `json:"headers"`
+	Gzip    bool              `json:"gzip"`
+
+	// a batch is sent when it holds max_batch_size decisions, its encoded size reaches max_batch_bytes,
+	// or when flush_interval_seconds elapsed since the previous flush.
+	MaxBatchSize         int `json:"max_batch_size"`
+	MaxBatchBytes        int `json:"max_batch_bytes"`
+	FlushIntervalSeconds int `json:"flush_interval_seconds"`
+	// maximum number of batches waiting to be sent, new batches are dropped when exceeded.
+	MaxPendingBatches int `json:"max_pending_batches"`
+
+	TimeoutSeconds int `json:"timeout_seconds"`
+	// maximum number of retries of a failed batch, 0 disables the retries. Defaults to 5 when unset.
+	MaxRetries          *int `json:"max_retries"`
+	RetryMinBackoffMS   int  `json:"retry_min_backoff_ms"`
+	RetryMaxBackoffMS   int  `json:"retry_max_backoff_ms"`
+	FlushTimeoutSeconds int  `json:"flush_timeout_seconds"`
+
+	ClientCertPath string `json:"client_cert_path"`
+	ClientKeyPath  string `json:"client_key_path"`
+	CACertPath     string `json:"ca_cert_path"`
+	Insecure       bool   `json:"insecure"`
+}
+
+func (cfg *Config) SetDefaults() {
+	if cfg.Format == "" {
+		cfg.Format = FormatNDJSON
+	}
+	if cfg.MaxBatchSize == 0 {
+		cfg.MaxBatchSize = 100
+	}
+	if cfg.MaxBatchBytes == 0 {
+		cfg.MaxBatchBytes = 1024 * 1024
+	}
+	if cfg.FlushIntervalSeconds == 0 {
+		cfg.FlushIntervalSeconds = 5
+	}
+	if cfg.MaxPendingBatches == 0 {
+		cfg.MaxPendingBatches = 100
+	}
+	if cfg.TimeoutSeconds == 0 {
+		cfg.TimeoutSeconds = 10
+	}
+	if cfg.MaxRetries == nil {
+		cfg.MaxRetries = lo.ToPtr(5)
+	}
+	if cfg.RetryMinBackoffMS == 0 {
+		cfg.RetryMinBackoffMS = 100
+	}
+	if cfg.RetryMaxBackoffMS == 0 {
+		cfg.RetryMaxBackoffMS = 10000
+	}
+	if cfg.FlushTimeoutSeconds == 0 {
+		cfg.FlushTimeoutSeconds = 5
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.URL == "" {
+		return errors.New("url not set")
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return errors.Wrap(err, "invalid url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Errorf("invalid url scheme %q", u.Scheme)
+	}
+
+	if cfg.Format != FormatNDJSON && cfg.Format != FormatJSON {
+		return errors.Errorf("unknown format %q, must be %s or %s", cfg.Format, FormatNDJSON, FormatJSON)
+	}
+
+	if (cfg.ClientCertPath == "") != (cfg.ClientKeyPath == "") {
+		return errors.New("client_cert_path and client_key_path must be set together")
+	}
+
+	if cfg.MaxRetries != nil && *cfg.MaxRetries < 0 {
+		return errors.New("max_retries must be positive or 0")
+	}
+
+	if cfg.RetryMaxBackoffMS < cfg.RetryMinBackoffMS {
+		return errors.New("retry_max_backoff_ms must not be less than retry_min_backoff_ms")
+	}
+
+	return nil
+}
@@ -0,0 +1,307 @@
+package webhook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// webhookLogger sends batches of decisions to an HTTP endpoint.
+type webhookLogger struct {
+	// ctx is canceled when Shutdown gives up flushing, aborting in-flight requests.
+	ctx    context.Context
+	cancel context.CancelFunc
+	cfg    *Config
+	logger *zerolog.Logger
+	client *http.Client
+
+	// mu guards the current batch and the pending batches channel.
+	mu      sync.Mutex
+	closed  bool
+	batch   []json.RawMessage
+	size    int
+	pending chan []json.RawMessage
+
+	done chan struct{}
+}
+
+func New(ctx context.Context, cfg *Config, logger *zerolog.Logger) (decisionlog.DecisionLogger, error) {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := tlsClientConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	newLogger := logger.With().Str("component", "webhook-decision-logger").Logger()
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	l := &webhookLogger{
+		ctx:    ctx,
+		cancel: cancel,
+		cfg:    cfg,
+		logger: &newLogger,
+		client: &http.Client{
+			Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+		},
+		pending: make(chan []json.RawMessage, cfg.MaxPendingBatches),
+		done:    make(chan struct{}),
+	}
+
+	go l.run()
+
+	return l, nil
+}
+
+func tlsClientConfig(cfg *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.Insecure, //nolint:gosec // opt-in, for test environments.
+	}
+
+	if cfg.CACertPath != "" {
+		caCert, err := os.ReadFile(cfg.CACertPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read ca cert")
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("failed to append ca cert")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if cfg.ClientCertPath != "" {
+		clientCert, err := tls.LoadX509KeyPair(cfg.ClientCertPath, cfg.ClientKeyPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (l *webhookLogger) Log(d *api.Decision) error {
+	record, err := json.Marshal(d)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling decision")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return errors.New("decision logger is shut down")
+	}
+
+	l.batch = append(l.batch, record)
+	l.size += len(record) + 1
+
+	if len(l.batch) >= l.cfg.MaxBatchSize || l.size >= l.cfg.MaxBatchBytes {
+		l.flush()
+	}
+
+	return nil
+}
+
+// Shutdown sends the current batch and waits, up to the flush timeout, for the pending batches to be sent.
+// When the timeout expires, in-flight requests are canceled and the remaining batches are dropped.
+func (l *webhookLogger) Shutdown() {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	l.flush()
+	l.closed = true
+	close(l.pending)
+	l.mu.Unlock()
+
+	select {
+	case <-l.done:
+	case <-time.After(time.Duration(l.cfg.FlushTimeoutSeconds) * time.Second):
+		l.logger.Warn().Int("pending_batches", len(l.pending)).Msg("timeout while flushing decision logs")
+		l.cancel()
+		<-l.done
+	}
+
+	l.cancel()
+}
+
+// flush moves the current batch to the pending batches, l.mu must be held.
+func (l *webhookLogger) flush() {
+	if len(l.batch) == 0 {
+		return
+	}
+
+	select {
+	case l.pending <- l.batch:
+	default:
+		l.logger.Error().Int("decisions", len(l.batch)).Msg("too many pending batches, dropping decisions")
+	}
+
+	l.batch = nil
+	l.size = 0
+}
+
+func (l *webhookLogger) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(time.Duration(l.cfg.FlushIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case batch, ok := <-l.pending:
+			if !ok {
+				return
+			}
+			l.send(batch)
+
+		case <-ticker.C:
+			l.mu.Lock()
+			if !l.closed {
+				l.flush()
+			}
+			l.mu.Unlock()
+		}
+	}
+}
+
+// send posts the batch, retrying failed requests with exponential backoff.
+func (l *webhookLogger) send(batch []json.RawMessage) {
+	if l.ctx.Err() != nil {
+		l.logger.Error().Int("decisions", len(batch)).Msg("failed to send decisions, shutting down")
+		return
+	}
+
+	body, err := l.encode(batch)
+	if err != nil {
+		l.logger.Error().Err(err).Int("decisions", len(batch)).Msg("failed to encode decisions")
+		return
+	}
+
+	backoff := time.Duration(l.cfg.RetryMinBackoffMS) * time.Millisecond
+	maxBackoff := time.Duration(l.cfg.RetryMaxBackoffMS) * time.Millisecond
+
+	for attempt := 0; ; attempt++ {
+		retry, err := l.post(body)
+		if err == nil {
+			return
+		}
+
+		if !retry || attempt >= *l.cfg.MaxRetries {
+			l.logger.Error().Err(err).Int("decisions", len(batch)).Int("attempts", attempt+1).Msg("failed to send decisions")
+			return
+		}
+
+		l.logger.Warn().Err(err).Dur("backoff", backoff).Msg("failed to send decisions, retrying")
+
+		select {
+		case <-time.After(backoff):
+		case <-l.ctx.Done():
+			l.logger.Error().Err(err).Int("decisions", len(batch)).Msg("failed to send decisions, shutting down")
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (l *webhookLogger) encode(batch []json.RawMessage) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	var w io.Writer = buf
+	var zw *gzip.Writer
+	if l.cfg.Gzip {
+		zw = gzip.NewWriter(buf)
+		w = zw
+	}
+
+	var err error
+	switch l.cfg.Format {
+	case FormatJSON:
+		err = json.NewEncoder(w).Encode(batch)
+	default:
+		for _, record := range batch {
+			if _, err = w.Write(append(record, '\n')); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// post sends the body to the webhook, the returned bool reports whether a failed request can be retried.
+func (l *webhookLogger) post(body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(l.ctx, http.MethodPost, l.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	for name, value := range l.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if l.cfg.Format == FormatJSON {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+
+	if l.cfg.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,230 @@
+package webhook_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/topaz/decision_log/logger/webhook"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// request is a request received by the test webhook.
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// testWebhook records the requests it receives and responds with the configured status codes in turn,
+// the last status code is repeated.
+type testWebhook struct {
+	mu       sync.Mutex
+	requests []request
+	statuses []int
+}
+
+func (h *testWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	h.mu.Lock()
+	h.requests = append(h.requests, request{header: r.Header.Clone(), body: body})
+	status := http.StatusOK
+	if n := len(h.requests); len(h.statuses) > 0 {
+		status = h.statuses[min(n, len(h.statuses))-1]
+	}
+	h.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+func (h *testWebhook) received() []request {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]request(nil), h.requests...)
+}
+
+func testLogger() *zerolog.Logger {
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+	return &logger
+}
+
+func decision(id string) *api.Decision {
+	return &api.Decision{Id: id}
+}
+
+// ndjsonIDs returns the decision IDs of a newline delimited JSON body.
+func ndjsonIDs(t *testing.T, body []byte) []string {
+	ids := []string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var d api.Decision
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &d))
+		ids = append(ids, d.Id)
+	}
+	require.NoError(t, scanner.Err())
+
+	return ids
+}
+
+func TestBatching(t *testing.T) {
+	h := &testWebhook{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	cfg := &webhook.Config{URL: srv.URL, MaxBatchSize: 2, FlushIntervalSeconds: 60}
+	l, err := webhook.New(context.Background(), cfg, testLogger())
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		require.NoError(t, l.Log(decision(id)))
+	}
+	l.Shutdown()
+
+	requests := h.received()
+	require.Len(t, requests, 3)
+
+	// full batches are sent as they fill up, the last partial batch on shutdown.
+	assert.Equal(t, []string{"1", "2"}, ndjsonIDs(t, requests[0].body))
+	assert.Equal(t, []string{"3", "4"}, ndjsonIDs(t, requests[1].body))
+	assert.Equal(t, []string{"5"}, ndjsonIDs(t, requests[2].body))
+	assert.Equal(t, "application/x-ndjson", requests[0].header.Get("Content-Type"))
+
+	assert.Error(t, l.Log(decision("6")), "decision logged after shutdown")
+}
+
+func TestFlushInterval(t *testing.T) {
+	h := &testWebhook{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	cfg := &webhook.Config{URL: srv.URL, FlushIntervalSeconds: 1}
+	l, err := webhook.New(context.Background(), cfg, testLogger())
+	require.NoError(t, err)
+	defer l.Shutdown()
+
+	require.NoError(t, l.Log(decision("1")))
+
+	assert.Eventually(t, func() bool { return len(h.received()) == 1 }, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries *int
+		statuses   []int
+		requests   int
+	}{
+		{name: "server errors are retried", maxRetries: lo.ToPtr(2), statuses: []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK}, requests: 3},
+		{name: "too many requests is retried", maxRetries: lo.ToPtr(2), statuses: []int{http.StatusTooManyRequests, http.StatusOK}, requests: 2},
+		{name: "client errors are not retried", maxRetries: lo.ToPtr(2), statuses: []int{http.StatusBadRequest}, requests: 1},
+		{name: "retries are bounded", maxRetries: lo.ToPtr(2), statuses: []int{http.StatusServiceUnavailable}, requests: 3},
+		{name: "retries are disabled", maxRetries: lo.ToPtr(0), statuses: []int{http.StatusServiceUnavailable}, requests: 1},
+		{name: "retries default to 5", statuses: []int{http.StatusServiceUnavailable}, requests: 6},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &testWebhook{statuses: tc.statuses}
+			srv := httptest.NewServer(h)
+			defer srv.Close()
+
+			cfg := &webhook.Config{
+				URL:               srv.URL,
+				MaxRetries:        tc.maxRetries,
+				RetryMinBackoffMS: 1,
+				RetryMaxBackoffMS: 2,
+			}
+			l, err := webhook.New(context.Background(), cfg, testLogger())
+			require.NoError(t, err)
+
+			require.NoError(t, l.Log(decision("1")))
+			l.Shutdown()
+
+			requests := h.received()
+			require.Len(t, requests, tc.requests)
+			for _, r := range requests {
+				assert.Equal(t, []string{"1"}, ndjsonIDs(t, r.body), "retries send the same batch")
+			}
+		})
+	}
+}
+
+func TestGzip(t *testing.T) {
+	h := &testWebhook{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	cfg := &webhook.Config{
+		URL:     srv.URL,
+		Format:  webhook.FormatJSON,
+		Gzip:    true,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}
+	l, err := webhook.New(context.Background(), cfg, testLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, l.Log(decision("1")))
+	require.NoError(t, l.Log(decision("2")))
+	l.Shutdown()
+
+	requests := h.received()
+	require.Len(t, requests, 1)
+
+	header := requests[0].header
+	assert.Equal(t, "gzip", header.Get("Content-Encoding"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+
+	zr, err := gzip.NewReader(bytes.NewReader(requests[0].body))
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+
+	var batch []*api.Decision
+	require.NoError(t, json.Unmarshal(body, &batch))
+	require.Len(t, batch, 2)
+	assert.Equal(t, "1", batch[0].Id)
+	assert.Equal(t, "2", batch[1].Id)
+}
+
+func TestShutdownCancelsRequests(t *testing.T) {
+	canceled := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the server notices the client going away once the body is read.
+		_, _ = io.ReadAll(r.Body)
+		<-r.Context().Done()
+		close(canceled)
+	}))
+	defer srv.Close()
+
+	cfg := &webhook.Config{URL: srv.URL, FlushTimeoutSeconds: 1, TimeoutSeconds: 60}
+	l, err := webhook.New(context.Background(), cfg, testLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, l.Log(decision("1")))
+
+	start := time.Now()
+	l.Shutdown()
+	assert.Less(t, time.Since(start), 10*time.Second, "shutdown waited for the request timeout")
+
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight request not canceled on shutdown")
+	}
+}
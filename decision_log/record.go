@@ -0,0 +1,156 @@
+package decisionlog
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Authorizer APIs recorded in the decision log.
+const (
+	APIIs           = "is"
+	APIQuery        = "query"
+	APIDecisionTree = "decisiontree"
+	APICompile      = "compile"
+)
+
+// APIs lists the authorizer APIs that can be recorded in the decision log.
+var APIs = []string{APIIs, APIQuery, APIDecisionTree, APICompile}
+
+// APIShadow is the API of the divergence records of shadow evaluations, which are always logged.
+const APIShadow = "shadow"
+
+// Annotation keys of the evaluation record of a decision.
+const (
+	AnnotationAPI             = "api"
+	AnnotationQuery           = "query"
+	AnnotationInputDigest     = "input_digest"
+	AnnotationResult          = "result"
+	AnnotationCallerAddress   = "caller_address"
+	AnnotationCallerUserAgent = "caller_user_agent"
+
+	// AnnotationDiverged lists the decisions of a divergence record whose shadow outcome differs.
+	AnnotationDiverged = "diverged"
+	// AnnotationShadowOutcomes holds the shadow outcomes of a divergence record, as "<decision>=<outcome>" pairs.
+	AnnotationShadowOutcomes = "shadow_outcomes"
+)
+
+// Evaluation records how a decision was evaluated, it is logged as the annotations of the decision.
+type Evaluation struct {
+	// API is the authorizer API that evaluated the decision.
+	API string
+	// Query is the rego query, not set for Is decisions.
+	Query string
+	// InputDigest is the SHA-256 digest of the query input, not set for Is decisions.
+	InputDigest string
+	// Result summarizes the evaluation result, not set for Is decisions.
+	Result *Result
+	Caller Caller
+}
+
+// Result summarizes the result of a Query, DecisionTree or Compile evaluation, it is logged as JSON.
+type Result struct {
+	// Results is the number of query results, decision tree paths or partial queries.
+	Results int `json:"results"`
+	// Bindings lists the binding names of the query results.
+	Bindings []string `json:"bindings,omitempty"`
+	// Decisions counts, per decision, the decision tree paths where the decision is true.
+	Decisions map[string]int `json:"decisions,omitempty"`
+	// Support is the number of support modules of a partial evaluation.
+	Support int `json:"support,omitempty"`
+}
+
+// Caller identifies the client of an authorizer API call.
+type Caller struct {
+	Address   string
+	UserAgent string
+}
+
+// CallerFromContext returns the caller of the gRPC call, or of the gateway request it was forwarded from.
+func CallerFromContext(ctx context.Context) Caller {
+	caller := Caller{}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		caller.Address = p.Addr.String()
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return caller
+	}
+
+	if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+		// the first address of the list is the originating client.
+		caller.Address = strings.TrimSpace(strings.Split(forwarded[0], ",")[0])
+	}
+
+	if userAgent := md.Get("grpcgateway-user-agent"); len(userAgent) > 0 {
+		caller.UserAgent = userAgent[0]
+	} else if userAgent := md.Get("user-agent"); len(userAgent) > 0 {
+		caller.UserAgent = userAgent[0]
+	}
+
+	if host, _, err := net.SplitHostPort(caller.Address); err == nil {
+		caller.Address = host
+	}
+
+	return caller
+}
+
+// Annotations returns the evaluation record as decision annotations, unset fields are omitted.
+func (e *Evaluation) Annotations() map[string]string {
+	annotations := map[string]string{}
+
+	set := func(key, value string) {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+
+	set(AnnotationAPI, e.API)
+	set(AnnotationQuery, e.Query)
+	set(AnnotationInputDigest, e.InputDigest)
+	if e.Result != nil {
+		// the result holds no value json can't encode.
+		buf, _ := json.Marshal(e.Result)
+		set(AnnotationResult, string(buf))
+	}
+	set(AnnotationCallerAddress, e.Caller.Address)
+	set(AnnotationCallerUserAgent, e.Caller.UserAgent)
+
+	return annotations
+}
+
+// EvaluationFromAnnotations returns the evaluation record of a decision with the given annotations.
+func EvaluationFromAnnotations(annotations map[string]string) (*Evaluation, error) {
+	e := &Evaluation{
+		API:         DecisionAPI(annotations),
+		Query:       annotations[AnnotationQuery],
+		InputDigest: annotations[AnnotationInputDigest],
+		Caller: Caller{
+			Address:   annotations[AnnotationCallerAddress],
+			UserAgent: annotations[AnnotationCallerUserAgent],
+		},
+	}
+
+	if result := annotations[AnnotationResult]; result != "" {
+		e.Result = &Result{}
+		if err := json.Unmarshal([]byte(result), e.Result); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// DecisionAPI returns the API of a decision with the given annotations, decisions without API annotation are Is decisions.
+func DecisionAPI(annotations map[string]string) string {
+	if api := annotations[AnnotationAPI]; api != "" {
+		return api
+	}
+	return APIIs
+}
@@ -0,0 +1,44 @@
+package directory
+
+import "sync"
+
+type changeCallback struct {
+	fn func()
+}
+
+var changes struct {
+	mu        sync.RWMutex
+	callbacks []*changeCallback
+}
+
+// OnChange registers a function called whenever the edge directory is written or synced,
+// the returned function unregisters it.
+func OnChange(fn func()) func() {
+	changes.mu.Lock()
+	defer changes.mu.Unlock()
+
+	cb := &changeCallback{fn: fn}
+	changes.callbacks = append(changes.callbacks, cb)
+
+	return func() {
+		changes.mu.Lock()
+		defer changes.mu.Unlock()
+
+		for i, c := range changes.callbacks {
+			if c == cb {
+				changes.callbacks = append(changes.callbacks[:i:i], changes.callbacks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// NotifyChange calls the functions registered with OnChange, it is called after the edge directory is written or synced.
+func NotifyChange() {
+	changes.mu.RLock()
+	defer changes.mu.RUnlock()
+
+	for _, cb := range changes.callbacks {
+		cb.fn()
+	}
+}
@@ -13,11 +13,16 @@ import (
 )
 
 func GetIdentityV2(ctx context.Context, client dsr3.ReaderClient, identity string) (*dsc3.Object, error) {
+	return GetIdentitySubject(ctx, client, "identity", "identifier", "user", identity)
+}
+
+// GetIdentitySubject returns the subject of type subjectType of the relation of the identity object of type objectType.
+func GetIdentitySubject(ctx context.Context, client dsr3.ReaderClient, objectType, relation, subjectType, identity string) (*dsc3.Object, error) {
 	relResp, err := client.GetRelation(ctx, &dsr3.GetRelationRequest{
-		ObjectType:  "identity",
+		ObjectType:  objectType,
 		ObjectId:    identity,
-		Relation:    "identifier",
-		SubjectType: "user",
+		Relation:    relation,
+		SubjectType: subjectType,
 		WithObjects: true,
 	})
 
@@ -35,7 +40,7 @@ func GetIdentityV2(ctx context.Context, client dsr3.ReaderClient, identity strin
 	}
 
 	for k, v := range relResp.Objects {
-		if strings.HasPrefix(k, "user:") {
+		if strings.HasPrefix(k, subjectType+":") {
 			return v, nil
 		}
 	}
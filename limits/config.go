@@ -0,0 +1,101 @@
+package limits
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// Config holds the limits of the policy evaluations of the authorizer APIs.
+type Config struct {
+	// Limits of all APIs.
+	Default Set `json:"default"`
+	// Limits of a single API (is, query, decisiontree or compile), overriding the default limits they set.
+	APIs map[string]Set `json:"apis"`
+}
+
+// Set bounds a policy evaluation, unset (zero) limits are unbounded.
+type Set struct {
+	// Maximum duration of the evaluation.
+	TimeoutMS int `json:"timeout_ms"`
+	// Maximum number of directory builtin calls made by the evaluation.
+	MaxBuiltinCalls int `json:"max_builtin_calls"`
+	// Maximum size of the JSON encoded evaluation result.
+	MaxOutputBytes int `json:"max_output_bytes"`
+}
+
+func (cfg *Config) Validate() error {
+	if err := cfg.Default.validate(); err != nil {
+		return errors.Wrap(err, "default")
+	}
+
+	for api, set := range cfg.APIs {
+		if !lo.Contains(decisionlog.APIs, api) {
+			return errors.Errorf("apis - unknown api %q, must be one of %s", api, strings.Join(decisionlog.APIs, ", "))
+		}
+		if err := set.validate(); err != nil {
+			return errors.Wrapf(err, "apis.%s", api)
+		}
+	}
+
+	return nil
+}
+
+// For returns the limits of the API.
+func (cfg *Config) For(api string) Set {
+	set := cfg.Default
+
+	override := cfg.APIs[api]
+	if override.TimeoutMS != 0 {
+		set.TimeoutMS = override.TimeoutMS
+	}
+	if override.MaxBuiltinCalls != 0 {
+		set.MaxBuiltinCalls = override.MaxBuiltinCalls
+	}
+	if override.MaxOutputBytes != 0 {
+		set.MaxOutputBytes = override.MaxOutputBytes
+	}
+
+	return set
+}
+
+// MaxTimeout returns the longest evaluation timeout of the APIs, 0 when the evaluations are unbounded by default.
+func (cfg *Config) MaxTimeout() time.Duration {
+	if cfg.Default.TimeoutMS == 0 {
+		return 0
+	}
+
+	timeout := cfg.Default.Timeout()
+	for _, set := range cfg.APIs {
+		timeout = max(timeout, set.Timeout())
+	}
+
+	return timeout
+}
+
+// Timeout returns the maximum duration of the evaluation, 0 when unbounded.
+func (s Set) Timeout() time.Duration {
+	return time.Duration(s.TimeoutMS) * time.Millisecond
+}
+
+func (s Set) validate() error {
+	if s.TimeoutMS < 0 {
+		return errors.New("timeout_ms must be positive or 0")
+	}
+	if s.MaxBuiltinCalls < 0 {
+		return errors.New("max_builtin_calls must be positive or 0")
+	}
+	if s.MaxOutputBytes < 0 {
+		return errors.New("max_output_bytes must be positive or 0")
+	}
+	return nil
+}
+
+// Start starts a policy evaluation of the API bounded by the API limits, see Set.Start.
+func (cfg *Config) Start(ctx context.Context, api string) (context.Context, *Evaluation) {
+	return cfg.For(api).Start(ctx, api)
+}
@@ -0,0 +1,172 @@
+package limits
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/pkg/errors"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc/codes"
+)
+
+// The limit errors are allocated in the E40000 range owned by topaz, the E30000 range belongs to the go-authorizer errors.
+var (
+	// ErrTimeout is returned when a policy evaluation exceeds its timeout.
+	ErrTimeout = cerr.NewAsertoError("E40001", codes.DeadlineExceeded, http.StatusGatewayTimeout, "policy evaluation timeout")
+	// ErrBuiltinCalls is returned when a policy evaluation exceeds its maximum number of builtin calls.
+	ErrBuiltinCalls = cerr.NewAsertoError("E40002", codes.ResourceExhausted, http.StatusUnprocessableEntity, "too many builtin calls")
+	// ErrOutputSize is returned when the result of a policy evaluation exceeds its maximum size.
+	ErrOutputSize = cerr.NewAsertoError("E40003", codes.ResourceExhausted, http.StatusUnprocessableEntity, "evaluation result too large")
+)
+
+// Limits reported in the violation metrics.
+const (
+	LimitTimeout      = "timeout"
+	LimitBuiltinCalls = "builtin_calls"
+	LimitOutputSize   = "output_size"
+)
+
+var (
+	errTimeout      = errors.New("evaluation timeout")
+	errBuiltinCalls = errors.New("too many builtin calls")
+
+	keyAPI   = tag.MustNewKey("api")
+	keyLimit = tag.MustNewKey("limit")
+
+	measureViolations = stats.Int64("topaz/authorizer/limit_violations", "Number of policy evaluations that exceeded a limit", stats.UnitDimensionless)
+
+	// Views exports the limit violation counters, by API and limit.
+	Views = []*view.View{
+		{
+			Name:        "topaz/authorizer/limit_violations",
+			Measure:     measureViolations,
+			Description: measureViolations.Description(),
+			TagKeys:     []tag.Key{keyAPI, keyLimit},
+			Aggregation: view.Sum(),
+		},
+	}
+)
+
+type evaluationKey struct{}
+
+// Evaluation enforces the limits of a policy evaluation.
+type Evaluation struct {
+	api    string
+	limits Set
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	stop   context.CancelFunc
+	calls  atomic.Int64
+}
+
+// Start returns the context of a policy evaluation of the API, bounded by the limits.
+// The evaluation is canceled when it times out or exceeds its builtin calls, End releases its resources.
+func (s Set) Start(ctx context.Context, api string) (context.Context, *Evaluation) {
+	e := &Evaluation{api: api, limits: s, stop: func() {}}
+
+	ctx, e.cancel = context.WithCancelCause(ctx)
+	if timeout := s.Timeout(); timeout > 0 {
+		ctx, e.stop = context.WithTimeoutCause(ctx, timeout, errTimeout)
+	}
+
+	e.ctx = context.WithValue(ctx, evaluationKey{}, e)
+
+	return e.ctx, e
+}
+
+// End releases the resources of the evaluation.
+func (e *Evaluation) End() {
+	e.stop()
+	e.cancel(nil)
+}
+
+// Violation returns the error of the limit exceeded by the evaluation, nil when the evaluation is within its limits.
+func (e *Evaluation) Violation() error {
+	switch context.Cause(e.ctx) {
+	case errTimeout:
+		e.record(LimitTimeout)
+		return ErrTimeout.Msgf("%s evaluation exceeded %s", e.api, e.limits.Timeout())
+	case errBuiltinCalls:
+		e.record(LimitBuiltinCalls)
+		return ErrBuiltinCalls.Msgf("%s evaluation exceeded %d builtin calls", e.api, e.limits.MaxBuiltinCalls)
+	default:
+		return nil
+	}
+}
+
+// CheckOutput returns an error when the JSON encoding of the evaluation result exceeds the maximum output size.
+func (e *Evaluation) CheckOutput(output interface{}) error {
+	if e.limits.MaxOutputBytes == 0 {
+		return nil
+	}
+
+	buf, err := json.Marshal(output)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal evaluation result")
+	}
+
+	if len(buf) > e.limits.MaxOutputBytes {
+		e.record(LimitOutputSize)
+		return ErrOutputSize.Msgf("%s evaluation result of %d bytes exceeded %d bytes", e.api, len(buf), e.limits.MaxOutputBytes)
+	}
+
+	return nil
+}
+
+// call counts a builtin call, the evaluation is canceled when it exceeds its maximum number of builtin calls.
+func (e *Evaluation) call() error {
+	if e.limits.MaxBuiltinCalls == 0 {
+		return nil
+	}
+
+	if e.calls.Add(1) > int64(e.limits.MaxBuiltinCalls) {
+		e.cancel(errBuiltinCalls)
+		return errBuiltinCalls
+	}
+
+	return nil
+}
+
+func (e *Evaluation) record(limit string) {
+	_ = stats.RecordWithTags(e.ctx,
+		[]tag.Mutator{tag.Upsert(keyAPI, e.api), tag.Upsert(keyLimit, limit)},
+		measureViolations.M(1),
+	)
+}
+
+// Count counts additional builtin calls against the limits of the evaluation of the context,
+// for builtins making several directory calls.
+func Count(ctx context.Context, calls int) error {
+	e, ok := ctx.Value(evaluationKey{}).(*Evaluation)
+	if !ok {
+		return nil
+	}
+
+	for i := 0; i < calls; i++ {
+		if err := e.call(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Builtin1 counts the calls of the builtin against the limits of the evaluation calling it.
+func Builtin1(fn *rego.Function, impl rego.Builtin1) (*rego.Function, rego.Builtin1) {
+	return fn, func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		if e, ok := bctx.Context.Value(evaluationKey{}).(*Evaluation); ok {
+			if err := e.call(); err != nil {
+				return nil, err
+			}
+		}
+
+		return impl(bctx, op1)
+	}
+}
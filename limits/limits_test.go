@@ -0,0 +1,124 @@
+package limits_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/aserto-dev/topaz/limits"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counted is a builtin whose calls count against the limits of the evaluation.
+func counted() func(*rego.Rego) {
+	return rego.Function1(limits.Builtin1(
+		&rego.Function{Name: "test.counted", Decl: types.NewFunction(types.Args(types.N), types.N)},
+		func(_ rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+			return op1, nil
+		},
+	))
+}
+
+func eval(ctx context.Context, query string) (rego.ResultSet, error) {
+	return rego.New(rego.Query(query), counted()).Eval(ctx)
+}
+
+func TestTimeout(t *testing.T) {
+	set := limits.Set{TimeoutMS: 10}
+
+	ctx, e := set.Start(context.Background(), "query")
+	defer e.End()
+
+	// the evaluation runs until it is canceled.
+	_, err := eval(ctx, "x := count([y | numbers.range(1, 100000000)[y]])")
+	require.Error(t, err)
+
+	violation := e.Violation()
+	require.Error(t, violation)
+	assert.True(t, cerr.Equals(limits.ErrTimeout, violation), violation.Error())
+	assert.Contains(t, violation.Error(), "10ms")
+}
+
+func TestBuiltinCalls(t *testing.T) {
+	set := limits.Set{MaxBuiltinCalls: 2}
+
+	ctx, e := set.Start(context.Background(), "is")
+	_, err := eval(ctx, "x := test.counted(1); y := test.counted(2)")
+	require.NoError(t, err)
+	assert.NoError(t, e.Violation(), "evaluation within its limits")
+	e.End()
+
+	ctx, e = set.Start(context.Background(), "is")
+	defer e.End()
+
+	// builtin errors are undefined results, unless builtin errors are strict.
+	rs, err := eval(ctx, "x := test.counted(1); y := test.counted(2); z := test.counted(3)")
+	assert.True(t, err != nil || len(rs) == 0, "evaluation exceeding its builtin calls succeeded")
+
+	violation := e.Violation()
+	require.Error(t, violation)
+	assert.True(t, cerr.Equals(limits.ErrBuiltinCalls, violation), violation.Error())
+}
+
+func TestCount(t *testing.T) {
+	set := limits.Set{MaxBuiltinCalls: 3}
+
+	ctx, e := set.Start(context.Background(), "is")
+	defer e.End()
+
+	require.NoError(t, limits.Count(ctx, 3))
+	require.Error(t, limits.Count(ctx, 1))
+	assert.True(t, cerr.Equals(limits.ErrBuiltinCalls, e.Violation()))
+
+	// calls outside of an evaluation are not counted.
+	assert.NoError(t, limits.Count(context.Background(), 100))
+}
+
+func TestUnbounded(t *testing.T) {
+	ctx, e := limits.Set{}.Start(context.Background(), "is")
+	defer e.End()
+
+	require.NoError(t, limits.Count(ctx, 10000))
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+	assert.NoError(t, e.CheckOutput(strings.Repeat("x", 1<<20)))
+	assert.NoError(t, e.Violation())
+}
+
+func TestOutputSize(t *testing.T) {
+	set := limits.Set{MaxOutputBytes: 16}
+
+	_, e := set.Start(context.Background(), "query")
+	defer e.End()
+
+	// the JSON encoding of the string adds its quotes.
+	assert.NoError(t, e.CheckOutput(strings.Repeat("x", 14)))
+
+	err := e.CheckOutput(strings.Repeat("x", 15))
+	require.Error(t, err)
+	assert.True(t, cerr.Equals(limits.ErrOutputSize, err), err.Error())
+}
+
+func TestConfig(t *testing.T) {
+	cfg := &limits.Config{
+		Default: limits.Set{TimeoutMS: 100, MaxBuiltinCalls: 10},
+		APIs:    map[string]limits.Set{"query": {TimeoutMS: 1000, MaxOutputBytes: 64}},
+	}
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, limits.Set{TimeoutMS: 1000, MaxBuiltinCalls: 10, MaxOutputBytes: 64}, cfg.For("query"))
+	assert.Equal(t, limits.Set{TimeoutMS: 100, MaxBuiltinCalls: 10}, cfg.For("is"))
+	assert.Equal(t, time.Second, cfg.For("query").Timeout())
+	assert.Equal(t, time.Second, cfg.MaxTimeout())
+	assert.Zero(t, (&limits.Config{APIs: map[string]limits.Set{"query": {TimeoutMS: 1000}}}).MaxTimeout())
+
+	assert.Error(t, (&limits.Config{APIs: map[string]limits.Set{"unknown": {}}}).Validate())
+	assert.Error(t, (&limits.Config{Default: limits.Set{TimeoutMS: -1}}).Validate())
+	assert.Error(t, (&limits.Config{APIs: map[string]limits.Set{"is": {MaxOutputBytes: -1}}}).Validate())
+}
@@ -9,6 +9,10 @@ import (
 	authz "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
 	azOpenAPI "github.com/aserto-dev/openapi-authorizer/publish/authorizer"
 	builder "github.com/aserto-dev/service-host"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
+	"github.com/aserto-dev/topaz/decision_log/logger/async"
+	"github.com/aserto-dev/topaz/limits"
+	"github.com/aserto-dev/topaz/pkg/app/directory"
 	"github.com/aserto-dev/topaz/pkg/app/impl"
 	"github.com/aserto-dev/topaz/pkg/cc/config"
 	"github.com/aserto-dev/topaz/pkg/rapidoc"
@@ -47,11 +51,29 @@ func NewAuthorizer(ctx context.Context, cfg *builder.API, commonConfig *config.C
 	if err := view.Register(ocgrpc.DefaultServerViews...); err != nil {
 		return nil, err
 	}
+	if err := view.Register(limits.Views...); err != nil {
+		return nil, err
+	}
+	if err := view.Register(impl.ShadowViews...); err != nil {
+		return nil, err
+	}
+	if err := view.Register(impl.IdentityCacheViews...); err != nil {
+		return nil, err
+	}
+	if err := view.Register(directory.CacheViews...); err != nil {
+		return nil, err
+	}
+	if err := view.Register(async.Views...); err != nil {
+		return nil, err
+	}
 	authorizerOpts = append(authorizerOpts, grpc.StatsHandler(&ocgrpc.ServerHandler{}))
 
 	authResolvers := resolvers.New()
 
-	authServer := impl.NewAuthorizerServer(ctx, logger, commonConfig, authResolvers)
+	authServer, err := impl.NewAuthorizerServer(ctx, logger, commonConfig, authResolvers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create authorizer server")
+	}
 
 	return &Authorizer{
 		cfg:              cfg,
@@ -68,6 +90,7 @@ func (e *Authorizer) AvailableServices() []string {
 func (e *Authorizer) GetGRPCRegistrations(services ...string) builder.GRPCRegistrations {
 	return func(server *grpc.Server) {
 		authz.RegisterAuthorizerServer(server, e.AuthorizerServer)
+		azv1.RegisterAuthorizerServer(server, e.AuthorizerServer)
 	}
 }
 
@@ -77,6 +100,10 @@ func (e *Authorizer) GetGatewayRegistration(services ...string) builder.HandlerR
 			return err
 		}
 
+		if err := azv1.RegisterAuthorizerHandlerFromEndpoint(ctx, mux, grpcEndpoint, opts); err != nil {
+			return err
+		}
+
 		if len(services) > 0 {
 			if err := mux.HandlePath(http.MethodGet, authorizerOpenAPISpec, azOpenAPIHandler); err != nil {
 				return err
@@ -91,7 +118,7 @@ func (e *Authorizer) GetGatewayRegistration(services ...string) builder.HandlerR
 }
 
 func (e *Authorizer) Cleanups() []func() {
-	return nil
+	return []func(){e.AuthorizerServer.Close}
 }
 
 const (
@@ -0,0 +1,244 @@
+package directory
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	dsr3 "github.com/aserto-dev/go-directory/aserto/directory/reader/v3"
+	topazdir "github.com/aserto-dev/topaz/directory"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/resolvers"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Results of directory cache lookups reported in the directory cache metrics.
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+var (
+	keyCacheBuiltin = tag.MustNewKey("builtin")
+	keyCacheResult  = tag.MustNewKey("result")
+
+	measureCacheLookups = stats.Int64("topaz/authorizer/directory_cache_lookups", "Number of directory cache lookups", stats.UnitDimensionless)
+
+	// CacheViews exports the directory cache lookup counters, by calling builtin and result (hit or miss).
+	CacheViews = []*view.View{
+		{
+			Name:        "topaz/authorizer/directory_cache_lookups",
+			Measure:     measureCacheLookups,
+			Description: measureCacheLookups.Description(),
+			TagKeys:     []tag.Key{keyCacheBuiltin, keyCacheResult},
+			Aggregation: view.Sum(),
+		},
+	}
+)
+
+// CachedResolver is a directory resolver whose reader clients cache the results of the Check, GetObject, GetRelation
+// and GetGraph calls of the ds builtins. The results are shared by all policy evaluations and dropped when the edge directory changes.
+type CachedResolver struct {
+	resolver resolvers.DirectoryResolver
+	cache    *resultCache
+}
+
+var _ resolvers.DirectoryResolver = &CachedResolver{}
+
+// NewCachedResolver returns the resolver caching the results of the directory resolver,
+// the directory resolver itself when the cache is disabled.
+func NewCachedResolver(resolver resolvers.DirectoryResolver, cfg *config.DirectoryCacheConfig) resolvers.DirectoryResolver {
+	if !cfg.Enabled() || resolver == nil {
+		return resolver
+	}
+
+	maxSize := cfg.MaxSize
+	if maxSize == 0 {
+		maxSize = config.DefaultDirectoryCacheSize
+	}
+
+	cache := &resultCache{
+		ttl:     time.Duration(cfg.TTLSeconds) * time.Second,
+		maxSize: maxSize,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+	topazdir.OnChange(cache.invalidate)
+
+	return &CachedResolver{
+		resolver: resolver,
+		cache:    cache,
+	}
+}
+
+// GetDS returns the caching reader client of the directory reader client. Its cache lookups are reported
+// for the builtin of the context, or for the reader method when the client is not requested by a builtin.
+func (r *CachedResolver) GetDS(ctx context.Context) (dsr3.ReaderClient, error) {
+	client, err := r.resolver.GetDS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cachedReader{ReaderClient: client, cache: r.cache, builtin: resolvers.BuiltinFromContext(ctx)}, nil
+}
+
+type cachedReader struct {
+	dsr3.ReaderClient
+	cache   *resultCache
+	builtin string
+}
+
+func (c *cachedReader) Check(ctx context.Context, in *dsr3.CheckRequest, opts ...grpc.CallOption) (*dsr3.CheckResponse, error) {
+	return cached(ctx, c, "Check", in, func() (*dsr3.CheckResponse, error) {
+		return c.ReaderClient.Check(ctx, in, opts...)
+	})
+}
+
+func (c *cachedReader) GetObject(ctx context.Context, in *dsr3.GetObjectRequest, opts ...grpc.CallOption) (*dsr3.GetObjectResponse, error) {
+	return cached(ctx, c, "GetObject", in, func() (*dsr3.GetObjectResponse, error) {
+		return c.ReaderClient.GetObject(ctx, in, opts...)
+	})
+}
+
+func (c *cachedReader) GetRelation(ctx context.Context, in *dsr3.GetRelationRequest, opts ...grpc.CallOption) (*dsr3.GetRelationResponse, error) {
+	return cached(ctx, c, "GetRelation", in, func() (*dsr3.GetRelationResponse, error) {
+		return c.ReaderClient.GetRelation(ctx, in, opts...)
+	})
+}
+
+func (c *cachedReader) GetGraph(ctx context.Context, in *dsr3.GetGraphRequest, opts ...grpc.CallOption) (*dsr3.GetGraphResponse, error) {
+	return cached(ctx, c, "GetGraph", in, func() (*dsr3.GetGraphResponse, error) {
+		return c.ReaderClient.GetGraph(ctx, in, opts...)
+	})
+}
+
+// cached returns the cached response of the request, calling the directory and caching its response on a miss.
+// Requests are keyed on the reader method and their deterministic encoding, so that builtins making the same
+// directory calls share their results. Traced requests and errors are not cached.
+func cached[T proto.Message](ctx context.Context, r *cachedReader, method string, req proto.Message, call func() (T, error)) (T, error) {
+	if traced, ok := req.(interface{ GetTrace() bool }); ok && traced.GetTrace() {
+		return call()
+	}
+
+	buf, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return call()
+	}
+	key := method + ":" + string(buf)
+
+	builtin := r.builtin
+	if builtin == "" {
+		builtin = method
+	}
+
+	c := r.cache
+
+	if resp, ok := c.get(key); ok {
+		recordCache(ctx, builtin, CacheHit)
+		return proto.Clone(resp).(T), nil
+	}
+
+	recordCache(ctx, builtin, CacheMiss)
+
+	generation := c.currentGeneration()
+
+	resp, err := call()
+	if err != nil {
+		return resp, err
+	}
+
+	c.put(generation, key, proto.Clone(resp))
+
+	return resp, nil
+}
+
+// resultCache is a least recently used cache of directory responses, dropped when the edge directory changes.
+// A response requested before the cache is dropped is not cached.
+type resultCache struct {
+	ttl     time.Duration
+	maxSize int
+
+	mu         sync.Mutex
+	generation uint64
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type resultEntry struct {
+	key     string
+	resp    proto.Message
+	expires time.Time
+}
+
+func (c *resultCache) get(key string) (proto.Message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*resultEntry)
+	if time.Now().After(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+
+	return entry.resp, true
+}
+
+func (c *resultCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+func (c *resultCache) put(generation uint64, key string, resp proto.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the cache was dropped while the directory was called.
+	if generation != c.generation {
+		return
+	}
+
+	entry := &resultEntry{key: key, resp: resp, expires: time.Now().Add(c.ttl)}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	for c.lru.Len() >= c.maxSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resultEntry).key)
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+}
+
+// invalidate drops all cached responses.
+func (c *resultCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+func recordCache(ctx context.Context, builtin, result string) {
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(keyCacheBuiltin, builtin), tag.Upsert(keyCacheResult, result)}, measureCacheLookups.M(1))
+}
@@ -0,0 +1,233 @@
+package directory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	dsr3 "github.com/aserto-dev/go-directory/aserto/directory/reader/v3"
+	topazdir "github.com/aserto-dev/topaz/directory"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"google.golang.org/grpc"
+)
+
+// testReader allows the checks of alice and fails the checks of the subject "error", it counts its calls.
+type testReader struct {
+	dsr3.ReaderClient
+
+	mu    sync.Mutex
+	calls int
+	// called is called during each call, before its response.
+	called func()
+}
+
+func (r *testReader) Check(ctx context.Context, in *dsr3.CheckRequest, _ ...grpc.CallOption) (*dsr3.CheckResponse, error) {
+	r.mu.Lock()
+	r.calls++
+	called := r.called
+	r.mu.Unlock()
+
+	if called != nil {
+		called()
+	}
+
+	if in.SubjectId == "error" {
+		return nil, errors.New("directory unavailable")
+	}
+
+	return &dsr3.CheckResponse{Check: in.SubjectId == "alice"}, nil
+}
+
+func (r *testReader) GetObject(ctx context.Context, in *dsr3.GetObjectRequest, _ ...grpc.CallOption) (*dsr3.GetObjectResponse, error) {
+	r.mu.Lock()
+	r.calls++
+	r.mu.Unlock()
+
+	return &dsr3.GetObjectResponse{}, nil
+}
+
+func (r *testReader) GetDS(context.Context) (dsr3.ReaderClient, error) {
+	return r, nil
+}
+
+func newTestCachedResolver(t *testing.T, cfg *config.DirectoryCacheConfig) (*CachedResolver, *testReader) {
+	reader := &testReader{}
+
+	resolver, ok := NewCachedResolver(reader, cfg).(*CachedResolver)
+	require.True(t, ok, "cache disabled")
+
+	return resolver, reader
+}
+
+func check(subject string) *dsr3.CheckRequest {
+	return &dsr3.CheckRequest{ObjectType: "group", ObjectId: "admin", Relation: "member", SubjectType: "user", SubjectId: subject}
+}
+
+func checkWith(t *testing.T, ctx context.Context, r *CachedResolver, req *dsr3.CheckRequest) bool {
+	client, err := r.GetDS(ctx)
+	require.NoError(t, err)
+
+	resp, err := client.Check(ctx, req)
+	require.NoError(t, err)
+
+	return resp.Check
+}
+
+func TestCachedResolverDisabled(t *testing.T) {
+	reader := &testReader{}
+	assert.Same(t, reader, NewCachedResolver(reader, &config.DirectoryCacheConfig{}))
+	assert.Nil(t, NewCachedResolver(nil, &config.DirectoryCacheConfig{TTLSeconds: 60}))
+}
+
+func TestCachedResolverKeys(t *testing.T) {
+	r, reader := newTestCachedResolver(t, &config.DirectoryCacheConfig{TTLSeconds: 60})
+	ctx := context.Background()
+
+	assert.True(t, checkWith(t, ctx, r, check("alice")))
+	assert.True(t, checkWith(t, ctx, r, check("alice")))
+	assert.Equal(t, 1, reader.calls)
+
+	// the requests differing in a field are cached apart.
+	assert.False(t, checkWith(t, ctx, r, check("bob")))
+	assert.Equal(t, 2, reader.calls)
+
+	// the builtins making the same directory calls share their results.
+	assert.True(t, checkWith(t, resolvers.WithBuiltin(ctx, "ds.checks"), r, check("alice")))
+	assert.Equal(t, 2, reader.calls)
+
+	// the reader methods are cached apart.
+	client, err := r.GetDS(ctx)
+	require.NoError(t, err)
+	_, err = client.GetObject(ctx, &dsr3.GetObjectRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, reader.calls)
+
+	// traced requests and errors are not cached.
+	traced := check("alice")
+	traced.Trace = true
+	for i := 0; i < 2; i++ {
+		_, err := client.Check(ctx, traced)
+		require.NoError(t, err)
+		_, err = client.Check(ctx, check("error"))
+		require.Error(t, err)
+	}
+	assert.Equal(t, 7, reader.calls)
+}
+
+func TestCachedResolverCopies(t *testing.T) {
+	r, _ := newTestCachedResolver(t, &config.DirectoryCacheConfig{TTLSeconds: 60})
+	ctx := context.Background()
+
+	client, err := r.GetDS(ctx)
+	require.NoError(t, err)
+
+	// the responses returned are not the cached ones.
+	first, err := client.Check(ctx, check("alice"))
+	require.NoError(t, err)
+	first.Check = false
+
+	second, err := client.Check(ctx, check("alice"))
+	require.NoError(t, err)
+	assert.True(t, second.Check)
+	second.Check = false
+
+	assert.True(t, checkWith(t, ctx, r, check("alice")))
+}
+
+func TestCachedResolverExpiry(t *testing.T) {
+	r, reader := newTestCachedResolver(t, &config.DirectoryCacheConfig{TTLSeconds: 60})
+	r.cache.ttl = 10 * time.Millisecond
+	ctx := context.Background()
+
+	checkWith(t, ctx, r, check("alice"))
+	checkWith(t, ctx, r, check("alice"))
+	assert.Equal(t, 1, reader.calls)
+
+	time.Sleep(20 * time.Millisecond)
+
+	checkWith(t, ctx, r, check("alice"))
+	assert.Equal(t, 2, reader.calls)
+}
+
+func TestCachedResolverInvalidate(t *testing.T) {
+	r, reader := newTestCachedResolver(t, &config.DirectoryCacheConfig{TTLSeconds: 60})
+	ctx := context.Background()
+
+	checkWith(t, ctx, r, check("alice"))
+
+	// the cache is dropped when the edge directory changes.
+	topazdir.NotifyChange()
+
+	checkWith(t, ctx, r, check("alice"))
+	assert.Equal(t, 2, reader.calls)
+	checkWith(t, ctx, r, check("alice"))
+	assert.Equal(t, 2, reader.calls)
+
+	// a response requested before the cache is dropped is not cached.
+	reader.called = r.cache.invalidate
+	checkWith(t, ctx, r, check("bob"))
+	reader.called = nil
+
+	checkWith(t, ctx, r, check("bob"))
+	assert.Equal(t, 4, reader.calls)
+	checkWith(t, ctx, r, check("bob"))
+	assert.Equal(t, 4, reader.calls)
+}
+
+func TestCachedResolverEviction(t *testing.T) {
+	r, reader := newTestCachedResolver(t, &config.DirectoryCacheConfig{TTLSeconds: 60, MaxSize: 2})
+	ctx := context.Background()
+
+	for _, subject := range []string{"alice", "bob", "alice", "carol"} {
+		checkWith(t, ctx, r, check(subject))
+	}
+	assert.Equal(t, 3, reader.calls)
+
+	// bob is the least recently used result, evicted for carol.
+	checkWith(t, ctx, r, check("alice"))
+	assert.Equal(t, 3, reader.calls)
+
+	checkWith(t, ctx, r, check("bob"))
+	assert.Equal(t, 4, reader.calls)
+
+	assert.Equal(t, 2, r.cache.lru.Len())
+	assert.Len(t, r.cache.entries, 2)
+}
+
+func TestCachedResolverMetrics(t *testing.T) {
+	require.NoError(t, view.Register(CacheViews...))
+	t.Cleanup(func() { view.Unregister(CacheViews...) })
+
+	r, _ := newTestCachedResolver(t, &config.DirectoryCacheConfig{TTLSeconds: 60})
+	ctx := context.Background()
+
+	checkWith(t, resolvers.WithBuiltin(ctx, "ds.checks"), r, check("alice"))
+	checkWith(t, resolvers.WithBuiltin(ctx, "ds.check"), r, check("alice"))
+	checkWith(t, resolvers.WithBuiltin(ctx, "ds.check"), r, check("alice"))
+	checkWith(t, ctx, r, check("alice"))
+
+	rows, err := view.RetrieveData("topaz/authorizer/directory_cache_lookups")
+	require.NoError(t, err)
+
+	lookups := map[string]float64{}
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tag := range row.Tags {
+			tags[tag.Key.Name()] = tag.Value
+		}
+		lookups[tags["builtin"]+" "+tags["result"]] = row.Data.(*view.SumData).Value
+	}
+
+	// the lookups are reported for the calling builtin, or for the reader method outside builtins.
+	assert.Equal(t, map[string]float64{
+		"ds.checks miss": 1,
+		"ds.check hit":   2,
+		"Check hit":      1,
+	}, lookups)
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+
+	builder "github.com/aserto-dev/service-host"
+	"github.com/aserto-dev/topaz/pkg/app/extauthz"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+)
+
+// ExtAuthz serves the Envoy external authorization API, backed by the authorizer service.
+type ExtAuthz struct {
+	server *extauthz.Server
+}
+
+const (
+	extAuthzService = "ext_authz"
+)
+
+func NewExtAuthz(cfg *config.ExtAuthzConfig, authorizer *Authorizer, logger *zerolog.Logger) (ServiceTypes, error) {
+	server, err := extauthz.NewServer(cfg, authorizer.AuthorizerServer, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create ext_authz server")
+	}
+
+	return &ExtAuthz{server: server}, nil
+}
+
+func (e *ExtAuthz) AvailableServices() []string {
+	return []string{extAuthzService}
+}
+
+func (e *ExtAuthz) GetGRPCRegistrations(services ...string) builder.GRPCRegistrations {
+	return func(server *grpc.Server) {
+		authv3.RegisterAuthorizationServer(server, e.server)
+	}
+}
+
+// Envoy calls the external authorization API over gRPC only.
+func (e *ExtAuthz) GetGatewayRegistration(services ...string) builder.HandlerRegistrations {
+	return func(ctx context.Context, mux *runtime.ServeMux, grpcEndpoint string, opts []grpc.DialOption) error {
+		return nil
+	}
+}
+
+func (e *ExtAuthz) Cleanups() []func() {
+	return nil
+}
@@ -0,0 +1,144 @@
+package extauthz
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// segment is a segment of a path template, either a literal or a {param}.
+type segment struct {
+	value string
+	param bool
+}
+
+// route is a compiled Route.
+type route struct {
+	methods    []string
+	segments   []segment
+	policyPath string
+}
+
+func parseTemplate(path string) ([]segment, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.Errorf("%q must start with /", path)
+	}
+
+	parts, err := splitPath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%q", path)
+	}
+
+	segments := []segment{}
+	for _, s := range parts {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			name := s[1 : len(s)-1]
+			if name == "" {
+				return nil, errors.Errorf("%q has an unnamed parameter", path)
+			}
+			segments = append(segments, segment{value: name, param: true})
+			continue
+		}
+
+		if strings.ContainsAny(s, "{}") {
+			return nil, errors.Errorf("%q has an invalid segment %q", path, s)
+		}
+		segments = append(segments, segment{value: s})
+	}
+
+	return segments, nil
+}
+
+func newRoute(r *config.ExtAuthzRoute) (*route, error) {
+	segments, err := parseTemplate(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &route{
+		methods:    r.Methods,
+		segments:   segments,
+		policyPath: r.PolicyPath,
+	}, nil
+}
+
+// match returns the path parameters of the request when the route matches it.
+func (r *route) match(method string, path []string) (map[string]string, bool) {
+	if len(r.methods) > 0 && !lo.Contains(r.methods, method) {
+		return nil, false
+	}
+
+	if len(path) != len(r.segments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, s := range r.segments {
+		switch {
+		case s.param:
+			params[s.value] = path[i]
+		case s.value != path[i]:
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// policy returns the policy path of the request method, the configured policy path
+// or <root>.<METHOD>.<path segments> where parameters are named __<param>.
+func (r *route) policy(root, method string) string {
+	if r.policyPath != "" {
+		return r.policyPath
+	}
+
+	parts := []string{root, method}
+	for _, s := range r.segments {
+		if s.param {
+			parts = append(parts, "__"+s.value)
+		} else {
+			parts = append(parts, s.value)
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// splitPath returns the unescaped segments of the request path, without its query string.
+//
+// Paths with empty, dot or dot-dot segments and segments holding an encoded separator are rejected, rather than
+// canonicalized: the upstream server would resolve them to another path than the one matched by the routes.
+func splitPath(path string) ([]string, error) {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/")
+	if path == "" {
+		return nil, nil
+	}
+
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		unescaped, err := url.PathUnescape(s)
+		if err != nil {
+			return nil, errors.Errorf("invalid path segment %q", s)
+		}
+
+		switch {
+		case unescaped == "":
+			return nil, errors.New("empty path segment")
+		case unescaped == "." || unescaped == "..":
+			return nil, errors.Errorf("dot path segment %q", s)
+		case strings.ContainsAny(unescaped, `/\`):
+			return nil, errors.Errorf("path segment %q holds a separator", s)
+		}
+
+		segments[i] = unescaped
+	}
+
+	return segments, nil
+}
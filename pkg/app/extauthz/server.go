@@ -0,0 +1,191 @@
+package extauthz
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/topaz/pkg/app/impl"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const bearerPrefix = "bearer "
+
+// Authorizer evaluates the Is requests of the checked HTTP requests.
+type Authorizer interface {
+	Is(ctx context.Context, req *authorizer.IsRequest) (*authorizer.IsResponse, error)
+}
+
+// Server implements the Envoy external authorization service, it checks HTTP requests with Is requests.
+//
+// The Is requests are evaluated in-process and do not pass through the gRPC middleware of the authorizer service:
+// the policy instance middleware does not attach the discovery policy instance, so requests are evaluated by the
+// default runtime, and the mTLS identity middleware does not apply, the client certificate of the caller is not an identity.
+type Server struct {
+	cfg        *config.ExtAuthzConfig
+	routes     []*route
+	authorizer Authorizer
+	logger     *zerolog.Logger
+}
+
+var _ authv3.AuthorizationServer = (*Server)(nil)
+
+func NewServer(cfg *config.ExtAuthzConfig, authorizer Authorizer, logger *zerolog.Logger) (*Server, error) {
+	routes := make([]*route, len(cfg.Routes))
+	for i := range cfg.Routes {
+		r, err := newRoute(&cfg.Routes[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "routes[%d].path", i)
+		}
+		routes[i] = r
+	}
+
+	newLogger := logger.With().Str("component", "api.ext_authz").Logger()
+
+	return &Server{
+		cfg:        cfg,
+		routes:     routes,
+		authorizer: authorizer,
+		logger:     &newLogger,
+	}, nil
+}
+
+// Check allows the HTTP request checked by Envoy when it is authorized, see Authorize.
+func (s *Server) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	httpReq := req.GetAttributes().GetRequest().GetHttp()
+
+	st := s.Authorize(ctx, httpReq.GetMethod(), httpReq.GetPath(), httpReq.GetHeaders())
+	if codes.Code(st.Code) != codes.OK {
+		return s.denied(st), nil
+	}
+
+	return s.allowed(), nil
+}
+
+// Authorize maps the HTTP request to an Is request of the first matching route, the request is allowed (OK status)
+// when the decision is true. Unmatched requests, requests with an invalid path (see splitPath) and requests whose
+// evaluation fails are denied, so that failures such as invalid tokens are not subject to the failure mode of the caller.
+// Header names must be in lower case.
+func (s *Server) Authorize(ctx context.Context, method, path string, headers map[string]string) *status.Status {
+	method = strings.ToUpper(method)
+
+	log := s.logger.With().Str("method", method).Str("path", path).Logger()
+
+	segments, err := splitPath(path)
+	if err != nil {
+		log.Debug().Err(err).Msg("invalid request path")
+		return &status.Status{Code: int32(codes.InvalidArgument), Message: "invalid request path"}
+	}
+
+	for _, r := range s.routes {
+		params, ok := r.match(method, segments)
+		if !ok {
+			continue
+		}
+
+		isReq, err := s.isRequest(r.policy(s.cfg.PolicyRoot, method), params, headers)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to map request")
+			return impl.ErrorStatus(err).Proto()
+		}
+
+		resp, err := s.authorizer.Is(ctx, isReq)
+		if err != nil {
+			log.Error().Err(err).Str("policy_path", isReq.PolicyContext.Path).Msg("failed to evaluate request")
+			return impl.ErrorStatus(err).Proto()
+		}
+
+		for _, d := range resp.Decisions {
+			if d.Decision == s.cfg.Decision && d.Is {
+				return &status.Status{Code: int32(codes.OK)}
+			}
+		}
+
+		log.Debug().Str("policy_path", isReq.PolicyContext.Path).Msg("request denied")
+
+		return &status.Status{Code: int32(codes.PermissionDenied), Message: "request denied"}
+	}
+
+	log.Debug().Msg("no route matches the request")
+
+	return &status.Status{Code: int32(codes.PermissionDenied), Message: "no route matches the request"}
+}
+
+// isRequest returns the Is request of the policy path, its resource context holds the path parameters and the mapped headers,
+// its identity is the bearer token of the authorization header.
+func (s *Server) isRequest(policyPath string, params, headers map[string]string) (*authorizer.IsRequest, error) {
+	resource := map[string]interface{}{}
+	for name, value := range params {
+		resource[name] = value
+	}
+	for header, field := range s.cfg.ResourceHeaders {
+		if value, ok := headers[header]; ok {
+			resource[field] = value
+		}
+	}
+
+	resourceContext, err := structpb.NewStruct(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	identityContext := &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_NONE}
+	if auth := headers["authorization"]; len(auth) > len(bearerPrefix) && strings.EqualFold(auth[:len(bearerPrefix)], bearerPrefix) {
+		identityContext = &api.IdentityContext{
+			Type:     api.IdentityType_IDENTITY_TYPE_JWT,
+			Identity: strings.TrimSpace(auth[len(bearerPrefix):]),
+		}
+	}
+
+	return &authorizer.IsRequest{
+		IdentityContext: identityContext,
+		PolicyContext: &api.PolicyContext{
+			Path:      policyPath,
+			Decisions: []string{s.cfg.Decision},
+		},
+		ResourceContext: resourceContext,
+	}, nil
+}
+
+func (s *Server) allowed() *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &status.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{
+			OkResponse: &authv3.OkHttpResponse{
+				Headers: headerOptions(s.cfg.AllowedHeaders),
+			},
+		},
+	}
+}
+
+func (s *Server) denied(st *status.Status) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: st,
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status:  &typev3.HttpStatus{Code: typev3.StatusCode(s.cfg.DeniedStatus)},
+				Headers: headerOptions(s.cfg.DeniedHeaders),
+			},
+		},
+	}
+}
+
+func headerOptions(headers map[string]string) []*corev3.HeaderValueOption {
+	options := make([]*corev3.HeaderValueOption, 0, len(headers))
+	for key, value := range headers {
+		options = append(options, &corev3.HeaderValueOption{
+			Header: &corev3.HeaderValue{Key: key, Value: value},
+		})
+	}
+
+	return options
+}
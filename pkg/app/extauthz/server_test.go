@@ -0,0 +1,133 @@
+package extauthz_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/topaz/pkg/app/extauthz"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testAuthorizer allows the requests of the todo with id 1, it records the last Is request.
+type testAuthorizer struct {
+	last *authorizer.IsRequest
+}
+
+func (a *testAuthorizer) Is(ctx context.Context, req *authorizer.IsRequest) (*authorizer.IsResponse, error) {
+	a.last = req
+
+	allowed := req.PolicyContext.Path == "todoApp.GET.todos.__id" && req.ResourceContext.Fields["id"].GetStringValue() == "1"
+
+	return &authorizer.IsResponse{Decisions: []*authorizer.Decision{{Decision: "allowed", Is: allowed}}}, nil
+}
+
+// newTestClient serves the ext_authz server over gRPC and returns its client.
+func newTestClient(t *testing.T, authz extauthz.Authorizer) authv3.AuthorizationClient {
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+
+	server, err := extauthz.NewServer(&config.ExtAuthzConfig{
+		PolicyRoot:     "todoApp",
+		Decision:       "allowed",
+		Routes:         []config.ExtAuthzRoute{{Path: "/todos/{id}", Methods: []string{"GET"}}},
+		AllowedHeaders: map[string]string{"x-authorized-by": "topaz"},
+		DeniedStatus:   401,
+	}, authz, &logger)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	authv3.RegisterAuthorizationServer(grpcServer, server)
+
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return authv3.NewAuthorizationClient(conn)
+}
+
+func checkRequest(method, path string) *authv3.CheckRequest {
+	return &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Request: &authv3.AttributeContext_Request{
+				Http: &authv3.AttributeContext_HttpRequest{
+					Method:  method,
+					Path:    path,
+					Headers: map[string]string{"authorization": "Bearer token"},
+				},
+			},
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	authz := &testAuthorizer{}
+	client := newTestClient(t, authz)
+	ctx := context.Background()
+
+	// allowed, the path parameter is added to the resource context.
+	resp, err := client.Check(ctx, checkRequest("get", "/todos/1?view=full"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.OK), resp.Status.Code)
+	require.NotNil(t, resp.GetOkResponse())
+	require.Len(t, resp.GetOkResponse().Headers, 1)
+	assert.Equal(t, "x-authorized-by", resp.GetOkResponse().Headers[0].Header.Key)
+
+	require.NotNil(t, authz.last)
+	assert.Equal(t, "todoApp.GET.todos.__id", authz.last.PolicyContext.Path)
+	assert.Equal(t, "1", authz.last.ResourceContext.Fields["id"].GetStringValue())
+	assert.Equal(t, api.IdentityType_IDENTITY_TYPE_JWT, authz.last.IdentityContext.Type)
+	assert.Equal(t, "token", authz.last.IdentityContext.Identity)
+
+	// denied by the decision.
+	resp, err = client.Check(ctx, checkRequest("GET", "/todos/2"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.PermissionDenied), resp.Status.Code)
+	require.NotNil(t, resp.GetDeniedResponse())
+	assert.Equal(t, typev3.StatusCode_Unauthorized, resp.GetDeniedResponse().Status.Code)
+
+	// denied without evaluation, no route matches the method or the path.
+	for _, req := range []*authv3.CheckRequest{checkRequest("DELETE", "/todos/1"), checkRequest("GET", "/todos/1/items")} {
+		authz.last = nil
+
+		resp, err = client.Check(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, int32(codes.PermissionDenied), resp.Status.Code)
+		assert.Equal(t, "no route matches the request", resp.Status.Message)
+		assert.Nil(t, authz.last)
+	}
+}
+
+func TestCheckInvalidPath(t *testing.T) {
+	authz := &testAuthorizer{}
+	client := newTestClient(t, authz)
+
+	for _, path := range []string{"/todos/..", "/todos/%2E%2E", "/todos/.", "/todos/a%2Fb", "/todos/%zz", "//todos/1"} {
+		resp, err := client.Check(context.Background(), checkRequest("GET", path))
+		require.NoError(t, err)
+		assert.Equal(t, int32(codes.InvalidArgument), resp.Status.Code, path)
+		require.NotNil(t, resp.GetDeniedResponse(), path)
+	}
+
+	assert.Nil(t, authz.last)
+}
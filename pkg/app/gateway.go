@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aserto-dev/certs"
+	metrics "github.com/aserto-dev/go-http-metrics/middleware/grpc"
+	builder "github.com/aserto-dev/service-host"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"github.com/rs/cors"
+	"github.com/samber/lo"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// newGateway returns the gateway of a gRPC service, as built by the service builder, for the services
+// whose gRPC server is built apart.
+func newGateway(cfg *builder.API, gatewayOpts *builder.GatewayOptions) (builder.Gateway, error) {
+	if len(cfg.Gateway.AllowedHeaders) == 0 {
+		cfg.Gateway.AllowedHeaders = builder.DefaultGatewayAllowedHeaders
+	}
+	if len(cfg.Gateway.AllowedOrigins) == 0 {
+		cfg.Gateway.AllowedOrigins = builder.DefaultGatewayAllowedOrigins
+	}
+	if len(cfg.Gateway.AllowedMethods) == 0 {
+		cfg.Gateway.AllowedMethods = builder.DefaultGatewayAllowedMethods
+	}
+
+	c := cors.New(cors.Options{
+		AllowedHeaders: cfg.Gateway.AllowedHeaders,
+		AllowedOrigins: cfg.Gateway.AllowedOrigins,
+		AllowedMethods: cfg.Gateway.AllowedMethods,
+	})
+
+	runtimeMux := gatewayMux(cfg.Gateway.AllowedHeaders, gatewayOpts.ErrorHandler)
+
+	tlsCreds, err := certs.GatewayAsClientTLSCreds(cfg.GRPC.Certs)
+	if err != nil {
+		return builder.Gateway{}, errors.Wrap(err, "failed to get TLS credentials")
+	}
+
+	grpcEndpoint := fmt.Sprintf("dns:///%s", cfg.GRPC.ListenAddress)
+	if err := gatewayOpts.HandlerRegistrations(context.Background(), runtimeMux, grpcEndpoint, []grpc.DialOption{grpc.WithTransportCredentials(tlsCreds)}); err != nil {
+		return builder.Gateway{}, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", runtimeMux)
+	mux.Handle("/api/", fieldsMaskHandler(runtimeMux))
+
+	server := &http.Server{
+		Addr:              cfg.Gateway.ListenAddress,
+		Handler:           c.Handler(mux),
+		ReadTimeout:       cfg.Gateway.ReadTimeout,
+		ReadHeaderTimeout: cfg.Gateway.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Gateway.WriteTimeout,
+		IdleTimeout:       cfg.Gateway.IdleTimeout,
+	}
+
+	if cfg.Gateway.HTTP {
+		return builder.Gateway{Server: server, Mux: mux}, nil
+	}
+
+	tlsConfig, err := certs.GatewayServerTLSConfig(cfg.Gateway.Certs)
+	if err != nil {
+		return builder.Gateway{}, err
+	}
+	server.TLSConfig = tlsConfig
+
+	return builder.Gateway{Server: server, Mux: mux, Certs: &cfg.Gateway.Certs}, nil
+}
+
+// gatewayMux returns the gateway multiplexer of the service builder.
+func gatewayMux(allowedHeaders []string, errorHandler runtime.ErrorHandlerFunc) *runtime.ServeMux {
+	opts := []runtime.ServeMuxOption{
+		runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+			if lo.Contains(allowedHeaders, key) {
+				return key, true
+			}
+			return runtime.DefaultHeaderMatcher(key)
+		}),
+		runtime.WithMetadata(metrics.CaptureGatewayRoute),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, gatewayMarshaler(true)),
+		// the masked results don't emit the unpopulated fields.
+		runtime.WithMarshalerOption("application/json+masked", gatewayMarshaler(false)),
+		runtime.WithForwardResponseOption(httpResponseModifier),
+	}
+
+	if errorHandler != nil {
+		opts = append(opts, runtime.WithErrorHandler(errorHandler))
+	}
+
+	return runtime.NewServeMux(opts...)
+}
+
+func gatewayMarshaler(emitUnpopulated bool) *runtime.JSONPb {
+	return &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			Indent:          "  ",
+			AllowPartial:    true,
+			UseProtoNames:   true,
+			EmitUnpopulated: emitUnpopulated,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			AllowPartial:   true,
+			DiscardUnknown: true,
+		},
+	}
+}
+
+// httpResponseModifier sets the status code of the response to the x-http-code header set by the gRPC handler.
+func httpResponseModifier(ctx context.Context, w http.ResponseWriter, p proto.Message) error {
+	md, ok := runtime.ServerMetadataFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	if vals := md.HeaderMD.Get("x-http-code"); len(vals) > 0 {
+		code, err := strconv.Atoi(vals[0])
+		if err != nil {
+			return err
+		}
+		// the header is not exposed in the response.
+		delete(md.HeaderMD, "x-http-code")
+		delete(w.Header(), "Grpc-Metadata-X-Http-Code")
+		w.WriteHeader(code)
+	}
+
+	return nil
+}
+
+// fieldsMaskHandler selects the masked marshaler for the requests with a fields.mask query parameter.
+func fieldsMaskHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := r.URL.Query()["fields.mask"]; ok && len(p) > 0 && p[0] != "" {
+			r.Header.Set("Content-Type", "application/json+masked")
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -16,8 +16,11 @@ import (
 	"github.com/lestrrat-go/jwx/v2/jwk"
 
 	runtime "github.com/aserto-dev/runtime"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/directory"
 	decisionlog_plugin "github.com/aserto-dev/topaz/plugins/decision_log"
 
+	"github.com/aserto-dev/topaz/pkg/app/mtls"
 	"github.com/aserto-dev/topaz/pkg/cc/config"
 	"github.com/aserto-dev/topaz/pkg/version"
 	"github.com/aserto-dev/topaz/resolvers"
@@ -25,6 +28,7 @@ import (
 	"github.com/mennanov/fmutils"
 	"github.com/open-policy-agent/opa/rego"
 	"github.com/open-policy-agent/opa/server/types"
+	"github.com/open-policy-agent/opa/storage"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc/codes"
@@ -41,6 +45,9 @@ const (
 	InputIdentity string = "identity"
 	InputPolicy   string = "policy"
 	InputResource string = "resource"
+
+	// IdentityClaims is the identity input field holding the validated token claims of JWT identities.
+	IdentityClaims string = "claims"
 )
 
 type AuthorizerServer struct {
@@ -49,7 +56,21 @@ type AuthorizerServer struct {
 	issuers  sync.Map
 	jwkCache *jwk.Cache
 
-	resolver *resolvers.Resolvers
+	trustedIssuers map[string]*trustedIssuer
+
+	resolver   *resolvers.Resolvers
+	queryCache *preparedQueryCache
+	schemas    *resourceSchemaCache
+	identities *identityCache
+
+	identityResolvers identityResolvers
+	certIdentities    *mtls.Identities
+
+	// shadows bounds the number of concurrent shadow evaluations to the shadow max_concurrency, defaulted by the config validation.
+	shadows chan struct{}
+
+	// unregister releases the registrations of the caches, called when the server is closed.
+	unregister []func()
 }
 
 func NewAuthorizerServer(
@@ -57,16 +78,59 @@ func NewAuthorizerServer(
 	logger *zerolog.Logger,
 	cfg *config.Common,
 	rf *resolvers.Resolvers,
-) *AuthorizerServer {
+) (*AuthorizerServer, error) {
 	newLogger := logger.With().Str("component", "api.grpc").Logger()
 
 	jwkCache := jwk.NewCache(ctx)
 
+	trustedIssuers, err := newTrustedIssuers(cfg.JWT.Issuers)
+	if err != nil {
+		return nil, err
+	}
+
+	identityResolvers, err := newIdentityResolvers(cfg.IdentityResolvers, rf)
+	if err != nil {
+		return nil, err
+	}
+
+	certIdentities, err := mtls.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	unregister := []func(){}
+
+	identities := newIdentityCache(&cfg.IdentityCache)
+	if identities != nil {
+		unregister = append(unregister, directory.OnChange(identities.invalidate))
+	}
+
+	queryCache := newPreparedQueryCache()
+	unregister = append(unregister, resolvers.OnRuntimeStopped(queryCache.evict))
+
+	schemas := newResourceSchemaCache()
+	unregister = append(unregister, resolvers.OnRuntimeStopped(schemas.evict))
+
 	return &AuthorizerServer{
-		cfg:      cfg,
-		logger:   &newLogger,
-		resolver: rf,
-		jwkCache: jwkCache,
+		cfg:               cfg,
+		logger:            &newLogger,
+		resolver:          rf,
+		jwkCache:          jwkCache,
+		trustedIssuers:    trustedIssuers,
+		queryCache:        queryCache,
+		schemas:           schemas,
+		identities:        identities,
+		identityResolvers: identityResolvers,
+		certIdentities:    certIdentities,
+		shadows:           make(chan struct{}, cfg.Shadow.MaxConcurrency),
+		unregister:        unregister,
+	}, nil
+}
+
+// Close unregisters the caches of the server from the directory changes and the runtime stops.
+func (s *AuthorizerServer) Close() {
+	for _, fn := range s.unregister {
+		fn()
 	}
 }
 
@@ -97,7 +161,7 @@ func (s *AuthorizerServer) DecisionTree(ctx context.Context, req *authorizer.Dec
 		return resp, aerr.ErrInvalidArgument.Msg("identity type UNKNOWN")
 	}
 
-	user, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
+	user, claims, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
 	if err != nil {
 		log.Error().Err(err).Interface("req", req).Msg("failed to resolve identity context")
 		return resp, aerr.ErrAuthenticationFailed.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
@@ -105,7 +169,7 @@ func (s *AuthorizerServer) DecisionTree(ctx context.Context, req *authorizer.Dec
 
 	input := map[string]interface{}{
 		InputUser:     convert(user),
-		InputIdentity: convert(req.IdentityContext),
+		InputIdentity: identityInput(req.IdentityContext, claims),
 		InputPolicy:   req.PolicyContext,
 		InputResource: req.ResourceContext,
 	}
@@ -120,6 +184,18 @@ func (s *AuthorizerServer) DecisionTree(ctx context.Context, req *authorizer.Dec
 		return resp, errors.Wrap(err, "get policy list")
 	}
 
+	// the schema is read, the query is prepared and the decisions are evaluated in the same store transaction.
+	store := policyRuntime.GetPluginsManager().Store
+	txn, err := store.NewTransaction(ctx)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to open store transaction")
+	}
+	defer store.Abort(ctx, txn)
+
+	if err := s.validateResourceContext(ctx, policyRuntime, txn, req.PolicyContext.Path, req.ResourceContext); err != nil {
+		return resp, err
+	}
+
 	decisionFilter := initDecisionFilter(req.PolicyContext.Decisions)
 
 	queryStmt := strings.Builder{}
@@ -146,18 +222,19 @@ func (s *AuthorizerServer) DecisionTree(ctx context.Context, req *authorizer.Dec
 
 	results := make(map[string]interface{})
 
-	qry, err := rego.New(
-		rego.Compiler(policyRuntime.GetPluginsManager().GetCompiler()),
-		rego.Store(policyRuntime.GetPluginsManager().Store),
-		rego.Input(input),
-		rego.Query(queryStmt.String()),
-	).PrepareForEval(ctx)
-
+	qry, err := s.queryCache.prepare(ctx, policyRuntime, txn, queryStmt.String())
 	if err != nil {
 		return resp, aerr.ErrBadQuery.Err(err).Msg(queryStmt.String())
 	}
 
-	queryResults, err := qry.Eval(ctx, rego.EvalInput(input))
+	evalCtx, evalLimits := s.cfg.Limits.Start(ctx, decisionlog.APIDecisionTree)
+	defer evalLimits.End()
+
+	queryResults, err := qry.Eval(evalCtx, rego.EvalInput(input), rego.EvalTransaction(txn))
+	if violation := evalLimits.Violation(); violation != nil {
+		return resp, violation
+	}
+
 	if err != nil {
 		return resp, aerr.ErrBadQuery.Err(err).Msgf("query evaluation failed: %s", queryStmt.String())
 	} else if len(queryResults) == 0 {
@@ -187,11 +264,23 @@ func (s *AuthorizerServer) DecisionTree(ctx context.Context, req *authorizer.Dec
 		results[path] = outcomes
 	}
 
+	if err := evalLimits.CheckOutput(results); err != nil {
+		return resp, err
+	}
+
 	paths, err := structpb.NewStruct(results)
 	if err != nil {
 		return resp, err
 	}
 
+	if err := logEvaluation(ctx, policyRuntime, req, input, &decisionlog.Evaluation{
+		API:    decisionlog.APIDecisionTree,
+		Query:  queryStmt.String(),
+		Result: decisionTreeSummary(req.PolicyContext.Decisions, results),
+	}); err != nil {
+		return resp, err
+	}
+
 	resp = &authorizer.DecisionTreeResponse{
 		PathRoot: req.PolicyContext.Path,
 		Path:     paths,
@@ -201,97 +290,115 @@ func (s *AuthorizerServer) DecisionTree(ctx context.Context, req *authorizer.Dec
 }
 
 // Is decision eval function.
-func (s *AuthorizerServer) Is(ctx context.Context, req *authorizer.IsRequest) (*authorizer.IsResponse, error) { // nolint:funlen,gocyclo //TODO: split into smaller functions after merge with onebox
+func (s *AuthorizerServer) Is(ctx context.Context, req *authorizer.IsRequest) (*authorizer.IsResponse, error) {
+	return s.evalIs(ctx, req)
+}
+
+// evalIs validates and evaluates an Is request, the evaluation options are passed to the query evaluation.
+func (s *AuthorizerServer) evalIs(ctx context.Context, req *authorizer.IsRequest, opts ...rego.EvalOption) (*authorizer.IsResponse, error) {
 	log := s.logger.With().Str("api", "is").Logger()
 
 	resp := &authorizer.IsResponse{
 		Decisions: make([]*authorizer.Decision, 0),
 	}
 
+	if err := validateIsRequest(req); err != nil {
+		return resp, err
+	}
+
+	user, claims, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
+	if err != nil {
+		log.Error().Err(err).Interface("req", req).Msg("failed to resolve identity context")
+		return resp, aerr.ErrUserNotFound.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
+	}
+
+	policyRuntime, err := s.getRuntime(ctx, req.PolicyInstance)
+	if err != nil {
+		return resp, err
+	}
+
+	queryStmt := isQuery(req.PolicyContext.Path)
+
+	query, err := s.queryCache.prepare(ctx, policyRuntime, nil, queryStmt)
+	if err != nil {
+		return resp, aerr.ErrBadQuery.Err(err).Msg(queryStmt)
+	}
+
+	return s.is(ctx, policyRuntime, nil, query, req, user, claims, opts...)
+}
+
+// validateIsRequest validates the Is request and defaults an unset resource context.
+func validateIsRequest(req *authorizer.IsRequest) error {
 	if req.PolicyContext == nil {
-		return resp, aerr.ErrInvalidArgument.Msg("policy context not set")
+		return aerr.ErrInvalidArgument.Msg("policy context not set")
 	}
 
 	if req.PolicyContext.Path == "" {
-		return resp, aerr.ErrInvalidArgument.Msg("policy context path not set")
+		return aerr.ErrInvalidArgument.Msg("policy context path not set")
 	}
 
 	if len(req.PolicyContext.Decisions) == 0 {
-		return resp, aerr.ErrInvalidArgument.Msg("policy context decisions not set")
+		return aerr.ErrInvalidArgument.Msg("policy context decisions not set")
 	}
 
 	if req.ResourceContext == nil {
 		var err error
 		req.ResourceContext, err = structpb.NewStruct(make(map[string]interface{}))
 		if err != nil {
-			return resp, err
+			return err
 		}
 	}
 
 	if req.IdentityContext == nil {
-		return resp, aerr.ErrInvalidArgument.Msg("identity context not set")
+		return aerr.ErrInvalidArgument.Msg("identity context not set")
 	}
 
 	if req.IdentityContext.Type == api.IdentityType_IDENTITY_TYPE_UNKNOWN {
-		return resp, aerr.ErrInvalidArgument.Msg("identity type UNKNOWN")
+		return aerr.ErrInvalidArgument.Msg("identity type UNKNOWN")
 	}
 
-	user, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
-	if err != nil {
-		log.Error().Err(err).Interface("req", req).Msg("failed to resolve identity context")
-		return resp, aerr.ErrUserNotFound.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
-	}
+	return nil
+}
 
-	input := map[string]interface{}{
-		InputUser:     convert(user),
-		InputIdentity: convert(req.IdentityContext),
-		InputPolicy:   req.PolicyContext,
-		InputResource: req.ResourceContext,
-	}
+func isQuery(path string) string {
+	return fmt.Sprintf("x = data.%s", path)
+}
+
+// is evaluates the decisions of a validated Is request for the resolved user and token claims,
+// and sends the outcome to the decision log. The request is evaluated within the store transaction when it is set.
+func (s *AuthorizerServer) is(
+	ctx context.Context,
+	policyRuntime *runtime.Runtime,
+	txn storage.Transaction,
+	query *rego.PreparedEvalQuery,
+	req *authorizer.IsRequest,
+	user proto.Message,
+	claims map[string]interface{},
+	opts ...rego.EvalOption,
+) (*authorizer.IsResponse, error) {
+	log := s.logger.With().Str("api", "is").Logger()
+
+	input := isInput(req, user, claims)
 
 	log.Debug().Interface("input", input).Msg("calculating is")
 
-	policyRuntime, err := s.getRuntime(ctx, req.PolicyInstance)
+	resp, outcomes, err := s.validateAndDecide(ctx, policyRuntime, txn, query, req, input, opts...)
 	if err != nil {
 		return resp, err
 	}
 
-	queryStmt := fmt.Sprintf("x = data.%s", req.PolicyContext.Path)
-
-	query, err := rego.New(
-		rego.Compiler(policyRuntime.GetPluginsManager().GetCompiler()),
-		rego.Store(policyRuntime.GetPluginsManager().Store),
-		rego.Query(queryStmt),
-	).PrepareForEval(ctx)
+	s.shadow(ctx, policyRuntime, req, input, outcomes)
 
-	if err != nil {
-		return resp, aerr.ErrBadQuery.Err(err).Msg(queryStmt)
+	dlPlugin := decisionlog_plugin.Lookup(policyRuntime.GetPluginsManager())
+	if dlPlugin == nil || !dlPlugin.Audits(decisionlog.APIIs) {
+		return resp, err
 	}
 
-	results, err := query.Eval(ctx, rego.EvalInput(input))
-
-	if err != nil {
-		return resp, aerr.ErrBadQuery.Err(err).Msgf("query evaluation failed: %s", queryStmt)
-	} else if len(results) == 0 {
-		return resp, aerr.ErrBadQuery.Err(err).Msgf("undefined results: %s", queryStmt)
+	evaluation := decisionlog.Evaluation{
+		API:    decisionlog.APIIs,
+		Caller: decisionlog.CallerFromContext(ctx),
 	}
 
-	v := results[0].Bindings["x"]
-	outcomes := map[string]bool{}
-
-	for _, d := range req.PolicyContext.Decisions {
-		decision := authorizer.Decision{
-			Decision: d,
-		}
-		decision.Is, err = is(v, d)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed getting outcome for decision [%s]", d)
-		}
-		resp.Decisions = append(resp.Decisions, &decision)
-		outcomes[decision.Decision] = decision.Is
-	}
-
-	dlPlugin := decisionlog_plugin.Lookup(policyRuntime.GetPluginsManager())
 	tenantID := getTenantID(ctx)
 	d := api.Decision{
 		Id:        uuid.NewString(),
@@ -306,13 +413,10 @@ func (s *AuthorizerServer) Is(ctx context.Context, req *authorizer.IsRequest) (*
 			Id:      getID(input),
 			Email:   getEmail(input),
 		},
-		TenantId: tenantID,
-		Resource: req.ResourceContext,
-		Outcomes: outcomes,
-	}
-
-	if dlPlugin == nil {
-		return resp, err
+		TenantId:    tenantID,
+		Resource:    req.ResourceContext,
+		Outcomes:    outcomes,
+		Annotations: evaluation.Annotations(),
 	}
 
 	err = dlPlugin.Log(ctx, &d)
@@ -323,6 +427,92 @@ func (s *AuthorizerServer) Is(ctx context.Context, req *authorizer.IsRequest) (*
 	return resp, err
 }
 
+func isInput(req *authorizer.IsRequest, user proto.Message, claims map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		InputUser:     convert(user),
+		InputIdentity: identityInput(req.IdentityContext, claims),
+		InputPolicy:   req.PolicyContext,
+		InputResource: req.ResourceContext,
+	}
+}
+
+// validateAndDecide validates the resource context and evaluates the decisions within a single store transaction.
+// Without caller transaction, the transaction is opened and released before the shadow evaluation and the decision log.
+func (s *AuthorizerServer) validateAndDecide(
+	ctx context.Context,
+	policyRuntime *runtime.Runtime,
+	txn storage.Transaction,
+	query *rego.PreparedEvalQuery,
+	req *authorizer.IsRequest,
+	input map[string]interface{},
+	opts ...rego.EvalOption,
+) (*authorizer.IsResponse, map[string]bool, error) {
+	if txn == nil {
+		store := policyRuntime.GetPluginsManager().Store
+		var err error
+		if txn, err = store.NewTransaction(ctx); err != nil {
+			return &authorizer.IsResponse{Decisions: make([]*authorizer.Decision, 0)}, nil, errors.Wrap(err, "failed to open store transaction")
+		}
+		defer store.Abort(ctx, txn)
+	}
+
+	if err := s.validateResourceContext(ctx, policyRuntime, txn, req.PolicyContext.Path, req.ResourceContext); err != nil {
+		return &authorizer.IsResponse{Decisions: make([]*authorizer.Decision, 0)}, nil, err
+	}
+
+	return s.decide(ctx, query, req, input, append([]rego.EvalOption{rego.EvalTransaction(txn)}, opts...)...)
+}
+
+// decide evaluates the Is query for the input, it returns the outcomes of the requested decisions.
+func (s *AuthorizerServer) decide(
+	ctx context.Context,
+	query *rego.PreparedEvalQuery,
+	req *authorizer.IsRequest,
+	input map[string]interface{},
+	opts ...rego.EvalOption,
+) (*authorizer.IsResponse, map[string]bool, error) {
+	resp := &authorizer.IsResponse{
+		Decisions: make([]*authorizer.Decision, 0),
+	}
+
+	queryStmt := isQuery(req.PolicyContext.Path)
+
+	evalCtx, evalLimits := s.cfg.Limits.Start(ctx, decisionlog.APIIs)
+	defer evalLimits.End()
+
+	results, err := query.Eval(evalCtx, append([]rego.EvalOption{rego.EvalInput(input)}, opts...)...)
+	if violation := evalLimits.Violation(); violation != nil {
+		return resp, nil, violation
+	}
+
+	if err != nil {
+		return resp, nil, aerr.ErrBadQuery.Err(err).Msgf("query evaluation failed: %s", queryStmt)
+	} else if len(results) == 0 {
+		return resp, nil, aerr.ErrBadQuery.Err(err).Msgf("undefined results: %s", queryStmt)
+	}
+
+	if err := evalLimits.CheckOutput(results); err != nil {
+		return resp, nil, err
+	}
+
+	v := results[0].Bindings["x"]
+	outcomes := map[string]bool{}
+
+	for _, d := range req.PolicyContext.Decisions {
+		decision := authorizer.Decision{
+			Decision: d,
+		}
+		decision.Is, err = is(v, d)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed getting outcome for decision [%s]", d)
+		}
+		resp.Decisions = append(resp.Decisions, &decision)
+		outcomes[decision.Decision] = decision.Is
+	}
+
+	return resp, outcomes, nil
+}
+
 func getTenantID(ctx context.Context) *string {
 	tenantID := header.ExtractTenantID(ctx)
 	if tenantID != "" {
@@ -398,11 +588,7 @@ func (s *AuthorizerServer) Query(ctx context.Context, req *authorizer.QueryReque
 		}
 
 		if req.IdentityContext.Type != api.IdentityType_IDENTITY_TYPE_NONE {
-			input[InputIdentity] = convert(req.IdentityContext)
-		}
-
-		if req.IdentityContext.Type != api.IdentityType_IDENTITY_TYPE_NONE {
-			user, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
+			user, claims, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
 			if err != nil || user == nil {
 				if err != nil {
 					log.Error().Err(err).Interface("req", req).Msg("failed to resolve identity context")
@@ -411,6 +597,7 @@ func (s *AuthorizerServer) Query(ctx context.Context, req *authorizer.QueryReque
 				return &authorizer.QueryResponse{}, aerr.ErrAuthenticationFailed.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
 			}
 
+			input[InputIdentity] = identityInput(req.IdentityContext, claims)
 			input[InputUser] = convert(user)
 		}
 	}
@@ -427,8 +614,11 @@ func (s *AuthorizerServer) Query(ctx context.Context, req *authorizer.QueryReque
 		return &authorizer.QueryResponse{}, aerr.ErrBadQuery.Err(err)
 	}
 
+	evalCtx, evalLimits := s.cfg.Limits.Start(ctx, decisionlog.APIQuery)
+	defer evalLimits.End()
+
 	queryResult, err := rt.Query(
-		ctx,
+		evalCtx,
 		req.Query,
 		input,
 		req.Options.TraceSummary,
@@ -436,10 +626,26 @@ func (s *AuthorizerServer) Query(ctx context.Context, req *authorizer.QueryReque
 		req.Options.Instrument,
 		TraceLevelToExplainModeV2(req.Options.Trace),
 	)
+	if violation := evalLimits.Violation(); violation != nil {
+		return &authorizer.QueryResponse{}, violation
+	}
+
 	if err != nil {
 		return &authorizer.QueryResponse{}, err
 	}
 
+	if err := evalLimits.CheckOutput(queryResult.Result); err != nil {
+		return &authorizer.QueryResponse{}, err
+	}
+
+	if err := logEvaluation(ctx, rt, req, input, &decisionlog.Evaluation{
+		API:    decisionlog.APIQuery,
+		Query:  req.Query,
+		Result: querySummary(queryResult.Result),
+	}); err != nil {
+		return &authorizer.QueryResponse{}, err
+	}
+
 	resp := &authorizer.QueryResponse{}
 	queryResultJSON, err := json.Marshal(queryResult.Result)
 	if err != nil {
@@ -510,11 +716,12 @@ func (s *AuthorizerServer) getRuntime(ctx context.Context, policyInstance *api.P
 	return rt, err
 }
 
-func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileRequest) (*authorizer.CompileResponse, error) { // nolint:funlen,gocyclo //TODO: split into smaller functions after merge with onebox
+// compile partially evaluates the query of the compile request, it returns the runtime, the input and the result of the evaluation.
+func (s *AuthorizerServer) compile(ctx context.Context, req *authorizer.CompileRequest) (*runtime.Runtime, map[string]interface{}, *runtime.CompileResult, error) { // nolint:funlen,gocyclo
 	log := s.logger.With().Str("api", "compile").Logger()
 
 	if req.Query == "" {
-		return &authorizer.CompileResponse{}, aerr.ErrInvalidArgument.Msg("query not set")
+		return nil, nil, nil, aerr.ErrInvalidArgument.Msg("query not set")
 	}
 
 	if req.Options == nil {
@@ -534,7 +741,7 @@ func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileR
 
 	if req.Input != "" {
 		if err := json.Unmarshal([]byte(req.Input), &input); err != nil {
-			return &authorizer.CompileResponse{}, errors.Wrap(err, "failed to unmarshal input - make sure it's a valid JSON object")
+			return nil, nil, nil, errors.Wrap(err, "failed to unmarshal input - make sure it's a valid JSON object")
 		}
 	}
 
@@ -548,23 +755,20 @@ func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileR
 
 	if req.IdentityContext != nil {
 		if req.IdentityContext.Type == api.IdentityType_IDENTITY_TYPE_UNKNOWN {
-			return &authorizer.CompileResponse{}, aerr.ErrInvalidArgument.Msg("identity type UNKNOWN")
+			return nil, nil, nil, aerr.ErrInvalidArgument.Msg("identity type UNKNOWN")
 		}
 
 		if req.IdentityContext.Type != api.IdentityType_IDENTITY_TYPE_NONE {
-			input[InputIdentity] = convert(req.IdentityContext)
-		}
-
-		if req.IdentityContext.Type != api.IdentityType_IDENTITY_TYPE_NONE {
-			user, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
+			user, claims, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
 			if err != nil || user == nil {
 				if err != nil {
 					log.Error().Err(err).Interface("req", req).Msg("failed to resolve identity context")
 				}
 
-				return &authorizer.CompileResponse{}, aerr.ErrAuthenticationFailed.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
+				return nil, nil, nil, aerr.ErrAuthenticationFailed.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
 			}
 
+			input[InputIdentity] = identityInput(req.IdentityContext, claims)
 			input[InputUser] = convert(user)
 		}
 	}
@@ -579,15 +783,18 @@ func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileR
 	log.Debug().Str("compile", req.Query).Interface("input", input).Msg("executing compile")
 	rt, err := s.getRuntime(ctx, req.PolicyInstance)
 	if err != nil {
-		return &authorizer.CompileResponse{}, err
+		return nil, nil, nil, err
 	}
 
 	_, err = rt.ValidateQuery(req.Query)
 	if err != nil {
-		return &authorizer.CompileResponse{}, aerr.ErrBadQuery.Err(err)
+		return nil, nil, nil, aerr.ErrBadQuery.Err(err)
 	}
 
-	compileResult, err := rt.Compile(ctx, req.Query,
+	evalCtx, evalLimits := s.cfg.Limits.Start(ctx, decisionlog.APICompile)
+	defer evalLimits.End()
+
+	compileResult, err := rt.Compile(evalCtx, req.Query,
 		input,
 		req.Unknowns,
 		req.DisableInlining,
@@ -595,6 +802,23 @@ func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileR
 		req.Options.Metrics,
 		req.Options.Instrument,
 		TraceLevelToExplainModeV2(req.Options.Trace))
+	if violation := evalLimits.Violation(); violation != nil {
+		return nil, nil, nil, violation
+	}
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := evalLimits.CheckOutput(compileResult.Result); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return rt, input, compileResult, nil
+}
+
+func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileRequest) (*authorizer.CompileResponse, error) { // nolint:funlen,gocyclo //TODO: split into smaller functions after merge with onebox
+	rt, input, compileResult, err := s.compile(ctx, req)
 	resp := &authorizer.CompileResponse{}
 	if err != nil {
 		return resp, err
@@ -613,6 +837,14 @@ func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileR
 	if err != nil {
 		return resp, err
 	}
+
+	if err := logEvaluation(ctx, rt, req, input, &decisionlog.Evaluation{
+		API:    decisionlog.APICompile,
+		Query:  req.Query,
+		Result: compileSummary(compileResult),
+	}); err != nil {
+		return resp, err
+	}
 	// metrics
 	if compileResult.Metrics != nil {
 		if metricsStruct, errX := structpb.NewStruct(compileResult.Metrics); errX == nil {
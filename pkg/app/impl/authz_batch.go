@@ -0,0 +1,193 @@
+package impl
+
+import (
+	"context"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	runtime "github.com/aserto-dev/runtime"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
+	"github.com/aserto-dev/topaz/limits"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxBatchSize is the maximum number of requests accepted by a single IsBatch call.
+const MaxBatchSize = 1000
+
+// MaxBatchDuration is the maximum duration of the evaluations of a single IsBatch call,
+// the requests not evaluated in time fail with a timeout error.
+const MaxBatchDuration = 10 * time.Second
+
+var errBatchTimeout = errors.New("batch timeout")
+
+var _ azv1.AuthorizerServer = (*AuthorizerServer)(nil)
+
+// batchItem holds the state of a single request while its batch is evaluated.
+type batchItem struct {
+	req     *authorizer.IsRequest
+	user    proto.Message
+	claims  map[string]interface{}
+	runtime *runtime.Runtime
+	resp    *authorizer.IsResponse
+	err     error
+}
+
+type userResult struct {
+	user   proto.Message
+	claims map[string]interface{}
+	err    error
+}
+
+// IsBatch evaluates many Is requests in one call.
+//
+// Each distinct identity is resolved once and each query is prepared once per runtime.
+// The requests of a runtime are evaluated against the same snapshot, within a single store transaction,
+// so that a batch never mixes the decisions of two bundles. Bundle activations wait for the transaction
+// to be released, the evaluations of a batch are bounded by MaxBatchDuration.
+// A failing request is reported in its result and does not fail the batch.
+func (s *AuthorizerServer) IsBatch(ctx context.Context, req *azv1.IsBatchRequest) (*azv1.IsBatchResponse, error) {
+	log := s.logger.With().Str("api", "is_batch").Logger()
+
+	resp := &azv1.IsBatchResponse{
+		Results: make([]*azv1.IsBatchResult, 0, len(req.Requests)),
+	}
+
+	if len(req.Requests) == 0 {
+		return resp, aerr.ErrInvalidArgument.Msg("requests not set")
+	}
+
+	if len(req.Requests) > MaxBatchSize {
+		return resp, aerr.ErrInvalidArgument.Msgf("too many requests (%d), max batch size is %d", len(req.Requests), MaxBatchSize)
+	}
+
+	users := map[string]userResult{}
+	runtimes := map[string]*runtime.Runtime{}
+
+	// the requests of each runtime, in the order the runtimes are first requested.
+	order := []*runtime.Runtime{}
+	batches := map[*runtime.Runtime][]*batchItem{}
+
+	// resolve identities and runtimes before evaluating the requests.
+	items := make([]*batchItem, len(req.Requests))
+	for i, r := range req.Requests {
+		item := &batchItem{req: r}
+		items[i] = item
+
+		if item.err = validateIsRequest(r); item.err != nil {
+			continue
+		}
+
+		identityKey := r.IdentityContext.Type.String() + ":" + r.IdentityContext.Identity
+		result, ok := users[identityKey]
+		if !ok {
+			result.user, result.claims, result.err = s.getUserFromIdentityContext(ctx, r.IdentityContext)
+			if result.err != nil {
+				log.Error().Err(result.err).Interface("identity_context", r.IdentityContext).Msg("failed to resolve identity context")
+				result.err = aerr.ErrUserNotFound.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
+			}
+			users[identityKey] = result
+		}
+		if result.err != nil {
+			item.err = result.err
+			continue
+		}
+		item.user, item.claims = result.user, result.claims
+
+		instanceKey := r.PolicyInstance.GetName() + "/" + r.PolicyInstance.GetInstanceLabel()
+		rt, ok := runtimes[instanceKey]
+		if !ok {
+			rt, item.err = s.getRuntime(ctx, r.PolicyInstance)
+			if item.err != nil {
+				continue
+			}
+			runtimes[instanceKey] = rt
+		}
+		item.runtime = rt
+
+		if _, ok := batches[rt]; !ok {
+			order = append(order, rt)
+		}
+		batches[rt] = append(batches[rt], item)
+	}
+
+	batchCtx, cancel := context.WithTimeoutCause(ctx, MaxBatchDuration, errBatchTimeout)
+	defer cancel()
+
+	// a single transaction is held at a time, the runtimes are evaluated one after the other.
+	for _, rt := range order {
+		s.isBatch(batchCtx, rt, batches[rt])
+	}
+
+	for _, item := range items {
+		if item.err != nil {
+			resp.Results = append(resp.Results, &azv1.IsBatchResult{
+				Result: &azv1.IsBatchResult_Error{Error: ErrorStatus(item.err).Proto()},
+			})
+			continue
+		}
+
+		resp.Results = append(resp.Results, &azv1.IsBatchResult{
+			Result: &azv1.IsBatchResult_Response{Response: item.resp},
+		})
+	}
+
+	return resp, nil
+}
+
+// isBatch evaluates the requests of the runtime within a single store transaction. The queries are prepared
+// within the transaction as well, so that they are planned against the compiler of the snapshot.
+func (s *AuthorizerServer) isBatch(ctx context.Context, rt *runtime.Runtime, items []*batchItem) {
+	store := rt.GetPluginsManager().Store
+
+	txn, err := store.NewTransaction(ctx)
+	if err != nil {
+		for _, item := range items {
+			item.err = errors.Wrap(err, "failed to open store transaction")
+		}
+		return
+	}
+	defer store.Abort(ctx, txn)
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			item.err = batchError(ctx)
+			continue
+		}
+
+		queryStmt := isQuery(item.req.PolicyContext.Path)
+
+		query, err := s.queryCache.prepare(ctx, rt, txn, queryStmt)
+		if err != nil {
+			item.err = aerr.ErrBadQuery.Err(err).Msg(queryStmt)
+			continue
+		}
+
+		item.resp, item.err = s.is(ctx, rt, txn, query, item.req, item.user, item.claims)
+		if item.err != nil && ctx.Err() != nil {
+			item.err = batchError(ctx)
+		}
+	}
+}
+
+// batchError returns the error of the requests not evaluated before the batch context is done.
+func batchError(ctx context.Context) error {
+	if context.Cause(ctx) == errBatchTimeout {
+		return limits.ErrTimeout.Msgf("is_batch evaluations exceeded %s", MaxBatchDuration)
+	}
+	return ctx.Err()
+}
+
+// ErrorStatus returns the status of an evaluation error, reported by the APIs returning the errors of individual evaluations.
+func ErrorStatus(err error) *status.Status {
+	if asertoErr := cerr.UnwrapAsertoError(err); asertoErr != nil {
+		return asertoErr.GRPCStatus()
+	}
+
+	return status.Convert(err)
+}
@@ -0,0 +1,192 @@
+package impl
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	runtime "github.com/aserto-dev/runtime"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// testRuntimeResolver serves test runtimes and shadow runtimes by policy name, the default runtime has no name.
+type testRuntimeResolver struct {
+	resolvers.RuntimeResolver
+	runtimes map[string]*runtime.Runtime
+	shadows  map[string]*runtime.Runtime
+}
+
+func (r *testRuntimeResolver) RuntimeFromContext(ctx context.Context, policyName, instanceLabel string) (*runtime.Runtime, error) {
+	rt, ok := r.runtimes[policyName]
+	if !ok {
+		return nil, aerr.ErrPolicyNotFound.Msgf("policy instance %q not found", policyName)
+	}
+	return rt, nil
+}
+
+func (r *testRuntimeResolver) ShadowRuntime(ctx context.Context, policyName, instanceLabel string) (*runtime.Runtime, error) {
+	return r.shadows[policyName], nil
+}
+
+func newTestServer(t *testing.T, cfg *config.Common, runtimes map[string]*runtime.Runtime) *AuthorizerServer {
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+
+	rf := resolvers.New()
+	rf.SetRuntimeResolver(&testRuntimeResolver{runtimes: runtimes})
+
+	s, err := NewAuthorizerServer(context.Background(), &logger, cfg, rf)
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func isRequest(policyName, id string) *authorizer.IsRequest {
+	req := &authorizer.IsRequest{
+		IdentityContext: &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_NONE},
+		PolicyContext:   &api.PolicyContext{Path: "test", Decisions: []string{"allowed"}},
+		ResourceContext: &structpb.Struct{Fields: map[string]*structpb.Value{"id": structpb.NewStringValue(id)}},
+	}
+	if policyName != "" {
+		req.PolicyInstance = &api.PolicyInstance{Name: policyName}
+	}
+	return req
+}
+
+func TestIsBatch(t *testing.T) {
+	def := newTestRuntime(t)
+	upsertPolicy(t, def, "package test\n\ndefault allowed = false\n\nallowed {\n\tinput.resource.id == \"1\"\n}\n")
+
+	other := newTestRuntime(t)
+	upsertPolicy(t, other, "package test\n\nallowed = true\n")
+
+	s := newTestServer(t, &config.Common{}, map[string]*runtime.Runtime{"": def, "other": other})
+
+	noPolicyContext := isRequest("", "1")
+	noPolicyContext.PolicyContext = nil
+
+	resp, err := s.IsBatch(context.Background(), &azv1.IsBatchRequest{
+		Requests: []*authorizer.IsRequest{
+			isRequest("", "1"),
+			isRequest("", "2"),
+			isRequest("other", "2"),
+			noPolicyContext,
+			isRequest("missing", "1"),
+			isRequest("other", "1"),
+			isRequest("", "1"),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 7)
+
+	allowed := func(i int) bool {
+		r := resp.Results[i].GetResponse()
+		require.NotNil(t, r, "result %d: %v", i, resp.Results[i].GetError())
+		require.Len(t, r.Decisions, 1)
+		return r.Decisions[0].Is
+	}
+
+	// results are in request order and each request is evaluated by the runtime of its policy instance.
+	assert.True(t, allowed(0))
+	assert.False(t, allowed(1))
+	assert.True(t, allowed(2))
+	assert.True(t, allowed(5))
+	assert.True(t, allowed(6))
+
+	// failing requests are reported in their result and do not fail the others.
+	assert.Equal(t, int32(codes.InvalidArgument), resp.Results[3].GetError().GetCode())
+	assert.NotNil(t, resp.Results[4].GetError())
+	assert.NotEqual(t, int32(codes.OK), resp.Results[4].GetError().GetCode())
+}
+
+// activateBundle writes the policy and the data of a bundle in a single store transaction, as a bundle activation.
+func activateBundle(t *testing.T, rt *runtime.Runtime, module string, data map[string]interface{}) error {
+	ctx := context.Background()
+	store := rt.GetPluginsManager().Store
+
+	return storage.Txn(ctx, store, storage.WriteParams, func(txn storage.Transaction) error {
+		if err := store.UpsertPolicy(ctx, txn, "test.rego", []byte(module)); err != nil {
+			return err
+		}
+		return store.Write(ctx, txn, storage.AddOp, storage.MustParsePath("/bundle"), data)
+	})
+}
+
+func TestIsBatchSnapshot(t *testing.T) {
+	dl := &testDecisionLogger{}
+	rt := newAuditedRuntime(t, dl, decisionlog.APIIs)
+	require.NoError(t, activateBundle(t, rt, "package test\n\nallowed = data.bundle.allowed\n", map[string]interface{}{"allowed": true}))
+
+	s := newTestServer(t, &config.Common{}, map[string]*runtime.Runtime{"": rt})
+
+	// a new bundle is activated once the first request is evaluated.
+	activated := make(chan error, 1)
+	var once sync.Once
+	dl.onLog = func(*api.Decision) {
+		once.Do(func() {
+			go func() {
+				activated <- activateBundle(t, rt, "package test\n\nallowed = false\n", map[string]interface{}{"allowed": false})
+			}()
+
+			// the activation waits for the batch snapshot to be released.
+			select {
+			case err := <-activated:
+				activated <- err
+				t.Error("bundle activated during the batch")
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+
+	resp, err := s.IsBatch(context.Background(), &azv1.IsBatchRequest{
+		Requests: []*authorizer.IsRequest{isRequest("", "1"), isRequest("", "2"), isRequest("", "3")},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 3)
+
+	// all requests are evaluated against the bundle active when the batch started.
+	for i, result := range resp.Results {
+		require.NotNil(t, result.GetResponse(), "result %d: %v", i, result.GetError())
+		assert.True(t, result.GetResponse().Decisions[0].Is, "result %d", i)
+	}
+
+	select {
+	case err := <-activated:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("bundle not activated after the batch")
+	}
+
+	dl.onLog = nil
+	result, err := s.Is(context.Background(), isRequest("", "1"))
+	require.NoError(t, err)
+	assert.False(t, result.Decisions[0].Is)
+}
+
+func TestIsBatchLimits(t *testing.T) {
+	s := newTestServer(t, &config.Common{}, map[string]*runtime.Runtime{})
+
+	_, err := s.IsBatch(context.Background(), &azv1.IsBatchRequest{})
+	assert.Error(t, err)
+
+	requests := make([]*authorizer.IsRequest, MaxBatchSize+1)
+	for i := range requests {
+		requests[i] = isRequest("", "1")
+	}
+	_, err = s.IsBatch(context.Background(), &azv1.IsBatchRequest{Requests: requests})
+	assert.Error(t, err)
+}
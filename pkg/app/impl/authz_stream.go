@@ -0,0 +1,133 @@
+package impl
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
+)
+
+// MaxStreamConcurrency is the maximum number of requests of a single IsStream call evaluated concurrently,
+// further requests are not read from the stream until an evaluation completes.
+const MaxStreamConcurrency = 64
+
+// IsStream evaluates the Is requests received on the stream.
+//
+// Requests are evaluated concurrently, sharing the identity resolution and prepared queries of Is,
+// and each response is sent as soon as its request is evaluated, tagged with the request id.
+// A failing request is reported in its response and does not end the stream, a failing send ends it:
+// the pending evaluations are canceled and the send error is returned.
+func (s *AuthorizerServer) IsStream(stream azv1.Authorizer_IsStreamServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		sendMtx sync.Mutex
+		sendErr error
+	)
+
+	pending := make(chan struct{}, MaxStreamConcurrency)
+
+	send := func(resp *azv1.IsStreamResponse) {
+		sendMtx.Lock()
+		defer sendMtx.Unlock()
+
+		if sendErr != nil {
+			return
+		}
+
+		if err := stream.Send(resp); err != nil {
+			s.logger.Error().Err(err).Str("api", "is_stream").Str("id", resp.Id).Msg("failed to send response")
+			sendErr = err
+			cancel()
+		}
+	}
+
+	// streamErr returns the send error, if any, once the pending evaluations completed.
+	streamErr := func(err error) error {
+		wg.Wait()
+
+		sendMtx.Lock()
+		defer sendMtx.Unlock()
+
+		if sendErr != nil {
+			return sendErr
+		}
+		return err
+	}
+
+	// requests are received in the background, so that a failing send ends the stream while Recv blocks.
+	// Recv returns once the stream ends, when IsStream returns.
+	requests := make(chan *azv1.IsStreamRequest)
+	recvErr := make(chan error, 1)
+
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		var req *azv1.IsStreamRequest
+
+		select {
+		case req = <-requests:
+		case err := <-recvErr:
+			if err == io.EOF {
+				return streamErr(nil)
+			}
+			return streamErr(err)
+		case <-ctx.Done():
+			return streamErr(ctx.Err())
+		}
+
+		select {
+		case pending <- struct{}{}:
+		case <-ctx.Done():
+			return streamErr(ctx.Err())
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-pending
+				wg.Done()
+			}()
+
+			send(s.isStreamResponse(ctx, req))
+		}()
+	}
+}
+
+// isStreamResponse evaluates a request of the stream.
+func (s *AuthorizerServer) isStreamResponse(ctx context.Context, req *azv1.IsStreamRequest) *azv1.IsStreamResponse {
+	resp := &azv1.IsStreamResponse{Id: req.Id}
+
+	if req.Request == nil {
+		resp.Result = &azv1.IsStreamResponse_Error{Error: ErrorStatus(aerr.ErrInvalidArgument.Msg("request not set")).Proto()}
+		return resp
+	}
+
+	result, err := s.evalIs(ctx, req.Request)
+	if err != nil {
+		resp.Result = &azv1.IsStreamResponse_Error{Error: ErrorStatus(err).Proto()}
+		return resp
+	}
+
+	resp.Result = &azv1.IsStreamResponse_Response{Response: result}
+
+	return resp
+}
@@ -0,0 +1,114 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	runtime "github.com/aserto-dev/runtime"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// testStream receives the requests of its channel. Once they are received, Recv returns io.EOF when end is set,
+// or else blocks until the stream context is done, as a client keeping the stream open.
+type testStream struct {
+	grpc.ServerStream
+
+	ctx      context.Context
+	requests chan *azv1.IsStreamRequest
+	end      bool
+	sendErr  error
+
+	mu        sync.Mutex
+	responses []*azv1.IsStreamResponse
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testStream) Recv() (*azv1.IsStreamRequest, error) {
+	select {
+	case req, ok := <-s.requests:
+		if ok {
+			return req, nil
+		}
+		if s.end {
+			return nil, io.EOF
+		}
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+func (s *testStream) Send(resp *azv1.IsStreamResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func TestIsStream(t *testing.T) {
+	rt := newTestRuntime(t)
+	upsertPolicy(t, rt, "package test\n\ndefault allowed = false\n\nallowed {\n\tinput.resource.id == \"1\"\n}\n")
+	s := newTestServer(t, &config.Common{}, map[string]*runtime.Runtime{"": rt})
+
+	stream := &testStream{ctx: context.Background(), requests: make(chan *azv1.IsStreamRequest, 3), end: true}
+	stream.requests <- &azv1.IsStreamRequest{Id: "a", Request: isRequest("", "1")}
+	stream.requests <- &azv1.IsStreamRequest{Id: "b", Request: isRequest("", "2")}
+	stream.requests <- &azv1.IsStreamRequest{Id: "c"}
+	close(stream.requests)
+
+	require.NoError(t, s.IsStream(stream))
+
+	results := map[string]*azv1.IsStreamResponse{}
+	for _, resp := range stream.responses {
+		results[resp.Id] = resp
+	}
+	require.Len(t, results, 3)
+	assert.True(t, results["a"].GetResponse().Decisions[0].Is)
+	assert.False(t, results["b"].GetResponse().Decisions[0].Is)
+	assert.NotNil(t, results["c"].GetError())
+}
+
+func TestIsStreamSendError(t *testing.T) {
+	rt := newTestRuntime(t)
+	upsertPolicy(t, rt, "package test\n\nallowed = true\n")
+	s := newTestServer(t, &config.Common{}, map[string]*runtime.Runtime{"": rt})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the client keeps the stream open after its request.
+	sendErr := errors.New("client gone")
+	stream := &testStream{ctx: ctx, requests: make(chan *azv1.IsStreamRequest, 1), sendErr: sendErr}
+	stream.requests <- &azv1.IsStreamRequest{Id: "a", Request: isRequest("", "1")}
+	close(stream.requests)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.IsStream(stream)
+	}()
+
+	select {
+	case err := <-done:
+		assert.Equal(t, sendErr, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not ended by the failing send")
+	}
+}
@@ -0,0 +1,126 @@
+package impl
+
+import (
+	"context"
+
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
+	"github.com/aserto-dev/topaz/datafilter"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/open-policy-agent/opa/server/types"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var sqlDialects = map[azv1.SQLDialect]datafilter.Dialect{
+	azv1.SQLDialect_SQL_DIALECT_POSTGRES: datafilter.Postgres,
+	azv1.SQLDialect_SQL_DIALECT_SQLITE:   datafilter.SQLite,
+}
+
+var filterOperators = map[datafilter.Op]azv1.FilterOperator{
+	datafilter.OpAnd:   azv1.FilterOperator_FILTER_OPERATOR_AND,
+	datafilter.OpOr:    azv1.FilterOperator_FILTER_OPERATOR_OR,
+	datafilter.OpNot:   azv1.FilterOperator_FILTER_OPERATOR_NOT,
+	datafilter.OpEq:    azv1.FilterOperator_FILTER_OPERATOR_EQ,
+	datafilter.OpNe:    azv1.FilterOperator_FILTER_OPERATOR_NE,
+	datafilter.OpLt:    azv1.FilterOperator_FILTER_OPERATOR_LT,
+	datafilter.OpLte:   azv1.FilterOperator_FILTER_OPERATOR_LTE,
+	datafilter.OpGt:    azv1.FilterOperator_FILTER_OPERATOR_GT,
+	datafilter.OpGte:   azv1.FilterOperator_FILTER_OPERATOR_GTE,
+	datafilter.OpIn:    azv1.FilterOperator_FILTER_OPERATOR_IN,
+	datafilter.OpTrue:  azv1.FilterOperator_FILTER_OPERATOR_TRUE,
+	datafilter.OpFalse: azv1.FilterOperator_FILTER_OPERATOR_FALSE,
+}
+
+// CompileFilter partially evaluates the query of the compile request with the resource as unknown,
+// and translates the residual queries into a filter expression over resource columns.
+func (s *AuthorizerServer) CompileFilter(ctx context.Context, req *azv1.CompileFilterRequest) (*azv1.CompileFilterResponse, error) {
+	resp := &azv1.CompileFilterResponse{}
+
+	if req.Compile == nil {
+		return resp, aerr.ErrInvalidArgument.Msg("compile request not set")
+	}
+
+	dialect, withSQL := sqlDialects[req.Dialect]
+	if !withSQL && req.Dialect != azv1.SQLDialect_SQL_DIALECT_UNKNOWN {
+		return resp, aerr.ErrInvalidArgument.Msgf("unknown SQL dialect %s", req.Dialect)
+	}
+
+	if len(req.Compile.Unknowns) == 0 {
+		req.Compile.Unknowns = []string{datafilter.ResourceRef.String()}
+	}
+
+	rt, input, compileResult, err := s.compile(ctx, req.Compile)
+	if err != nil {
+		return resp, err
+	}
+
+	result, ok := (*compileResult.Result).(types.PartialEvaluationResultV1)
+	if !ok {
+		return resp, aerr.ErrBadQuery.Msg("unexpected partial evaluation result")
+	}
+
+	if len(result.Support) > 0 {
+		return resp, aerr.ErrBadQuery.Msg("residual queries depend on support rules, which cannot be translated into a filter")
+	}
+
+	filter, err := datafilter.Translate(result.Queries, req.Columns)
+	if err != nil {
+		return resp, aerr.ErrBadQuery.Err(err).Msg("failed to translate residual queries into a filter")
+	}
+
+	if err := logEvaluation(ctx, rt, req.Compile, input, &decisionlog.Evaluation{
+		API:    decisionlog.APICompile,
+		Query:  req.Compile.Query,
+		Result: compileSummary(compileResult),
+	}); err != nil {
+		return resp, err
+	}
+
+	if resp.Filter, err = filterExpression(filter); err != nil {
+		return resp, err
+	}
+
+	if withSQL {
+		where, args, err := filter.SQL(dialect)
+		if err != nil {
+			return resp, aerr.ErrBadQuery.Err(err).Msg("failed to translate filter into SQL")
+		}
+
+		resp.Sql = &azv1.SQLFilter{Where: where}
+		for _, arg := range args {
+			value, err := structpb.NewValue(arg)
+			if err != nil {
+				return resp, err
+			}
+			resp.Sql.Args = append(resp.Sql.Args, value)
+		}
+	}
+
+	return resp, nil
+}
+
+// filterExpression converts a filter expression into its API representation.
+func filterExpression(e *datafilter.Expr) (*azv1.FilterExpression, error) {
+	expr := &azv1.FilterExpression{
+		Operator: filterOperators[e.Op],
+		Column:   e.Column,
+	}
+
+	if e.Column != "" {
+		value, err := structpb.NewValue(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		expr.Value = value
+	}
+
+	for _, operand := range e.Operands {
+		o, err := filterExpression(operand)
+		if err != nil {
+			return nil, err
+		}
+		expr.Operands = append(expr.Operands, o)
+	}
+
+	return expr, nil
+}
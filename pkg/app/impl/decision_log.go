@@ -0,0 +1,130 @@
+package impl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	runtime "github.com/aserto-dev/runtime"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	decisionlog_plugin "github.com/aserto-dev/topaz/plugins/decision_log"
+	"github.com/google/uuid"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/server/types"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// evaluationRequest is implemented by the Query, DecisionTree and Compile requests.
+type evaluationRequest interface {
+	GetPolicyContext() *api.PolicyContext
+	GetIdentityContext() *api.IdentityContext
+	GetResourceContext() *structpb.Struct
+	GetPolicyInstance() *api.PolicyInstance
+}
+
+// logEvaluation sends the record of a Query, DecisionTree or Compile evaluation to the decision log,
+// when the decision log of the runtime audits the API.
+func logEvaluation(
+	ctx context.Context,
+	policyRuntime *runtime.Runtime,
+	req evaluationRequest,
+	input map[string]interface{},
+	evaluation *decisionlog.Evaluation,
+) error {
+	dlPlugin := decisionlog_plugin.Lookup(policyRuntime.GetPluginsManager())
+	if dlPlugin == nil || !dlPlugin.Audits(evaluation.API) {
+		return nil
+	}
+
+	digest, err := inputDigest(input)
+	if err != nil {
+		return err
+	}
+
+	evaluation.InputDigest = digest
+	evaluation.Caller = decisionlog.CallerFromContext(ctx)
+
+	d := api.Decision{
+		Id:        uuid.NewString(),
+		Timestamp: timestamppb.New(time.Now().In(time.UTC)),
+		Path:      req.GetPolicyContext().GetPath(),
+		Policy: &api.DecisionPolicy{
+			Context:        req.GetPolicyContext(),
+			PolicyInstance: req.GetPolicyInstance(),
+		},
+		User: &api.DecisionUser{
+			Context: req.GetIdentityContext(),
+			Id:      getID(input),
+			Email:   getEmail(input),
+		},
+		TenantId:    getTenantID(ctx),
+		Resource:    req.GetResourceContext(),
+		Annotations: evaluation.Annotations(),
+	}
+
+	return dlPlugin.Log(ctx, &d)
+}
+
+// inputDigest returns the hex encoded SHA-256 digest of the JSON encoded input.
+func inputDigest(input map[string]interface{}) (string, error) {
+	buf, err := json.Marshal(input)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal input")
+	}
+
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// querySummary summarizes a query result set as the number of results and the names of their bindings.
+func querySummary(results rego.ResultSet) *decisionlog.Result {
+	names := map[string]bool{}
+	for _, result := range results {
+		for name := range result.Bindings {
+			names[name] = true
+		}
+	}
+
+	return &decisionlog.Result{Results: len(results), Bindings: sortedKeys(names)}
+}
+
+// decisionTreeSummary summarizes a decision tree as the number of paths and, per decision, the number of paths where it is true.
+func decisionTreeSummary(decisions []string, paths map[string]interface{}) *decisionlog.Result {
+	counts := map[string]int{}
+	for _, decision := range decisions {
+		counts[decision] = 0
+	}
+
+	for _, v := range paths {
+		outcomes, _ := v.(map[string]interface{})
+		for _, decision := range decisions {
+			if outcome, ok := outcomes[decision].(bool); ok && outcome {
+				counts[decision]++
+			}
+		}
+	}
+
+	return &decisionlog.Result{Results: len(paths), Decisions: counts}
+}
+
+// compileSummary summarizes a partial evaluation result as the number of queries and support modules.
+func compileSummary(result *runtime.CompileResult) *decisionlog.Result {
+	partial, _ := (*result.Result).(types.PartialEvaluationResultV1)
+
+	return &decisionlog.Result{Results: len(partial.Queries), Support: len(partial.Support)}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
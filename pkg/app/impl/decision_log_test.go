@@ -0,0 +1,123 @@
+package impl
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	runtime "github.com/aserto-dev/runtime"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	decisionlog_plugin "github.com/aserto-dev/topaz/plugins/decision_log"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDecisionLogger collects the logged decisions, onLog is called for each decision when it is set.
+type testDecisionLogger struct {
+	mu        sync.Mutex
+	decisions []*api.Decision
+	onLog     func(d *api.Decision)
+}
+
+func (l *testDecisionLogger) Log(d *api.Decision) error {
+	if l.onLog != nil {
+		l.onLog(d)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.decisions = append(l.decisions, d)
+	return nil
+}
+
+func (l *testDecisionLogger) Shutdown() {}
+
+func (l *testDecisionLogger) last(t *testing.T) *api.Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	require.NotEmpty(t, l.decisions)
+	return l.decisions[len(l.decisions)-1]
+}
+
+// newAuditedRuntime returns a runtime whose decision log records the calls of the given APIs.
+func newAuditedRuntime(t *testing.T, dl *testDecisionLogger, apis ...string) *runtime.Runtime {
+	ctx := context.Background()
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+
+	rt, cleanup, err := runtime.NewRuntime(ctx, &logger,
+		&runtime.Config{
+			InstanceID: "test",
+			Config: runtime.OPAConfig{
+				Plugins: map[string]interface{}{decisionlog_plugin.PluginName: map[string]interface{}{"enabled": true}},
+			},
+		},
+		runtime.WithPlugin(decisionlog_plugin.PluginName, decisionlog_plugin.NewFactory(dl, nil, apis)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	require.NoError(t, rt.Start(ctx))
+
+	return rt
+}
+
+func TestLogQueryEvaluation(t *testing.T) {
+	dl := &testDecisionLogger{}
+	rt := newAuditedRuntime(t, dl, decisionlog.APIQuery, decisionlog.APIDecisionTree)
+	upsertPolicy(t, rt, "package test\n\nallowed = true\n\nvisible = false\n")
+
+	s := newTestServer(t, &config.Common{}, map[string]*runtime.Runtime{"": rt})
+	identity := &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_NONE}
+
+	_, err := s.Query(context.Background(), &authorizer.QueryRequest{
+		Query:           "x = data.test.allowed; y = data.test.visible",
+		IdentityContext: identity,
+	})
+	require.NoError(t, err)
+
+	d := dl.last(t)
+	assert.Empty(t, d.Outcomes)
+
+	evaluation, err := decisionlog.EvaluationFromAnnotations(d.Annotations)
+	require.NoError(t, err)
+	assert.Equal(t, decisionlog.APIQuery, evaluation.API)
+	assert.Equal(t, "x = data.test.allowed; y = data.test.visible", evaluation.Query)
+	assert.Len(t, evaluation.InputDigest, 64)
+	assert.Equal(t, &decisionlog.Result{Results: 1, Bindings: []string{"x", "y"}}, evaluation.Result)
+
+	_, err = s.DecisionTree(context.Background(), &authorizer.DecisionTreeRequest{
+		PolicyContext:   &api.PolicyContext{Path: "test", Decisions: []string{"allowed", "visible"}},
+		IdentityContext: identity,
+	})
+	require.NoError(t, err)
+
+	d = dl.last(t)
+	evaluation, err = decisionlog.EvaluationFromAnnotations(d.Annotations)
+	require.NoError(t, err)
+	assert.Equal(t, decisionlog.APIDecisionTree, evaluation.API)
+	assert.Equal(t, "test", d.Path)
+	assert.Equal(t, &decisionlog.Result{Results: 1, Decisions: map[string]int{"allowed": 1, "visible": 0}}, evaluation.Result)
+}
+
+func TestLogEvaluationNotAudited(t *testing.T) {
+	dl := &testDecisionLogger{}
+	rt := newAuditedRuntime(t, dl, decisionlog.APIIs)
+	upsertPolicy(t, rt, "package test\n\nallowed = true\n")
+
+	s := newTestServer(t, &config.Common{}, map[string]*runtime.Runtime{"": rt})
+
+	_, err := s.Query(context.Background(), &authorizer.QueryRequest{
+		Query:           "x = data.test.allowed",
+		IdentityContext: &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_NONE},
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, dl.decisions, "query logged by a decision log auditing is only")
+}
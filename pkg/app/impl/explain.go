@@ -0,0 +1,208 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/server/types"
+	"github.com/open-policy-agent/opa/topdown"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// explanation is the provenance of the decisions of an Is request.
+type explanation struct {
+	// Decisions holds the rule bodies of each decision.
+	Decisions map[string]*decisionExplanation `json:"decisions"`
+	// Rules holds the bodies of the other rules evaluated for the decisions.
+	Rules []*ruleExplanation `json:"rules,omitempty"`
+	// Calls holds the directory checks made outside of the reported rule bodies.
+	Calls []*ds.Call `json:"calls,omitempty"`
+	// Notes holds the messages of the trace builtin.
+	Notes []string `json:"notes,omitempty"`
+}
+
+type decisionExplanation struct {
+	Outcome bool               `json:"outcome"`
+	Rules   []*ruleExplanation `json:"rules,omitempty"`
+}
+
+// ruleExplanation is an evaluation of a rule body.
+type ruleExplanation struct {
+	Rule     string `json:"rule"`
+	Location string `json:"location,omitempty"`
+	Default  bool   `json:"default,omitempty"`
+	Result   bool   `json:"result"`
+	// FailedExpression is the last expression that failed in a rule body that evaluated false.
+	FailedExpression *expressionExplanation `json:"failed_expression,omitempty"`
+	Calls            []*ds.Call             `json:"calls,omitempty"`
+}
+
+type expressionExplanation struct {
+	Expression string `json:"expression"`
+	Location   string `json:"location,omitempty"`
+}
+
+// IsExplain evaluates an Is request with tracing enabled and explains its decisions.
+func (s *AuthorizerServer) IsExplain(ctx context.Context, req *azv1.IsExplainRequest) (*azv1.IsExplainResponse, error) {
+	resp := &azv1.IsExplainResponse{}
+
+	if req.Request == nil {
+		return resp, aerr.ErrInvalidArgument.Msg("request not set")
+	}
+
+	mode := TraceLevelToExplainModeV2(req.Trace)
+	if mode == types.ExplainOffV1 {
+		mode = types.ExplainFullV1
+	}
+
+	tracer := topdown.NewBufferTracer()
+
+	result, err := s.evalIs(ctx, req.Request, rego.EvalQueryTracer(tracer))
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Decisions = result.Decisions
+
+	e := explain(*tracer, req.Request.PolicyContext.Path, result.Decisions, mode)
+
+	if resp.Explanation, err = toStruct(e); err != nil {
+		return resp, aerr.ErrBadQuery.Err(err).Msg("failed to encode explanation")
+	}
+
+	return resp, nil
+}
+
+// explain builds the explanation of the decisions from the trace events of their evaluation.
+//
+// Rule bodies are identified by the query id of their enter event, events of nested queries
+// (negations, comprehensions) are attributed to the closest enclosing rule body.
+func explain(events []*topdown.Event, path string, decisions []*authorizer.Decision, mode types.ExplainModeV1) *explanation {
+	e := &explanation{
+		Decisions: map[string]*decisionExplanation{},
+	}
+
+	decisionRules := map[string]*decisionExplanation{}
+	for _, d := range decisions {
+		de := &decisionExplanation{Outcome: d.Is}
+		e.Decisions[d.Decision] = de
+		decisionRules[fmt.Sprintf("data.%s.%s", path, d.Decision)] = de
+	}
+
+	bodies := map[uint64]*ruleExplanation{}
+	parents := map[uint64]uint64{}
+	order := []*ruleExplanation{}
+
+	body := func(queryID uint64) *ruleExplanation {
+		for id, seen := queryID, map[uint64]bool{}; !seen[id]; id = parents[id] {
+			if r, ok := bodies[id]; ok {
+				return r
+			}
+			seen[id] = true
+		}
+		return nil
+	}
+
+	for _, evt := range events {
+		if _, ok := parents[evt.QueryID]; !ok {
+			parents[evt.QueryID] = evt.ParentID
+		}
+
+		switch node := evt.Node.(type) {
+		case *ast.Rule:
+			switch evt.Op {
+			case topdown.EnterOp:
+				r := &ruleExplanation{
+					Rule:     ruleName(node),
+					Location: location(evt.Location),
+					Default:  node.Default,
+				}
+				bodies[evt.QueryID] = r
+				order = append(order, r)
+			case topdown.ExitOp:
+				if r, ok := bodies[evt.QueryID]; ok {
+					r.Result, r.FailedExpression = true, nil
+				}
+			}
+		case *ast.Expr:
+			if evt.Op == topdown.FailOp {
+				if r := body(evt.QueryID); r != nil && !r.Result {
+					r.FailedExpression = &expressionExplanation{
+						Expression: node.String(),
+						Location:   location(evt.Location),
+					}
+				}
+			}
+		}
+
+		if evt.Op != topdown.NoteOp {
+			continue
+		}
+
+		call, ok := ds.CallFromEvent(evt)
+		if !ok {
+			e.Notes = append(e.Notes, evt.Message)
+			continue
+		}
+
+		if r := body(evt.QueryID); r != nil && mode != types.ExplainNotesV1 {
+			r.Calls = append(r.Calls, call)
+		} else {
+			e.Calls = append(e.Calls, call)
+		}
+	}
+
+	for _, r := range order {
+		switch {
+		case mode == types.ExplainNotesV1:
+			continue
+		case mode == types.ExplainFailsV1 && r.Result:
+			// the directory checks of rule bodies left out are still reported.
+			e.Calls = append(e.Calls, r.Calls...)
+			continue
+		}
+
+		if de, ok := decisionRules[r.Rule]; ok {
+			de.Rules = append(de.Rules, r)
+		} else {
+			e.Rules = append(e.Rules, r)
+		}
+	}
+
+	return e
+}
+
+func ruleName(rule *ast.Rule) string {
+	if rule.Module == nil {
+		return rule.Head.Ref().String()
+	}
+	return rule.Ref().String()
+}
+
+func location(loc *ast.Location) string {
+	if loc == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", loc.File, loc.Row)
+}
+
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, err
+	}
+
+	return structpb.NewStruct(m)
+}
@@ -0,0 +1,153 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/server/types"
+	"github.com/open-policy-agent/opa/topdown"
+	otypes "github.com/open-policy-agent/opa/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const explainPolicy = `package test
+
+default allowed = false
+
+allowed {
+	input.id == "1"
+	input.level > 2
+	test.check("member")
+}
+
+allowed {
+	input.id == "2"
+}
+
+visible {
+	trace("visible")
+}
+`
+
+// testCheck traces a directory call, as the ds builtins do, and returns true.
+var testCheck = rego.Function1(
+	&rego.Function{Name: "test.check", Decl: otypes.NewFunction(otypes.Args(otypes.S), otypes.B)},
+	func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		buf, err := json.Marshal(ds.Call{Builtin: "ds.check", Args: map[string]interface{}{"relation": op1.Value.String()}, Result: true})
+		if err != nil {
+			return nil, err
+		}
+
+		if bctx.TraceEnabled {
+			bctx.QueryTracers[0].TraceEvent(topdown.Event{
+				Op:       topdown.NoteOp,
+				Message:  ds.CallNotePrefix + string(buf),
+				QueryID:  bctx.QueryID,
+				ParentID: bctx.ParentID,
+			})
+		}
+
+		return ast.BooleanTerm(true), nil
+	},
+)
+
+// traceDecisions evaluates the allowed and visible decisions of the explain policy.
+func traceDecisions(t *testing.T, id string, level int) ([]*topdown.Event, []*authorizer.Decision) {
+	tracer := topdown.NewBufferTracer()
+
+	rs, err := rego.New(
+		rego.Query("allowed = data.test.allowed; visible = data.test.visible"),
+		rego.Module("policy.rego", explainPolicy),
+		rego.Input(map[string]interface{}{"id": id, "level": level}),
+		rego.QueryTracer(tracer),
+		testCheck,
+	).Eval(context.Background())
+	require.NoError(t, err)
+
+	decisions := []*authorizer.Decision{{Decision: "allowed"}, {Decision: "visible"}}
+	if len(rs) == 1 {
+		decisions[0].Is, _ = rs[0].Bindings["allowed"].(bool)
+		decisions[1].Is, _ = rs[0].Bindings["visible"].(bool)
+	}
+
+	return *tracer, decisions
+}
+
+// bodyAt returns the explanation of the rule body at the line of the policy.
+func bodyAt(rules []*ruleExplanation, row string) *ruleExplanation {
+	for _, r := range rules {
+		if r.Location == "policy.rego:"+row {
+			return r
+		}
+	}
+	return nil
+}
+
+func TestExplain(t *testing.T) {
+	events, decisions := traceDecisions(t, "1", 3)
+	e := explain(events, "test", decisions, types.ExplainFullV1)
+
+	require.Contains(t, e.Decisions, "allowed")
+	allowed := e.Decisions["allowed"]
+	assert.True(t, allowed.Outcome)
+
+	first := bodyAt(allowed.Rules, "5")
+	require.NotNil(t, first, "first body of allowed not reported")
+	assert.Equal(t, "data.test.allowed", first.Rule)
+	assert.True(t, first.Result)
+	assert.Nil(t, first.FailedExpression)
+
+	// the directory call is attributed to the rule body making it.
+	require.Len(t, first.Calls, 1)
+	assert.Equal(t, "ds.check", first.Calls[0].Builtin)
+	assert.Equal(t, true, first.Calls[0].Result)
+	assert.Empty(t, e.Calls)
+
+	// trace messages are reported as notes.
+	assert.Equal(t, []string{"visible"}, e.Notes)
+	require.Contains(t, e.Decisions, "visible")
+	assert.True(t, e.Decisions["visible"].Outcome)
+	assert.NotNil(t, bodyAt(e.Decisions["visible"].Rules, "15"))
+}
+
+func TestExplainFailedExpression(t *testing.T) {
+	events, decisions := traceDecisions(t, "1", 1)
+	e := explain(events, "test", decisions, types.ExplainFullV1)
+
+	allowed := e.Decisions["allowed"]
+	assert.False(t, allowed.Outcome)
+
+	body := bodyAt(allowed.Rules, "5")
+	require.NotNil(t, body, "first body of allowed not reported")
+	assert.False(t, body.Result)
+	require.NotNil(t, body.FailedExpression)
+	// the expression is reported as compiled, its location points to the policy.
+	assert.Contains(t, body.FailedExpression.Expression, "gt(")
+	assert.Equal(t, "policy.rego:7", body.FailedExpression.Location)
+	assert.Empty(t, body.Calls, "directory call made after the failed expression")
+	assert.Empty(t, e.Calls)
+}
+
+func TestExplainModes(t *testing.T) {
+	events, decisions := traceDecisions(t, "1", 3)
+
+	// fails mode leaves out the rule bodies that succeeded, but still reports their directory calls.
+	e := explain(events, "test", decisions, types.ExplainFailsV1)
+	assert.Nil(t, bodyAt(e.Decisions["allowed"].Rules, "5"))
+	require.Len(t, e.Calls, 1)
+	assert.Equal(t, "ds.check", e.Calls[0].Builtin)
+
+	// notes mode only reports the notes and directory calls.
+	e = explain(events, "test", decisions, types.ExplainNotesV1)
+	assert.Empty(t, e.Decisions["allowed"].Rules)
+	assert.Empty(t, e.Rules)
+	assert.Equal(t, []string{"visible"}, e.Notes)
+	require.Len(t, e.Calls, 1)
+	assert.Equal(t, "ds.check", e.Calls[0].Builtin)
+}
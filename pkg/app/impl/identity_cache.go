@@ -0,0 +1,180 @@
+package impl
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	"github.com/aserto-dev/go-directory/pkg/derr"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Results of identity cache lookups reported in the identity cache metrics.
+const (
+	IdentityCacheHit         = "hit"
+	IdentityCacheNegativeHit = "negative_hit"
+	IdentityCacheMiss        = "miss"
+)
+
+var (
+	keyIdentityCacheResult = tag.MustNewKey("result")
+
+	measureIdentityCacheLookups = stats.Int64("topaz/authorizer/identity_cache_lookups", "Number of identity cache lookups", stats.UnitDimensionless)
+
+	// IdentityCacheViews exports the identity cache lookup counters, by result (hit, negative_hit or miss).
+	IdentityCacheViews = []*view.View{
+		{
+			Name:        "topaz/authorizer/identity_cache_lookups",
+			Measure:     measureIdentityCacheLookups,
+			Description: measureIdentityCacheLookups.Description(),
+			TagKeys:     []tag.Key{keyIdentityCacheResult},
+			Aggregation: view.Sum(),
+		},
+	}
+)
+
+// identityCache caches the users resolved from identities, and the identities without user.
+//
+// The least recently used identities are evicted once the cache is full, and all identities are dropped
+// when the edge directory changes. A resolution started before the cache is dropped is not cached.
+type identityCache struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+	maxSize     int
+
+	mu         sync.Mutex
+	generation uint64
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type identityEntry struct {
+	identity string
+	user     proto.Message
+	err      error
+	expires  time.Time
+}
+
+func newIdentityCache(cfg *config.IdentityCacheConfig) *identityCache {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	maxSize := cfg.MaxSize
+	if maxSize == 0 {
+		maxSize = config.DefaultIdentityCacheSize
+	}
+
+	return &identityCache{
+		ttl:         time.Duration(cfg.TTLSeconds) * time.Second,
+		negativeTTL: time.Duration(cfg.NegativeTTLSeconds) * time.Second,
+		maxSize:     maxSize,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+	}
+}
+
+// resolve returns the cached user of the identity, resolving and caching it on a miss unless the resolution is not cacheable.
+// Identities without user are cached when negative caching is enabled, other resolution errors are not cached.
+func (c *identityCache) resolve(ctx context.Context, identity string, resolve func() (proto.Message, bool, error)) (proto.Message, error) {
+	entry, generation, ok := c.get(identity)
+	if ok {
+		if entry.err != nil {
+			recordIdentityCache(ctx, IdentityCacheNegativeHit)
+			return nil, entry.err
+		}
+
+		recordIdentityCache(ctx, IdentityCacheHit)
+		return proto.Clone(entry.user), nil
+	}
+
+	recordIdentityCache(ctx, IdentityCacheMiss)
+
+	user, cacheable, err := resolve()
+	switch {
+	case !cacheable:
+	case err == nil:
+		c.put(generation, &identityEntry{identity: identity, user: proto.Clone(user), expires: time.Now().Add(c.ttl)})
+	case c.negativeTTL > 0 && isNotFound(err):
+		c.put(generation, &identityEntry{identity: identity, err: err, expires: time.Now().Add(c.negativeTTL)})
+	}
+
+	return user, err
+}
+
+func (c *identityCache) get(identity string) (*identityEntry, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[identity]
+	if !ok {
+		return nil, c.generation, false
+	}
+
+	entry := elem.Value.(*identityEntry)
+	if time.Now().After(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, identity)
+		return nil, c.generation, false
+	}
+
+	c.lru.MoveToFront(elem)
+
+	return entry, c.generation, true
+}
+
+func (c *identityCache) put(generation uint64, entry *identityEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the cache was dropped while the identity was resolved.
+	if generation != c.generation {
+		return
+	}
+
+	if elem, ok := c.entries[entry.identity]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	for c.lru.Len() >= c.maxSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*identityEntry).identity)
+	}
+
+	c.entries[entry.identity] = c.lru.PushFront(entry)
+}
+
+// invalidate drops all cached identities.
+func (c *identityCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+// isNotFound returns true when the error reports an identity without user.
+func isNotFound(err error) bool {
+	if cerr.Equals(err, aerr.ErrDirectoryObjectNotFound) || cerr.Equals(err, derr.ErrNotFound) {
+		return true
+	}
+
+	return status.Code(err) == codes.NotFound
+}
+
+func recordIdentityCache(ctx context.Context, result string) {
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(keyIdentityCacheResult, result)}, measureIdentityCacheLookups.M(1))
+}
@@ -0,0 +1,175 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// countingResolver resolves identities to users named after them, it counts its calls.
+type countingResolver struct {
+	calls int
+	err   error
+}
+
+func (r *countingResolver) resolve(identity string) func() (proto.Message, bool, error) {
+	return func() (proto.Message, bool, error) {
+		r.calls++
+		if r.err != nil {
+			return nil, true, r.err
+		}
+		return wrapperspb.String("user-" + identity), true, nil
+	}
+}
+
+func TestIdentityCacheHit(t *testing.T) {
+	ctx := context.Background()
+	c := newIdentityCache(&config.IdentityCacheConfig{TTLSeconds: 60})
+	r := &countingResolver{}
+
+	first, err := c.resolve(ctx, "alice", r.resolve("alice"))
+	require.NoError(t, err)
+	second, err := c.resolve(ctx, "alice", r.resolve("alice"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, r.calls)
+	assert.True(t, proto.Equal(first, second))
+	assert.NotSame(t, first, second, "cached user returned without copy")
+
+	_, err = c.resolve(ctx, "bob", r.resolve("bob"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.calls)
+}
+
+func TestIdentityCacheDisabled(t *testing.T) {
+	assert.Nil(t, newIdentityCache(&config.IdentityCacheConfig{}))
+}
+
+func TestIdentityCacheNegativeHit(t *testing.T) {
+	ctx := context.Background()
+	notFound := &countingResolver{err: aerr.ErrDirectoryObjectNotFound}
+
+	// identities without user are not cached without negative ttl.
+	c := newIdentityCache(&config.IdentityCacheConfig{TTLSeconds: 60})
+	for i := 0; i < 2; i++ {
+		_, err := c.resolve(ctx, "alice", notFound.resolve("alice"))
+		require.Error(t, err)
+	}
+	assert.Equal(t, 2, notFound.calls)
+
+	notFound.calls = 0
+	c = newIdentityCache(&config.IdentityCacheConfig{TTLSeconds: 60, NegativeTTLSeconds: 10})
+	for i := 0; i < 2; i++ {
+		_, err := c.resolve(ctx, "alice", notFound.resolve("alice"))
+		assert.ErrorIs(t, err, aerr.ErrDirectoryObjectNotFound)
+	}
+	assert.Equal(t, 1, notFound.calls)
+
+	// other errors are never cached.
+	failing := &countingResolver{err: errors.New("directory unavailable")}
+	for i := 0; i < 2; i++ {
+		_, err := c.resolve(ctx, "bob", failing.resolve("bob"))
+		require.Error(t, err)
+	}
+	assert.Equal(t, 2, failing.calls)
+}
+
+func TestIdentityCacheNotCacheable(t *testing.T) {
+	ctx := context.Background()
+	c := newIdentityCache(&config.IdentityCacheConfig{TTLSeconds: 60})
+
+	calls := 0
+	resolve := func() (proto.Message, bool, error) {
+		calls++
+		return wrapperspb.String("user"), false, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := c.resolve(ctx, "alice", resolve)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdentityCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	c := newIdentityCache(&config.IdentityCacheConfig{TTLSeconds: 60, NegativeTTLSeconds: 60})
+	c.ttl = 10 * time.Millisecond
+	c.negativeTTL = 10 * time.Millisecond
+
+	r := &countingResolver{}
+	notFound := &countingResolver{err: aerr.ErrDirectoryObjectNotFound}
+
+	_, err := c.resolve(ctx, "alice", r.resolve("alice"))
+	require.NoError(t, err)
+	_, err = c.resolve(ctx, "bob", notFound.resolve("bob"))
+	require.Error(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = c.resolve(ctx, "alice", r.resolve("alice"))
+	require.NoError(t, err)
+	_, err = c.resolve(ctx, "bob", notFound.resolve("bob"))
+	require.Error(t, err)
+
+	assert.Equal(t, 2, r.calls)
+	assert.Equal(t, 2, notFound.calls)
+}
+
+func TestIdentityCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	c := newIdentityCache(&config.IdentityCacheConfig{TTLSeconds: 60})
+	r := &countingResolver{}
+
+	_, err := c.resolve(ctx, "alice", r.resolve("alice"))
+	require.NoError(t, err)
+
+	c.invalidate()
+
+	_, err = c.resolve(ctx, "alice", r.resolve("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.calls)
+
+	// a resolution started before the cache is dropped is not cached.
+	_, err = c.resolve(ctx, "bob", func() (proto.Message, bool, error) {
+		c.invalidate()
+		return wrapperspb.String("user-bob"), true, nil
+	})
+	require.NoError(t, err)
+
+	_, err = c.resolve(ctx, "bob", r.resolve("bob"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.calls)
+}
+
+func TestIdentityCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := newIdentityCache(&config.IdentityCacheConfig{TTLSeconds: 60, MaxSize: 2})
+	r := &countingResolver{}
+
+	for _, identity := range []string{"alice", "bob", "alice", "carol"} {
+		_, err := c.resolve(ctx, identity, r.resolve(identity))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, r.calls)
+
+	// bob is the least recently used identity, evicted for carol.
+	_, err := c.resolve(ctx, "alice", r.resolve("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.calls)
+
+	_, err = c.resolve(ctx, "bob", r.resolve("bob"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, r.calls)
+
+	assert.Equal(t, 2, c.lru.Len())
+	assert.Len(t, c.entries, 2)
+}
@@ -0,0 +1,200 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	dsr3 "github.com/aserto-dev/go-directory/aserto/directory/reader/v3"
+	"github.com/aserto-dev/topaz/directory"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"sigs.k8s.io/yaml"
+)
+
+// identityResolver resolves the user of an identity, it returns a not found error when the identity has no user.
+type identityResolver interface {
+	resolve(ctx context.Context, identity string, claims map[string]interface{}) (proto.Message, error)
+}
+
+// identityResolvers is the ordered chain of identity resolvers, an identity is resolved by the first resolver finding its user.
+type identityResolvers []identityResolver
+
+func newIdentityResolvers(cfgs []config.IdentityResolverConfig, rf *resolvers.Resolvers) (identityResolvers, error) {
+	if len(cfgs) == 0 {
+		cfgs = config.DefaultIdentityResolvers()
+	}
+
+	chain := make(identityResolvers, 0, len(cfgs))
+	for i := range cfgs {
+		cfg := &cfgs[i]
+
+		switch cfg.Type {
+		case config.IdentityResolverRelation:
+			chain = append(chain, &relationResolver{resolver: rf, objectType: cfg.ObjectType, relation: cfg.Relation, subjectType: cfg.SubjectType})
+		case config.IdentityResolverObject:
+			chain = append(chain, &objectResolver{resolver: rf, objectType: cfg.ObjectType})
+		case config.IdentityResolverClaims:
+			chain = append(chain, &claimsResolver{objectType: cfg.ObjectType, idClaim: cfg.IDClaim})
+		case config.IdentityResolverStatic:
+			r, err := newStaticResolver(cfg.Path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "identity_resolvers[%d]", i)
+			}
+			chain = append(chain, r)
+		default:
+			return nil, errors.Errorf("identity_resolvers[%d] - unknown type %q", i, cfg.Type)
+		}
+	}
+
+	return chain, nil
+}
+
+// resolve returns the user of the first resolver finding the identity, or the not found error of the last resolver.
+// Resolutions reaching the claims resolver depend on the identity type and are not cacheable.
+func (c identityResolvers) resolve(ctx context.Context, identity string, claims map[string]interface{}) (proto.Message, bool, error) {
+	cacheable := true
+
+	var err error
+	for _, r := range c {
+		if _, ok := r.(*claimsResolver); ok {
+			cacheable = false
+		}
+
+		var user proto.Message
+		user, err = r.resolve(ctx, identity, claims)
+		if err == nil || !isNotFound(err) {
+			return user, cacheable, err
+		}
+	}
+
+	return nil, cacheable, err
+}
+
+// relationResolver resolves the subject of the relation of the identity object.
+type relationResolver struct {
+	resolver    *resolvers.Resolvers
+	objectType  string
+	relation    string
+	subjectType string
+}
+
+func (r *relationResolver) resolve(ctx context.Context, identity string, _ map[string]interface{}) (proto.Message, error) {
+	client, err := r.resolver.GetDirectoryResolver().GetDS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := directory.GetIdentitySubject(ctx, client, r.objectType, r.relation, r.subjectType, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return addObjectKey(user)
+}
+
+// objectResolver resolves the object whose id is the identity.
+type objectResolver struct {
+	resolver   *resolvers.Resolvers
+	objectType string
+}
+
+func (r *objectResolver) resolve(ctx context.Context, identity string, _ map[string]interface{}) (proto.Message, error) {
+	client, err := r.resolver.GetDirectoryResolver().GetDS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objResp, err := client.GetObject(ctx, &dsr3.GetObjectRequest{
+		ObjectType: r.objectType,
+		ObjectId:   identity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return addObjectKey(objResp.Result)
+}
+
+// claimsResolver resolves a user built from the validated claims of JWT identities, without directory lookup.
+// The user id is the value of the id claim and its properties are the token claims.
+type claimsResolver struct {
+	objectType string
+	idClaim    string
+}
+
+func (r *claimsResolver) resolve(_ context.Context, identity string, claims map[string]interface{}) (proto.Message, error) {
+	if claims == nil {
+		return nil, aerr.ErrDirectoryObjectNotFound.Msgf("identity %q has no token claims", identity)
+	}
+
+	id, _ := claims[r.idClaim].(string)
+	if id == "" {
+		return nil, aerr.ErrDirectoryObjectNotFound.Msgf("token has no %q claim", r.idClaim)
+	}
+
+	buf, err := json.Marshal(map[string]interface{}{
+		"id":         id,
+		"key":        id,
+		"type":       r.objectType,
+		"properties": claims,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal token claims")
+	}
+
+	user := &structpb.Struct{}
+	if err := protojson.Unmarshal(buf, user); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal token claims")
+	}
+
+	return user, nil
+}
+
+// staticResolver resolves the users of a static users file, a map of identities to user objects.
+type staticResolver struct {
+	users map[string]*structpb.Struct
+}
+
+func newStaticResolver(path string) (*staticResolver, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read users file")
+	}
+
+	objects := map[string]map[string]interface{}{}
+	if err := yaml.Unmarshal(buf, &objects); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse users file %s", path)
+	}
+
+	users := make(map[string]*structpb.Struct, len(objects))
+	for identity, object := range objects {
+		id, _ := object["id"].(string)
+		if id == "" {
+			return nil, errors.Errorf("users file %s - user of identity %q has no id", path, identity)
+		}
+		object["key"] = id
+
+		user, err := structpb.NewStruct(object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "users file %s - invalid user of identity %q", path, identity)
+		}
+		users[identity] = user
+	}
+
+	return &staticResolver{users: users}, nil
+}
+
+func (r *staticResolver) resolve(_ context.Context, identity string, _ map[string]interface{}) (proto.Message, error) {
+	user, ok := r.users[identity]
+	if !ok {
+		return nil, aerr.ErrDirectoryObjectNotFound.Msgf("identity %q not found in users file", identity)
+	}
+
+	return proto.Clone(user), nil
+}
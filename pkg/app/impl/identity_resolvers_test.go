@@ -0,0 +1,130 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// failingResolver fails every resolution with its error.
+type failingResolver struct {
+	err   error
+	calls int
+}
+
+func (r *failingResolver) resolve(context.Context, string, map[string]interface{}) (proto.Message, error) {
+	r.calls++
+	return nil, r.err
+}
+
+func writeUsersFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func userID(t *testing.T, user proto.Message) string {
+	s, ok := user.(*structpb.Struct)
+	require.True(t, ok, "user is a %T", user)
+	return s.Fields["id"].GetStringValue()
+}
+
+func TestIdentityResolversOrder(t *testing.T) {
+	first := writeUsersFile(t, "alice@acme.com:\n  id: alice\n")
+	second := writeUsersFile(t, "alice@acme.com:\n  id: alice-2\nbob@acme.com:\n  id: bob\n")
+
+	chain, err := newIdentityResolvers([]config.IdentityResolverConfig{
+		{Type: config.IdentityResolverStatic, Path: first},
+		{Type: config.IdentityResolverStatic, Path: second},
+	}, resolvers.New())
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+
+	// the first resolver finding the identity resolves its user.
+	user, cacheable, err := chain.resolve(context.Background(), "alice@acme.com", nil)
+	require.NoError(t, err)
+	assert.True(t, cacheable)
+	assert.Equal(t, "alice", userID(t, user))
+
+	// an identity not found by a resolver falls through to the next one.
+	user, _, err = chain.resolve(context.Background(), "bob@acme.com", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", userID(t, user))
+
+	// the not found error of the last resolver is returned.
+	_, cacheable, err = chain.resolve(context.Background(), "carol@acme.com", nil)
+	require.Error(t, err)
+	assert.True(t, isNotFound(err))
+	assert.True(t, cacheable)
+	assert.Contains(t, err.Error(), "carol@acme.com")
+}
+
+func TestIdentityResolversError(t *testing.T) {
+	unavailable := &failingResolver{err: errors.New("directory unavailable")}
+	notFound := &failingResolver{err: aerr.ErrDirectoryObjectNotFound.Msg("no identity")}
+	last := &failingResolver{err: aerr.ErrDirectoryObjectNotFound.Msg("no user")}
+
+	// errors other than not found end the chain.
+	_, _, err := identityResolvers{notFound, unavailable, last}.resolve(context.Background(), "alice", nil)
+	assert.Equal(t, unavailable.err, err)
+	assert.Equal(t, 1, notFound.calls)
+	assert.Equal(t, 0, last.calls)
+}
+
+func TestIdentityResolversClaims(t *testing.T) {
+	static := writeUsersFile(t, "alice@acme.com:\n  id: alice\n")
+
+	chain, err := newIdentityResolvers([]config.IdentityResolverConfig{
+		{Type: config.IdentityResolverStatic, Path: static},
+		{Type: config.IdentityResolverClaims, ObjectType: "user", IDClaim: "sub"},
+	}, resolvers.New())
+	require.NoError(t, err)
+
+	claims := map[string]interface{}{"sub": "bob", "email": "bob@acme.com"}
+
+	// resolved before the claims resolver, the user does not depend on the token.
+	user, cacheable, err := chain.resolve(context.Background(), "alice@acme.com", claims)
+	require.NoError(t, err)
+	assert.True(t, cacheable)
+	assert.Equal(t, "alice", userID(t, user))
+
+	// resolved from the claims, the user depends on the token and is not cacheable.
+	user, cacheable, err = chain.resolve(context.Background(), "bob@acme.com", claims)
+	require.NoError(t, err)
+	assert.False(t, cacheable)
+	assert.Equal(t, "bob", userID(t, user))
+	props := user.(*structpb.Struct).Fields["properties"].GetStructValue()
+	assert.Equal(t, "bob@acme.com", props.Fields["email"].GetStringValue())
+
+	// identities without claims or id claim are not found, and not cacheable either.
+	for _, c := range []map[string]interface{}{nil, {"email": "bob@acme.com"}} {
+		_, cacheable, err = chain.resolve(context.Background(), "bob@acme.com", c)
+		require.Error(t, err)
+		assert.True(t, isNotFound(err))
+		assert.False(t, cacheable)
+	}
+}
+
+func TestNewIdentityResolvers(t *testing.T) {
+	chain, err := newIdentityResolvers(nil, resolvers.New())
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.IsType(t, &relationResolver{}, chain[0])
+	assert.IsType(t, &objectResolver{}, chain[1])
+
+	_, err = newIdentityResolvers([]config.IdentityResolverConfig{{Type: "ldap"}}, resolvers.New())
+	assert.Error(t, err)
+
+	_, err = newIdentityResolvers([]config.IdentityResolverConfig{{Type: config.IdentityResolverStatic, Path: writeUsersFile(t, "alice: {}\n")}}, resolvers.New())
+	assert.Error(t, err, "user without id")
+}
@@ -8,21 +8,20 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
 	"github.com/aserto-dev/go-authorizer/pkg/aerr"
 	dsc2 "github.com/aserto-dev/go-directory/aserto/directory/common/v2"
 	"github.com/aserto-dev/go-directory/aserto/directory/common/v3"
-	dsr3 "github.com/aserto-dev/go-directory/aserto/directory/reader/v3"
 	"github.com/aserto-dev/go-directory/pkg/pb"
+	"github.com/aserto-dev/topaz/pkg/app/mtls"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
-
-	"github.com/aserto-dev/topaz/directory"
 )
 
 var (
@@ -35,35 +34,35 @@ var (
 )
 
 // getUserFromJWT.
-func (s *AuthorizerServer) getUserFromJWT(ctx context.Context, bearerJWT string) (proto.Message, error) {
+func (s *AuthorizerServer) getUserFromJWT(ctx context.Context, bearerJWT string) (proto.Message, map[string]interface{}, error) {
 	resp := dsc2.Object{}
 
-	ident, err := s.getIdentityFromJWT(ctx, bearerJWT)
+	ident, claims, err := s.getIdentityFromJWT(ctx, bearerJWT)
 	if err != nil {
-		return &resp, err
+		return &resp, nil, err
 	}
 
-	user, err := s.getUserFromIdentity(ctx, ident)
+	user, err := s.getUserFromIdentity(ctx, ident, claims)
 	if err != nil {
-		return &resp, err
+		return &resp, nil, err
 	}
 
-	return user, nil
+	return user, claims, nil
 }
 
-// getIdentityFromJWT.
-func (s *AuthorizerServer) getIdentityFromJWT(ctx context.Context, bearerJWT string) (string, error) {
+// getIdentityFromJWT returns the identity and the validated claims of the token.
+func (s *AuthorizerServer) getIdentityFromJWT(ctx context.Context, bearerJWT string) (string, map[string]interface{}, error) {
 	log := s.logger
 
 	jwtTemp, err := jwt.ParseString(bearerJWT, jwt.WithVerify(false))
 	if err != nil {
 		log.Error().Err(err).Msg("jwt parse without validation")
-		return "", err
+		return "", nil, err
 	}
 
-	options, err := s.jwtParseStringOptions(ctx, jwtTemp)
+	options, err := s.jwtParseStringOptions(ctx, bearerJWT, jwtTemp)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	jwtToken, err := jwt.ParseString(
@@ -72,20 +71,76 @@ func (s *AuthorizerServer) getIdentityFromJWT(ctx context.Context, bearerJWT str
 	)
 	if err != nil {
 		log.Error().Err(err).Msg("jwt parse with validation")
-		return "", err
+		return "", nil, err
 	}
 
 	ident := jwtToken.Subject()
+	if issuer, ok := s.trustedIssuers[jwtToken.Issuer()]; ok && len(issuer.identityClaims) > 0 {
+		if ident, err = identityFromClaims(jwtToken, issuer.identityClaims); err != nil {
+			return "", nil, err
+		}
+	}
+
+	claims, err := tokenClaims(jwtToken)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return ident, claims, nil
+}
+
+// identityFromClaims returns the value of the first identity claim present in the token.
+func identityFromClaims(jwtToken jwt.Token, identityClaims []string) (string, error) {
+	for _, claim := range identityClaims {
+		if value, ok := jwtToken.Get(claim); ok {
+			if ident, ok := value.(string); ok && ident != "" {
+				return ident, nil
+			}
+		}
+	}
+
+	return "", aerr.ErrAuthenticationFailed.Msgf("token does not contain an identity claim [%s]", strings.Join(identityClaims, ", "))
+}
+
+// tokenClaims returns the token claims in their JSON representation.
+func tokenClaims(jwtToken jwt.Token) (map[string]interface{}, error) {
+	buf, err := json.Marshal(jwtToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal token claims")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+
+	claims := map[string]interface{}{}
+	if err := dec.Decode(&claims); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal token claims")
+	}
 
-	return ident, nil
+	return claims, nil
 }
 
-func (s *AuthorizerServer) jwtParseStringOptions(ctx context.Context, jwtToken jwt.Token) ([]jwt.ParseOption, error) {
+func (s *AuthorizerServer) jwtParseStringOptions(ctx context.Context, bearerJWT string, jwtToken jwt.Token) ([]jwt.ParseOption, error) {
 	options := []jwt.ParseOption{
 		jwt.WithValidate(true),
 		jwt.WithAcceptableSkew(time.Duration(s.cfg.JWT.AcceptableTimeSkewSeconds) * time.Second),
 	}
 
+	// when trusted issuers are configured, tokens of other issuers are rejected before any JWKS is fetched.
+	if len(s.trustedIssuers) > 0 {
+		issuer, ok := s.trustedIssuers[jwtToken.Issuer()]
+		if !ok {
+			return nil, aerr.ErrAuthenticationFailed.Msgf("untrusted token issuer %q", jwtToken.Issuer())
+		}
+
+		issuerOptions, err := s.trustedIssuerParseOptions(ctx, issuer, bearerJWT)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(options, issuerOptions...), nil
+	}
+
 	jwtKeysURL, err := s.jwksURLFromCache(ctx, jwtToken.Issuer())
 
 	if err != nil {
@@ -183,83 +238,113 @@ func (s *AuthorizerServer) jwksURL(ctx context.Context, baseURL string) (*url.UR
 	return u, nil
 }
 
-// getUserFromIdentityContext.
-func (s *AuthorizerServer) getUserFromIdentityContext(ctx context.Context, identityContext *api.IdentityContext) (proto.Message, error) {
+// getUserFromIdentityContext returns the user of the identity context,
+// and the validated token claims for JWT identities.
+func (s *AuthorizerServer) getUserFromIdentityContext(ctx context.Context, identityContext *api.IdentityContext) (proto.Message, map[string]interface{}, error) {
 	if identityContext == nil {
-		return nil, aerr.ErrInvalidArgument.Msg("identity context not set")
+		return nil, nil, aerr.ErrInvalidArgument.Msg("identity context not set")
 	}
 
 	// nolint: exhaustive
 	switch identityContext.Type {
 	case api.IdentityType_IDENTITY_TYPE_NONE:
-		return nil, nil
+		return nil, nil, nil
 
 	case api.IdentityType_IDENTITY_TYPE_SUB:
 		if identityContext.Identity == "" {
-			return nil, fmt.Errorf("identity value not set (type: %s)", identityContext.Type.String())
+			return nil, nil, fmt.Errorf("identity value not set (type: %s)", identityContext.Type.String())
 		}
 
-		user, err := s.getUserFromIdentity(ctx, identityContext.Identity)
+		user, err := s.getUserFromIdentity(ctx, identityContext.Identity, nil)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		return user, nil
+		return user, nil, nil
 	case api.IdentityType_IDENTITY_TYPE_JWT:
 		if identityContext.Identity == "" {
-			return nil, fmt.Errorf("identity value not set (type: %s)", identityContext.Type.String())
+			return nil, nil, fmt.Errorf("identity value not set (type: %s)", identityContext.Type.String())
 		}
 
-		user, err := s.getUserFromJWT(ctx, identityContext.Identity)
+		user, claims, err := s.getUserFromJWT(ctx, identityContext.Identity)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		return user, nil
+		return user, claims, nil
 	case api.IdentityType_IDENTITY_TYPE_MANUAL:
 		if identityContext.Identity == "" {
-			return nil, fmt.Errorf("identity value not set (type: %s)", identityContext.Type.String())
+			return nil, nil, fmt.Errorf("identity value not set (type: %s)", identityContext.Type.String())
 		}
 
 		// the resulting user object will be an empty object.
-		return pb.NewStruct(), nil
+		return pb.NewStruct(), nil, nil
+	case mtls.IdentityType:
+		user, err := s.getUserFromCertificate(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return user, nil, nil
 	default:
-		return nil, fmt.Errorf("invalid identity type %s", identityContext.Type.String())
+		return nil, nil, fmt.Errorf("invalid identity type %s", identityContext.Type.String())
 	}
 }
 
-func (s *AuthorizerServer) getUserFromIdentity(ctx context.Context, identity string) (proto.Message, error) {
-	client, err := s.resolver.GetDirectoryResolver().GetDS(ctx)
-	if err != nil {
-		return nil, err
-	}
-	user, err := directory.GetIdentityV2(ctx, client, identity)
-	switch {
-	case errors.Is(err, aerr.ErrDirectoryObjectNotFound):
-		// Try to find a user with key == identity
-		return s.getObject(ctx, "user", identity)
-	case err != nil:
-		return nil, err
-	default:
-		return addObjectKey(user)
+// identityInput returns the identity input of a policy evaluation, the validated
+// token claims of JWT identities are exposed as input.identity.claims.
+func identityInput(identityContext *api.IdentityContext, claims map[string]interface{}) interface{} {
+	ident := convert(identityContext)
+	if m, ok := ident.(map[string]interface{}); ok {
+		if claims != nil {
+			m[IdentityClaims] = claims
+		}
+		if identityContext.GetType() == mtls.IdentityType {
+			m["type"] = mtls.IdentityTypeName
+		}
 	}
+	return ident
 }
 
-func (s *AuthorizerServer) getObject(ctx context.Context, objType, objID string) (proto.Message, error) {
-	client, err := s.resolver.GetDirectoryResolver().GetDS(ctx)
-	if err != nil {
-		return nil, err
+// getUserFromIdentity returns the user of the identity resolved by the identity resolvers, from the identity cache when enabled.
+// The claims are the validated token claims of JWT identities, nil otherwise.
+func (s *AuthorizerServer) getUserFromIdentity(ctx context.Context, identity string, claims map[string]interface{}) (proto.Message, error) {
+	if s.identities == nil {
+		user, _, err := s.identityResolvers.resolve(ctx, identity, claims)
+		return user, err
 	}
 
-	objResp, err := client.GetObject(ctx, &dsr3.GetObjectRequest{
-		ObjectType: objType,
-		ObjectId:   objID,
+	return s.identities.resolve(ctx, identity, func() (proto.Message, bool, error) {
+		return s.identityResolvers.resolve(ctx, identity, claims)
 	})
+}
+
+// getUserFromCertificate returns the user of the client certificate identity of the connection, the object of the mTLS subject type
+// whose id is the identity when set, otherwise the user resolved by the identity resolvers.
+func (s *AuthorizerServer) getUserFromCertificate(ctx context.Context) (proto.Message, error) {
+	if s.certIdentities == nil {
+		return nil, aerr.ErrInvalidArgument.Msg("mTLS identities are not enabled")
+	}
+
+	identity, err := s.certIdentities.FromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return addObjectKey(objResp.Result)
+	if s.cfg.MTLS.SubjectType == "" {
+		return s.getUserFromIdentity(ctx, identity, nil)
+	}
+
+	resolver := &objectResolver{resolver: s.resolver, objectType: s.cfg.MTLS.SubjectType}
+	if s.identities == nil {
+		return resolver.resolve(ctx, identity, nil)
+	}
+
+	// the subject type prefix keeps the certificate identities apart from the identities resolved by the identity resolvers.
+	return s.identities.resolve(ctx, s.cfg.MTLS.SubjectType+":"+identity, func() (proto.Message, bool, error) {
+		user, err := resolver.resolve(ctx, identity, nil)
+		return user, true, err
+	})
 }
 
 func addObjectKey(in *common.Object) (proto.Message, error) {
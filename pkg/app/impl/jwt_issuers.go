@@ -0,0 +1,132 @@
+package impl
+
+import (
+	"context"
+	"os"
+	"slices"
+
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+)
+
+// trustedIssuer holds the validation settings of an issuer listed in the jwt configuration.
+type trustedIssuer struct {
+	issuer         string
+	jwksURL        string
+	keySet         jwk.Set
+	audiences      []string
+	algorithms     map[jwa.SignatureAlgorithm]bool
+	identityClaims []string
+}
+
+// newTrustedIssuers loads the trusted issuers, including their static key sets.
+func newTrustedIssuers(issuers []config.JWTIssuer) (map[string]*trustedIssuer, error) {
+	trusted := make(map[string]*trustedIssuer, len(issuers))
+
+	for _, cfg := range issuers {
+		issuer := &trustedIssuer{
+			issuer:         cfg.Issuer,
+			jwksURL:        cfg.JWKSURL,
+			audiences:      cfg.Audiences,
+			algorithms:     map[jwa.SignatureAlgorithm]bool{},
+			identityClaims: cfg.ClaimMapping.Claims(),
+		}
+
+		for _, alg := range cfg.Algorithms {
+			issuer.algorithms[jwa.SignatureAlgorithm(alg)] = true
+		}
+
+		jwks := []byte(cfg.JWKS)
+		if cfg.JWKSFile != "" {
+			buf, err := os.ReadFile(cfg.JWKSFile)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read jwks file for issuer %q", cfg.Issuer)
+			}
+			jwks = buf
+		}
+
+		if len(jwks) > 0 {
+			keySet, err := jwk.Parse(jwks)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse jwks for issuer %q", cfg.Issuer)
+			}
+			issuer.keySet = keySet
+		}
+
+		trusted[cfg.Issuer] = issuer
+	}
+
+	return trusted, nil
+}
+
+// trustedIssuerParseOptions returns the options validating a token of a trusted issuer.
+// Static key sets are used as is, otherwise the issuer JWKS is fetched from the configured or discovered JWKS URL.
+func (s *AuthorizerServer) trustedIssuerParseOptions(ctx context.Context, issuer *trustedIssuer, bearerJWT string) ([]jwt.ParseOption, error) {
+	if err := issuer.verifyAlgorithm(bearerJWT); err != nil {
+		return nil, err
+	}
+
+	var options []jwt.ParseOption
+
+	if len(issuer.audiences) > 0 {
+		options = append(options, jwt.WithValidator(jwt.ValidatorFunc(issuer.validateAudience)))
+	}
+
+	if issuer.keySet != nil {
+		return append(options, jwt.WithKeySet(issuer.keySet, jws.WithInferAlgorithmFromKey(true))), nil
+	}
+
+	jwksURL := issuer.jwksURL
+	if jwksURL == "" {
+		var err error
+		if jwksURL, err = s.jwksURLFromCache(ctx, issuer.issuer); err != nil {
+			return nil, errors.Wrap(err, "token didn't have a JWKS endpoint we could use for verification")
+		}
+	}
+
+	if err := registerJWKSURL(ctx, s.jwkCache, jwksURL); err != nil {
+		return nil, errors.Wrap(err, "failed to register JWKS URL")
+	}
+
+	jwkSet, err := s.jwkCache.Get(ctx, jwksURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch JWK set for validation")
+	}
+
+	return append(options, jwt.WithKeySet(jwkSet)), nil
+}
+
+// verifyAlgorithm checks the token is signed using one of the allowed algorithms.
+func (i *trustedIssuer) verifyAlgorithm(bearerJWT string) error {
+	if len(i.algorithms) == 0 {
+		return nil
+	}
+
+	msg, err := jws.Parse([]byte(bearerJWT))
+	if err != nil {
+		return err
+	}
+
+	for _, sig := range msg.Signatures() {
+		if alg := sig.ProtectedHeaders().Algorithm(); !i.algorithms[alg] {
+			return aerr.ErrAuthenticationFailed.Msgf("signature algorithm %q not allowed for issuer %q", alg, i.issuer)
+		}
+	}
+
+	return nil
+}
+
+func (i *trustedIssuer) validateAudience(_ context.Context, token jwt.Token) jwt.ValidationError {
+	for _, aud := range token.Audience() {
+		if slices.Contains(i.audiences, aud) {
+			return nil
+		}
+	}
+
+	return jwt.ErrInvalidAudience()
+}
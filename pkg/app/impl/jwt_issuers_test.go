@@ -0,0 +1,144 @@
+package impl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer      = "https://issuer.test"
+	testOtherIssuer = "https://other.test"
+)
+
+func newTestKey(t *testing.T, kid string) jwk.Key {
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	key, err := jwk.FromRaw(raw)
+	require.NoError(t, err)
+	require.NoError(t, key.Set(jwk.KeyIDKey, kid))
+
+	return key
+}
+
+func publicJWKS(t *testing.T, key jwk.Key) string {
+	pub, err := key.PublicKey()
+	require.NoError(t, err)
+	require.NoError(t, pub.Set(jwk.AlgorithmKey, jwa.RS256))
+
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(pub))
+
+	buf, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	return string(buf)
+}
+
+func signToken(t *testing.T, key jwk.Key, alg jwa.SignatureAlgorithm, claims map[string]interface{}) string {
+	token := jwt.New()
+	require.NoError(t, token.Set(jwt.IssuedAtKey, time.Now()))
+	require.NoError(t, token.Set(jwt.ExpirationKey, time.Now().Add(time.Hour)))
+	for name, value := range claims {
+		require.NoError(t, token.Set(name, value))
+	}
+
+	signed, err := jwt.Sign(token, jwt.WithKey(alg, key))
+	require.NoError(t, err)
+
+	return string(signed)
+}
+
+func TestTrustedIssuers(t *testing.T) {
+	key := newTestKey(t, "key")
+	otherKey := newTestKey(t, "other")
+
+	cfg := &config.Common{}
+	cfg.JWT.Issuers = []config.JWTIssuer{
+		{
+			Issuer:     testIssuer,
+			JWKS:       publicJWKS(t, key),
+			Audiences:  []string{"api"},
+			Algorithms: []string{"RS256"},
+		},
+		{
+			Issuer: testOtherIssuer,
+			JWKS:   publicJWKS(t, otherKey),
+		},
+	}
+	s := newTestServer(t, cfg, nil)
+
+	tests := []struct {
+		name     string
+		token    string
+		identity string
+		err      string
+	}{
+		{
+			name:     "trusted issuer",
+			token:    signToken(t, key, jwa.RS256, map[string]interface{}{"iss": testIssuer, "aud": "api", "sub": "s1"}),
+			identity: "s1",
+		},
+		{
+			name:     "one of the audiences",
+			token:    signToken(t, key, jwa.RS256, map[string]interface{}{"iss": testIssuer, "aud": []string{"web", "api"}, "sub": "s1"}),
+			identity: "s1",
+		},
+		{
+			name:  "wrong audience",
+			token: signToken(t, key, jwa.RS256, map[string]interface{}{"iss": testIssuer, "aud": "web", "sub": "s1"}),
+			err:   "aud",
+		},
+		{
+			name:  "no audience",
+			token: signToken(t, key, jwa.RS256, map[string]interface{}{"iss": testIssuer, "sub": "s1"}),
+			err:   "aud",
+		},
+		{
+			name:  "algorithm not allowed",
+			token: signToken(t, key, jwa.RS512, map[string]interface{}{"iss": testIssuer, "aud": "api", "sub": "s1"}),
+			err:   "not allowed",
+		},
+		{
+			name:  "key of another issuer",
+			token: signToken(t, otherKey, jwa.RS256, map[string]interface{}{"iss": testIssuer, "aud": "api", "sub": "s1"}),
+			err:   "key",
+		},
+		{
+			name:  "untrusted issuer",
+			token: signToken(t, key, jwa.RS256, map[string]interface{}{"iss": "https://untrusted.test", "aud": "api", "sub": "s1"}),
+			err:   "untrusted",
+		},
+		{
+			name:     "issuer without audiences",
+			token:    signToken(t, otherKey, jwa.RS256, map[string]interface{}{"iss": testOtherIssuer, "aud": "web", "sub": "s2"}),
+			identity: "s2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			identity, claims, err := s.getIdentityFromJWT(context.Background(), tc.token)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.identity, identity)
+			assert.NotEmpty(t, claims["iss"])
+		})
+	}
+}
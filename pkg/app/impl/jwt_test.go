@@ -0,0 +1,111 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityClaimMapping(t *testing.T) {
+	key := newTestKey(t, "key")
+
+	cfg := &config.Common{}
+	cfg.JWT.Issuers = []config.JWTIssuer{{
+		Issuer: testIssuer,
+		JWKS:   publicJWKS(t, key),
+		ClaimMapping: config.JWTClaimMapping{
+			Identity:  "email",
+			Fallbacks: []string{"preferred_username", "upn"},
+		},
+	}}
+	s := newTestServer(t, cfg, nil)
+
+	tests := []struct {
+		name     string
+		claims   map[string]interface{}
+		identity string
+		err      bool
+	}{
+		{
+			name:     "identity claim",
+			claims:   map[string]interface{}{"sub": "s1", "email": "a@test", "preferred_username": "a"},
+			identity: "a@test",
+		},
+		{
+			name:     "first fallback",
+			claims:   map[string]interface{}{"sub": "s1", "preferred_username": "a", "upn": "a@upn"},
+			identity: "a",
+		},
+		{
+			name:     "second fallback",
+			claims:   map[string]interface{}{"sub": "s1", "upn": "a@upn"},
+			identity: "a@upn",
+		},
+		{
+			name:     "empty and non-string claims are skipped",
+			claims:   map[string]interface{}{"sub": "s1", "email": "", "preferred_username": 42, "upn": "a@upn"},
+			identity: "a@upn",
+		},
+		{
+			name:   "no identity claim",
+			claims: map[string]interface{}{"sub": "s1"},
+			err:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.claims["iss"] = testIssuer
+			identity, _, err := s.getIdentityFromJWT(context.Background(), signToken(t, key, jwa.RS256, tc.claims))
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.identity, identity)
+		})
+	}
+}
+
+func TestIdentityInputClaims(t *testing.T) {
+	key := newTestKey(t, "key")
+
+	cfg := &config.Common{}
+	cfg.JWT.Issuers = []config.JWTIssuer{{Issuer: testIssuer, JWKS: publicJWKS(t, key)}}
+	s := newTestServer(t, cfg, nil)
+
+	token := signToken(t, key, jwa.RS256, map[string]interface{}{
+		"iss":    testIssuer,
+		"sub":    "s1",
+		"groups": []string{"admin", "dev"},
+		"level":  3,
+	})
+
+	_, claims, err := s.getIdentityFromJWT(context.Background(), token)
+	require.NoError(t, err)
+
+	identityContext := &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_JWT, Identity: token}
+	input, ok := identityInput(identityContext, claims).(map[string]interface{})
+	require.True(t, ok)
+
+	assert.Equal(t, "IDENTITY_TYPE_JWT", input["type"])
+	assert.Equal(t, token, input["identity"])
+
+	inputClaims, ok := input[IdentityClaims].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "s1", inputClaims["sub"])
+	assert.Equal(t, []interface{}{"admin", "dev"}, inputClaims["groups"])
+	assert.Equal(t, json.Number("3"), inputClaims["level"], "numeric claims keep their JSON representation")
+
+	// identities of other types have no claims.
+	input, ok = identityInput(&api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_SUB, Identity: "s1"}, nil).(map[string]interface{})
+	require.True(t, ok)
+	assert.NotContains(t, input, IdentityClaims)
+}
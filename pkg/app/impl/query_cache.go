@@ -0,0 +1,117 @@
+package impl
+
+import (
+	"context"
+	"sync"
+
+	runtime "github.com/aserto-dev/runtime"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage"
+)
+
+// preparedQueryCache caches prepared rego queries per runtime.
+//
+// Prepared queries are only valid for the compiler they were planned against,
+// the cached queries of a runtime are dropped when the runtime's plugins manager
+// installs a new compiler (bundle activation or local bundle watch reload).
+type preparedQueryCache struct {
+	mu       sync.RWMutex
+	runtimes map[*runtime.Runtime]*runtimeQueries
+}
+
+type runtimeQueries struct {
+	compiler *ast.Compiler
+	queries  map[string]*rego.PreparedEvalQuery
+}
+
+func newPreparedQueryCache() *preparedQueryCache {
+	return &preparedQueryCache{
+		runtimes: map[*runtime.Runtime]*runtimeQueries{},
+	}
+}
+
+// prepare returns the prepared query for the query statement, planning and caching it
+// when the runtime's current compiler has not seen the query before.
+// Callers holding a store transaction pass it, the query is then planned within that transaction
+// instead of a new one: a reader must not open a second transaction while a bundle activation waits.
+func (c *preparedQueryCache) prepare(ctx context.Context, rt *runtime.Runtime, txn storage.Transaction, queryStmt string) (*rego.PreparedEvalQuery, error) {
+	compiler := rt.GetPluginsManager().GetCompiler()
+
+	if query, ok := c.get(rt, compiler, queryStmt); ok {
+		return query, nil
+	}
+
+	opts := []func(*rego.Rego){
+		rego.Compiler(compiler),
+		rego.Store(rt.GetPluginsManager().Store),
+		rego.Query(queryStmt),
+	}
+	if txn != nil {
+		opts = append(opts, rego.Transaction(txn))
+	}
+
+	query, err := rego.New(opts...).PrepareForEval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(rt, compiler, queryStmt, &query)
+
+	return &query, nil
+}
+
+func (c *preparedQueryCache) get(rt *runtime.Runtime, compiler *ast.Compiler, queryStmt string) (*rego.PreparedEvalQuery, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.runtimes[rt]
+	if !ok || entry.compiler != compiler {
+		return nil, false
+	}
+
+	query, ok := entry.queries[queryStmt]
+	return query, ok
+}
+
+func (c *preparedQueryCache) put(rt *runtime.Runtime, compiler *ast.Compiler, queryStmt string, query *rego.PreparedEvalQuery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.runtimes[rt]
+	if !ok {
+		// first time this runtime is seen, invalidate its entries whenever the compiler is replaced.
+		rt.GetPluginsManager().RegisterCompilerTrigger(func(storage.Transaction) {
+			c.invalidate(rt)
+		})
+	}
+
+	if !ok || entry.compiler != compiler {
+		entry = &runtimeQueries{
+			compiler: compiler,
+			queries:  map[string]*rego.PreparedEvalQuery{},
+		}
+		c.runtimes[rt] = entry
+	}
+
+	entry.queries[queryStmt] = query
+}
+
+// evict drops the runtime and its prepared queries, it is called when the runtime is stopped.
+func (c *preparedQueryCache) evict(rt *runtime.Runtime) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.runtimes, rt)
+}
+
+// invalidate drops all prepared queries of the runtime, the runtime stays registered.
+func (c *preparedQueryCache) invalidate(rt *runtime.Runtime) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.runtimes[rt]; ok {
+		entry.compiler = nil
+		entry.queries = map[string]*rego.PreparedEvalQuery{}
+	}
+}
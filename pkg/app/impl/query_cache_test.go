@@ -0,0 +1,99 @@
+package impl
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	runtime "github.com/aserto-dev/runtime"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRuntime(t *testing.T) *runtime.Runtime {
+	ctx := context.Background()
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+
+	rt, cleanup, err := runtime.NewRuntime(ctx, &logger, &runtime.Config{InstanceID: "test"})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	require.NoError(t, rt.Start(ctx))
+
+	return rt
+}
+
+func upsertPolicy(t *testing.T, rt *runtime.Runtime, module string) {
+	ctx := context.Background()
+	store := rt.GetPluginsManager().Store
+
+	require.NoError(t, storage.Txn(ctx, store, storage.WriteParams, func(txn storage.Transaction) error {
+		return store.UpsertPolicy(ctx, txn, "test.rego", []byte(module))
+	}))
+}
+
+func evalAllowed(t *testing.T, c *preparedQueryCache, rt *runtime.Runtime) bool {
+	ctx := context.Background()
+
+	query, err := c.prepare(ctx, rt, nil, "x = data.test.allowed")
+	require.NoError(t, err)
+
+	rs, err := query.Eval(ctx)
+	require.NoError(t, err)
+	require.Len(t, rs, 1)
+
+	return rs[0].Bindings["x"].(bool)
+}
+
+func TestPreparedQueryCacheCompilerChange(t *testing.T) {
+	ctx := context.Background()
+	rt := newTestRuntime(t)
+	c := newPreparedQueryCache()
+
+	upsertPolicy(t, rt, "package test\n\nallowed = true\n")
+	assert.True(t, evalAllowed(t, c, rt))
+
+	first, err := c.prepare(ctx, rt, nil, "x = data.test.allowed")
+	require.NoError(t, err)
+	second, err := c.prepare(ctx, rt, nil, "x = data.test.allowed")
+	require.NoError(t, err)
+	assert.Same(t, first, second, "query prepared again for the same compiler")
+
+	// the new policy installs a new compiler, the query must be prepared against it.
+	upsertPolicy(t, rt, "package test\n\nallowed = false\n")
+	assert.False(t, evalAllowed(t, c, rt))
+
+	third, err := c.prepare(ctx, rt, nil, "x = data.test.allowed")
+	require.NoError(t, err)
+	assert.NotSame(t, first, third, "query not prepared again after compiler change")
+}
+
+func TestPreparedQueryCacheEvict(t *testing.T) {
+	rt := newTestRuntime(t)
+	c := newPreparedQueryCache()
+	t.Cleanup(resolvers.OnRuntimeStopped(c.evict))
+
+	upsertPolicy(t, rt, "package test\n\nallowed = true\n")
+	assert.True(t, evalAllowed(t, c, rt))
+	assert.Contains(t, c.runtimes, rt)
+
+	resolvers.RuntimeStopped(rt)
+	assert.NotContains(t, c.runtimes, rt)
+}
+
+func TestAuthorizerServerClose(t *testing.T) {
+	rt := newTestRuntime(t)
+	upsertPolicy(t, rt, "package test\n\nallowed = true\n")
+
+	s := newTestServer(t, &config.Common{}, map[string]*runtime.Runtime{"": rt})
+	assert.True(t, evalAllowed(t, s.queryCache, rt))
+
+	// the caches of a closed server are no longer notified of the runtime stops.
+	s.Close()
+	resolvers.RuntimeStopped(rt)
+	assert.Contains(t, s.queryCache.runtimes, rt)
+}
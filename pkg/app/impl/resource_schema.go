@@ -0,0 +1,278 @@
+package impl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	runtime "github.com/aserto-dev/runtime"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/loader"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/topdown"
+	"github.com/open-policy-agent/opa/util"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// schemasRoot is the bundle directory and the data document holding the resource context schemas, keyed by policy path.
+	schemasRoot = "schemas"
+
+	resourceContextField = "resource_context"
+)
+
+// validateResourceContext validates the resource context against the schema of the policy path, when the policy ships one.
+// In warn mode mismatches are logged, in enforce mode they are returned as an invalid argument error holding the field errors.
+//
+// The schema is read within the store transaction of the evaluation, the caller must not open another one.
+func (s *AuthorizerServer) validateResourceContext(
+	ctx context.Context,
+	rt *runtime.Runtime,
+	txn storage.Transaction,
+	path string,
+	resource *structpb.Struct,
+) error {
+	mode := s.cfg.ResourceSchemas.Validation
+	if mode != config.SchemaValidationWarn && mode != config.SchemaValidationEnforce {
+		return nil
+	}
+
+	schema, err := s.schemas.schema(ctx, rt, txn, path)
+	if err != nil {
+		return err
+	}
+
+	if schema.err != nil {
+		return aerr.ErrInvalidPolicy.Err(schema.err).Msgf("invalid schema of %s", path)
+	}
+
+	if schema.term == nil {
+		return nil
+	}
+
+	fields, err := matchSchema(resource.AsMap(), schema.term)
+	if err != nil {
+		return aerr.ErrInvalidPolicy.Err(err).Msgf("invalid schema of %s", path)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if mode == config.SchemaValidationWarn {
+		s.logger.Warn().Str("path", path).Interface("fields", fields).Msg("resource context does not match schema")
+		return nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for field := range fields {
+		keys = append(keys, field)
+	}
+	sort.Strings(keys)
+
+	resourceErr := aerr.ErrInvalidArgument.Msgf("resource context does not match schema of %s", path)
+	for _, field := range keys {
+		resourceErr = resourceErr.Str(field, fields[field])
+	}
+
+	return resourceErr
+}
+
+// matchSchema validates the document against the JSON schema with the json.match_schema builtin,
+// it returns the errors by field, fields are referenced as resource_context or resource_context.<path>.
+func matchSchema(document interface{}, schema *ast.Term) (map[string]string, error) {
+	documentValue, err := ast.InterfaceToValue(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []interface{}
+
+	builtin := topdown.GetBuiltin(ast.JSONMatchSchema.Name)
+	operands := []*ast.Term{ast.NewTerm(documentValue), schema}
+	if err := builtin(topdown.BuiltinContext{}, operands, func(t *ast.Term) error {
+		return ast.As(t.Value, &result)
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(result) != 2 {
+		return nil, errors.New("unexpected json.match_schema result")
+	}
+
+	fields := map[string]string{}
+
+	matchErrors, _ := result[1].([]interface{})
+	for _, e := range matchErrors {
+		matchErr, _ := e.(map[string]interface{})
+		field := schemaErrorField(matchErr)
+		desc, _ := matchErr["desc"].(string)
+
+		if existing, ok := fields[field]; ok {
+			desc = existing + "; " + desc
+		}
+		fields[field] = desc
+	}
+
+	if valid, _ := result[0].(bool); !valid && len(fields) == 0 {
+		fields[resourceContextField] = "does not match schema"
+	}
+
+	return fields, nil
+}
+
+// schemaErrorField returns the document field of a json.match_schema error,
+// errors of missing and additional properties reference the property instead of the object holding it.
+func schemaErrorField(matchErr map[string]interface{}) string {
+	field, _ := matchErr["field"].(string)
+	errType, _ := matchErr["type"].(string)
+	desc, _ := matchErr["desc"].(string)
+
+	path := []string{resourceContextField}
+	if field != "" && !strings.EqualFold(field, "(root)") {
+		path = append(path, field)
+	}
+
+	switch errType {
+	case "required":
+		path = append(path, strings.TrimSuffix(desc, " is required"))
+	case "additional_property_not_allowed":
+		path = append(path, strings.TrimSuffix(strings.TrimPrefix(desc, "Additional property "), " is not allowed"))
+	}
+
+	return strings.Join(path, ".")
+}
+
+// loadSchema reads the schema of the policy path from the schemas/<path>.json file of the runtime's local bundles,
+// or else from the data.schemas["<path>"] document. A policy path without schema has no schema term.
+func loadSchema(ctx context.Context, rt *runtime.Runtime, txn storage.Transaction, path string) (*resourceSchema, error) {
+	var doc interface{}
+
+	buf, err := readSchemaFile(rt, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if buf != nil {
+		if err := util.UnmarshalJSON(buf, &doc); err != nil {
+			return &resourceSchema{err: err}, nil
+		}
+	} else {
+		doc, err = rt.GetPluginsManager().Store.Read(ctx, txn, storage.Path{schemasRoot, path})
+		if storage.IsNotFound(err) {
+			return &resourceSchema{}, nil
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to read schema of %s", path)
+		}
+	}
+
+	value, err := ast.InterfaceToValue(doc)
+	if err != nil {
+		return &resourceSchema{err: err}, nil
+	}
+
+	term := ast.NewTerm(value)
+
+	return &resourceSchema{term: term, err: verifySchema(term)}, nil
+}
+
+// readSchemaFile returns the schemas/<path>.json file of the first local bundle, directory or tarball, shipping it.
+// The OPA bundle reader only keeps the data.json files of downloaded bundles, their schemas are read from the data document.
+func readSchemaFile(rt *runtime.Runtime, path string) ([]byte, error) {
+	if rt.Config == nil || strings.ContainsAny(path, `/\`) {
+		return nil, nil
+	}
+
+	name := schemasRoot + "/" + path + ".json"
+
+	for _, bundlePath := range rt.Config.LocalBundles.Paths {
+		_, bundlePath = loader.SplitPrefix(bundlePath)
+
+		buf, err := readBundleFile(bundlePath, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s of bundle %s", name, bundlePath)
+		}
+
+		if buf != nil {
+			return buf, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// readBundleFile returns the file of the bundle directory or tarball, or nil when the bundle does not ship it.
+func readBundleFile(bundlePath, name string) ([]byte, error) {
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		buf, err := os.ReadFile(bundlePath + "/" + name)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return buf, err
+	}
+
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	files := bundle.NewTarballLoaderWithBaseURL(f, bundlePath)
+	for {
+		desc, err := files.NextFile()
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if strings.TrimPrefix(desc.Path(), "/") != name {
+			_ = desc.Close()
+			continue
+		}
+
+		var buf bytes.Buffer
+		_, err = desc.Read(&buf, bundle.DefaultSizeLimitBytes)
+		_ = desc.Close()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+}
+
+// verifySchema checks the schema with the json.verify_schema builtin, it returns the schema error.
+func verifySchema(schema *ast.Term) error {
+	var result []interface{}
+
+	builtin := topdown.GetBuiltin(ast.JSONSchemaVerify.Name)
+	if err := builtin(topdown.BuiltinContext{}, []*ast.Term{schema}, func(t *ast.Term) error {
+		return ast.As(t.Value, &result)
+	}); err != nil {
+		return err
+	}
+
+	if len(result) != 2 {
+		return errors.New("unexpected json.verify_schema result")
+	}
+
+	if valid, _ := result[0].(bool); !valid {
+		desc, _ := result[1].(string)
+		return errors.New(desc)
+	}
+
+	return nil
+}
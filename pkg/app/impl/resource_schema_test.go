@@ -0,0 +1,167 @@
+package impl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	runtime "github.com/aserto-dev/runtime"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const testResourceSchema = `{
+	"type": "object",
+	"properties": {
+		"id": {"type": "string"},
+		"level": {"type": "integer"}
+	},
+	"required": ["id"],
+	"additionalProperties": false
+}`
+
+// newBundleRuntime returns a runtime serving the local bundle directory holding the files.
+func newBundleRuntime(t *testing.T, files map[string]string) *runtime.Runtime {
+	ctx := context.Background()
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	rt, cleanup, err := runtime.NewRuntime(ctx, &logger, &runtime.Config{
+		InstanceID:   "test",
+		LocalBundles: runtime.LocalBundlesConfig{Paths: []string{dir}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	require.NoError(t, rt.Start(ctx))
+
+	return rt
+}
+
+func resourceRequest(resource map[string]interface{}) *authorizer.IsRequest {
+	req := isRequest("", "")
+	req.ResourceContext, _ = structpb.NewStruct(resource)
+	return req
+}
+
+func TestResourceSchemaBundleFile(t *testing.T) {
+	rt := newBundleRuntime(t, map[string]string{
+		"policy.rego":       "package test\n\nallowed = true\n",
+		"schemas/test.json": testResourceSchema,
+	})
+
+	cfg := &config.Common{ResourceSchemas: config.ResourceSchemasConfig{Validation: config.SchemaValidationEnforce}}
+	s := newTestServer(t, cfg, map[string]*runtime.Runtime{"": rt})
+
+	resp, err := s.Is(context.Background(), resourceRequest(map[string]interface{}{"id": "1", "level": 2}))
+	require.NoError(t, err)
+	require.Len(t, resp.Decisions, 1)
+	assert.True(t, resp.Decisions[0].Is)
+
+	_, err = s.Is(context.Background(), resourceRequest(map[string]interface{}{"level": "high", "name": "todo"}))
+	require.Error(t, err)
+	assert.True(t, cerr.Equals(aerr.ErrInvalidArgument, err), err.Error())
+	assert.Equal(t, codes.InvalidArgument, cerr.UnwrapAsertoError(err).StatusCode)
+
+	fields := cerr.UnwrapAsertoError(err).Data()
+	assert.Contains(t, fields, "resource_context.id")
+	assert.Contains(t, fields, "resource_context.level")
+	assert.Contains(t, fields, "resource_context.name")
+
+	_, err = s.DecisionTree(context.Background(), &authorizer.DecisionTreeRequest{
+		PolicyContext:   &api.PolicyContext{Path: "test", Decisions: []string{"allowed"}},
+		IdentityContext: &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_NONE},
+		ResourceContext: resourceRequest(map[string]interface{}{"level": 1}).ResourceContext,
+	})
+	require.Error(t, err)
+	assert.True(t, cerr.Equals(aerr.ErrInvalidArgument, err), err.Error())
+}
+
+func TestResourceSchemaMissing(t *testing.T) {
+	rt := newBundleRuntime(t, map[string]string{
+		"policy.rego":        "package test\n\nallowed = true\n",
+		"schemas/other.json": testResourceSchema,
+	})
+
+	cfg := &config.Common{ResourceSchemas: config.ResourceSchemasConfig{Validation: config.SchemaValidationEnforce}}
+	s := newTestServer(t, cfg, map[string]*runtime.Runtime{"": rt})
+
+	// the path has no schema, any resource context is valid.
+	resp, err := s.Is(context.Background(), resourceRequest(map[string]interface{}{"name": "todo"}))
+	require.NoError(t, err)
+	assert.True(t, resp.Decisions[0].Is)
+}
+
+func TestResourceSchemaModes(t *testing.T) {
+	rt := newBundleRuntime(t, map[string]string{
+		"policy.rego":       "package test\n\nallowed = true\n",
+		"schemas/test.json": testResourceSchema,
+	})
+	invalid := map[string]interface{}{"name": "todo"}
+
+	for _, mode := range []string{config.SchemaValidationOff, config.SchemaValidationWarn} {
+		cfg := &config.Common{ResourceSchemas: config.ResourceSchemasConfig{Validation: mode}}
+		s := newTestServer(t, cfg, map[string]*runtime.Runtime{"": rt})
+
+		resp, err := s.Is(context.Background(), resourceRequest(invalid))
+		require.NoError(t, err, mode)
+		assert.True(t, resp.Decisions[0].Is, mode)
+	}
+}
+
+func TestResourceSchemaDataDocument(t *testing.T) {
+	ctx := context.Background()
+	rt := newTestRuntime(t)
+	store := rt.GetPluginsManager().Store
+
+	writeSchema := func(schema string) {
+		var doc interface{}
+		require.NoError(t, util.UnmarshalJSON([]byte(schema), &doc))
+		require.NoError(t, storage.WriteOne(ctx, store, storage.AddOp, storage.Path{"schemas"}, map[string]interface{}{"test": doc}))
+	}
+
+	writeSchema(testResourceSchema)
+	upsertPolicy(t, rt, "package test\n\nallowed = true\n")
+
+	cfg := &config.Common{ResourceSchemas: config.ResourceSchemasConfig{Validation: config.SchemaValidationEnforce}}
+	s := newTestServer(t, cfg, map[string]*runtime.Runtime{"": rt})
+
+	_, err := s.Is(ctx, resourceRequest(map[string]interface{}{"id": "1"}))
+	require.NoError(t, err)
+
+	_, err = s.Is(ctx, resourceRequest(map[string]interface{}{"id": 1}))
+	require.Error(t, err)
+	assert.Contains(t, cerr.UnwrapAsertoError(err).Data(), "resource_context.id")
+
+	// the schema is cached until the compiler is replaced.
+	writeSchema(`{"type": "object", "properties": {"id": {"type": "integer"}}}`)
+	_, err = s.Is(ctx, resourceRequest(map[string]interface{}{"id": 1}))
+	require.Error(t, err, "schema read again before the compiler changed")
+
+	upsertPolicy(t, rt, "package test\n\nallowed = true\n\nvisible = true\n")
+	_, err = s.Is(ctx, resourceRequest(map[string]interface{}{"id": 1}))
+	require.NoError(t, err)
+
+	// an invalid schema fails the calls on its path.
+	writeSchema(`{"type": 12}`)
+	upsertPolicy(t, rt, "package test\n\nallowed = true\n")
+	_, err = s.Is(ctx, resourceRequest(map[string]interface{}{"id": 1}))
+	require.Error(t, err)
+	assert.True(t, cerr.Equals(aerr.ErrInvalidPolicy, err), err.Error())
+}
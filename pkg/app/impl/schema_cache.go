@@ -0,0 +1,112 @@
+package impl
+
+import (
+	"context"
+	"sync"
+
+	runtime "github.com/aserto-dev/runtime"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/storage"
+)
+
+// resourceSchema is the verified resource context schema of a policy path.
+// The term is nil when the policy path has no schema, err is set when the schema is invalid.
+type resourceSchema struct {
+	term *ast.Term
+	err  error
+}
+
+// resourceSchemaCache caches the resource context schemas per runtime.
+//
+// The schemas ship with the bundle, like the prepared queries the cached schemas of a runtime
+// are dropped when the runtime's plugins manager installs a new compiler (bundle activation or
+// local bundle watch reload). Policy paths without schema are cached as well.
+type resourceSchemaCache struct {
+	mu       sync.RWMutex
+	runtimes map[*runtime.Runtime]*runtimeSchemas
+}
+
+type runtimeSchemas struct {
+	compiler *ast.Compiler
+	schemas  map[string]*resourceSchema
+}
+
+func newResourceSchemaCache() *resourceSchemaCache {
+	return &resourceSchemaCache{
+		runtimes: map[*runtime.Runtime]*runtimeSchemas{},
+	}
+}
+
+// schema returns the schema of the policy path, loading and caching it within the transaction
+// when the runtime's current compiler has not seen the policy path before.
+func (c *resourceSchemaCache) schema(ctx context.Context, rt *runtime.Runtime, txn storage.Transaction, path string) (*resourceSchema, error) {
+	compiler := rt.GetPluginsManager().GetCompiler()
+
+	if schema, ok := c.get(rt, compiler, path); ok {
+		return schema, nil
+	}
+
+	schema, err := loadSchema(ctx, rt, txn, path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(rt, compiler, path, schema)
+
+	return schema, nil
+}
+
+func (c *resourceSchemaCache) get(rt *runtime.Runtime, compiler *ast.Compiler, path string) (*resourceSchema, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.runtimes[rt]
+	if !ok || entry.compiler != compiler {
+		return nil, false
+	}
+
+	schema, ok := entry.schemas[path]
+	return schema, ok
+}
+
+func (c *resourceSchemaCache) put(rt *runtime.Runtime, compiler *ast.Compiler, path string, schema *resourceSchema) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.runtimes[rt]
+	if !ok {
+		// first time this runtime is seen, invalidate its entries whenever the compiler is replaced.
+		rt.GetPluginsManager().RegisterCompilerTrigger(func(storage.Transaction) {
+			c.invalidate(rt)
+		})
+	}
+
+	if !ok || entry.compiler != compiler {
+		entry = &runtimeSchemas{
+			compiler: compiler,
+			schemas:  map[string]*resourceSchema{},
+		}
+		c.runtimes[rt] = entry
+	}
+
+	entry.schemas[path] = schema
+}
+
+// evict drops the runtime and its schemas, it is called when the runtime is stopped.
+func (c *resourceSchemaCache) evict(rt *runtime.Runtime) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.runtimes, rt)
+}
+
+// invalidate drops all schemas of the runtime, the runtime stays registered.
+func (c *resourceSchemaCache) invalidate(rt *runtime.Runtime) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.runtimes[rt]; ok {
+		entry.compiler = nil
+		entry.schemas = map[string]*resourceSchema{}
+	}
+}
@@ -0,0 +1,193 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	runtime "github.com/aserto-dev/runtime"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	decisionlog_plugin "github.com/aserto-dev/topaz/plugins/decision_log"
+	"github.com/google/uuid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Results of shadow evaluations reported in the shadow metrics.
+const (
+	ShadowMatch    = "match"
+	ShadowDiverged = "diverged"
+	ShadowError    = "error"
+	ShadowSkipped  = "skipped"
+)
+
+var (
+	keyShadowResult = tag.MustNewKey("result")
+
+	measureShadowEvaluations = stats.Int64("topaz/authorizer/shadow_evaluations", "Number of shadow evaluations", stats.UnitDimensionless)
+
+	// ShadowViews exports the shadow evaluation counters, by result (match, diverged, error or skipped).
+	ShadowViews = []*view.View{
+		{
+			Name:        "topaz/authorizer/shadow_evaluations",
+			Measure:     measureShadowEvaluations,
+			Description: measureShadowEvaluations.Description(),
+			TagKeys:     []tag.Key{keyShadowResult},
+			Aggregation: view.Sum(),
+		},
+	}
+)
+
+// shadow evaluates the Is request against the shadow runtime of its policy instance, when the policy instance is shadowed.
+// The shadow evaluation runs in the background and never affects the live response, requests are not shadowed
+// while the maximum number of concurrent shadow evaluations is reached.
+func (s *AuthorizerServer) shadow(
+	ctx context.Context,
+	liveRuntime *runtime.Runtime,
+	req *authorizer.IsRequest,
+	input map[string]interface{},
+	outcomes map[string]bool,
+) {
+	shadowRuntime, err := s.resolver.GetRuntimeResolver().ShadowRuntime(ctx, req.PolicyInstance.GetName(), req.PolicyInstance.GetInstanceLabel())
+	if err != nil {
+		s.logger.Warn().Err(err).Str("path", req.PolicyContext.Path).Msg("failed to resolve shadow runtime")
+		recordShadow(ctx, ShadowError)
+		return
+	}
+
+	if shadowRuntime == nil {
+		return
+	}
+
+	select {
+	case s.shadows <- struct{}{}:
+	default:
+		recordShadow(ctx, ShadowSkipped)
+		return
+	}
+
+	// the shadow evaluation outlives the request.
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		defer func() { <-s.shadows }()
+
+		recordShadow(ctx, s.evalShadow(ctx, liveRuntime, shadowRuntime, req, input, outcomes))
+	}()
+}
+
+// evalShadow evaluates the request against the shadow runtime and reports a divergence to the decision log of the live runtime.
+func (s *AuthorizerServer) evalShadow(
+	ctx context.Context,
+	liveRuntime, shadowRuntime *runtime.Runtime,
+	req *authorizer.IsRequest,
+	input map[string]interface{},
+	outcomes map[string]bool,
+) string {
+	log := s.logger.With().Str("api", "is").Str("path", req.PolicyContext.Path).Logger()
+
+	query, err := s.queryCache.prepare(ctx, shadowRuntime, nil, isQuery(req.PolicyContext.Path))
+	if err != nil {
+		log.Warn().Err(err).Msg("shadow query preparation failed")
+		return ShadowError
+	}
+
+	_, shadowOutcomes, err := s.decide(ctx, query, req, input)
+	if err != nil {
+		log.Warn().Err(err).Msg("shadow evaluation failed")
+		return ShadowError
+	}
+
+	diverged := []string{}
+	for decision, outcome := range outcomes {
+		if shadowOutcomes[decision] != outcome {
+			diverged = append(diverged, decision)
+		}
+	}
+
+	if len(diverged) == 0 {
+		return ShadowMatch
+	}
+
+	sort.Strings(diverged)
+
+	log.Debug().Strs("diverged", diverged).Msg("shadow outcome diverged")
+
+	if err := logDivergence(ctx, liveRuntime, req, input, outcomes, shadowOutcomes, diverged); err != nil {
+		log.Warn().Err(err).Msg("failed to log shadow divergence")
+	}
+
+	return ShadowDiverged
+}
+
+// logDivergence sends the divergence record of a shadow evaluation to the decision log, the record holds the live outcomes
+// and is annotated with the diverged decisions and the shadow outcomes.
+func logDivergence(
+	ctx context.Context,
+	liveRuntime *runtime.Runtime,
+	req *authorizer.IsRequest,
+	input map[string]interface{},
+	outcomes, shadowOutcomes map[string]bool,
+	diverged []string,
+) error {
+	dlPlugin := decisionlog_plugin.Lookup(liveRuntime.GetPluginsManager())
+	if dlPlugin == nil {
+		return nil
+	}
+
+	evaluation := decisionlog.Evaluation{
+		API:    decisionlog.APIShadow,
+		Caller: decisionlog.CallerFromContext(ctx),
+	}
+
+	annotations := evaluation.Annotations()
+	annotations[decisionlog.AnnotationDiverged] = strings.Join(diverged, ",")
+	annotations[decisionlog.AnnotationShadowOutcomes] = outcomesSummary(shadowOutcomes)
+
+	d := api.Decision{
+		Id:        uuid.NewString(),
+		Timestamp: timestamppb.New(time.Now().In(time.UTC)),
+		Path:      req.PolicyContext.Path,
+		Policy: &api.DecisionPolicy{
+			Context:        req.PolicyContext,
+			PolicyInstance: req.PolicyInstance,
+		},
+		User: &api.DecisionUser{
+			Context: req.IdentityContext,
+			Id:      getID(input),
+			Email:   getEmail(input),
+		},
+		TenantId:    getTenantID(ctx),
+		Resource:    req.ResourceContext,
+		Outcomes:    outcomes,
+		Annotations: annotations,
+	}
+
+	return dlPlugin.Log(ctx, &d)
+}
+
+// outcomesSummary returns the outcomes as space separated "<decision>=<outcome>" pairs, sorted by decision.
+func outcomesSummary(outcomes map[string]bool) string {
+	decisions := make([]string, 0, len(outcomes))
+	for decision := range outcomes {
+		decisions = append(decisions, decision)
+	}
+	sort.Strings(decisions)
+
+	summary := make([]string, 0, len(decisions))
+	for _, decision := range decisions {
+		summary = append(summary, fmt.Sprintf("%s=%t", decision, outcomes[decision]))
+	}
+
+	return strings.Join(summary, " ")
+}
+
+func recordShadow(ctx context.Context, result string) {
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(keyShadowResult, result)}, measureShadowEvaluations.M(1))
+}
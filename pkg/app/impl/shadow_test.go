@@ -0,0 +1,146 @@
+package impl
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	runtime "github.com/aserto-dev/runtime"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+)
+
+// newShadowedServer returns a server whose default runtime is shadowed, the decision log of the live runtime
+// records the divergences only.
+func newShadowedServer(t *testing.T, maxConcurrency int, live, shadow string) (*AuthorizerServer, *testDecisionLogger) {
+	dl := &testDecisionLogger{}
+	liveRuntime := newAuditedRuntime(t, dl, decisionlog.APIQuery)
+	upsertPolicy(t, liveRuntime, live)
+
+	shadowRuntime := newTestRuntime(t)
+	upsertPolicy(t, shadowRuntime, shadow)
+
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+
+	rf := resolvers.New()
+	rf.SetRuntimeResolver(&testRuntimeResolver{
+		runtimes: map[string]*runtime.Runtime{"": liveRuntime},
+		shadows:  map[string]*runtime.Runtime{"": shadowRuntime},
+	})
+
+	s, err := NewAuthorizerServer(context.Background(), &logger, &config.Common{Shadow: config.ShadowConfig{MaxConcurrency: maxConcurrency}}, rf)
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	return s, dl
+}
+
+func shadowRequest() *authorizer.IsRequest {
+	return &authorizer.IsRequest{
+		IdentityContext: &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_NONE},
+		PolicyContext:   &api.PolicyContext{Path: "test", Decisions: []string{"allowed", "enabled", "visible"}},
+	}
+}
+
+// waitShadows waits for the running shadow evaluations to complete.
+func waitShadows(t *testing.T, s *AuthorizerServer) {
+	require.Eventually(t, func() bool { return len(s.shadows) == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+// shadowResults returns the shadow evaluation counters by result.
+func shadowResults(t *testing.T) map[string]float64 {
+	rows, err := view.RetrieveData("topaz/authorizer/shadow_evaluations")
+	require.NoError(t, err)
+
+	results := map[string]float64{}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == keyShadowResult {
+				results[tag.Value] = row.Data.(*view.SumData).Value
+			}
+		}
+	}
+	return results
+}
+
+func registerShadowViews(t *testing.T) {
+	require.NoError(t, view.Register(ShadowViews...))
+	t.Cleanup(func() { view.Unregister(ShadowViews...) })
+}
+
+func TestShadowDiverged(t *testing.T) {
+	registerShadowViews(t)
+
+	s, dl := newShadowedServer(t, 1,
+		"package test\n\nallowed = true\n\nenabled = true\n\nvisible = false\n",
+		"package test\n\nallowed = false\n\nenabled = true\n\nvisible = true\n",
+	)
+
+	// the live outcomes are returned.
+	resp, err := s.Is(context.Background(), shadowRequest())
+	require.NoError(t, err)
+	require.Len(t, resp.Decisions, 3)
+	assert.True(t, resp.Decisions[0].Is)
+	assert.False(t, resp.Decisions[2].Is)
+
+	waitShadows(t, s)
+
+	d := dl.last(t)
+	assert.Len(t, dl.decisions, 1)
+	assert.Equal(t, "test", d.Path)
+	assert.Equal(t, map[string]bool{"allowed": true, "enabled": true, "visible": false}, d.Outcomes)
+
+	evaluation, err := decisionlog.EvaluationFromAnnotations(d.Annotations)
+	require.NoError(t, err)
+	assert.Equal(t, decisionlog.APIShadow, evaluation.API)
+	assert.Equal(t, "allowed,visible", d.Annotations[decisionlog.AnnotationDiverged])
+	assert.Equal(t, "allowed=false enabled=true visible=true", d.Annotations[decisionlog.AnnotationShadowOutcomes])
+
+	assert.Equal(t, map[string]float64{ShadowDiverged: 1}, shadowResults(t))
+}
+
+func TestShadowMatch(t *testing.T) {
+	registerShadowViews(t)
+
+	// the shadow policy differs, its outcomes don't.
+	s, dl := newShadowedServer(t, 1,
+		"package test\n\nallowed = true\n\nenabled = true\n\nvisible = false\n",
+		"package test\n\nallowed { true }\n\nenabled = true\n\nvisible = false\n",
+	)
+
+	_, err := s.Is(context.Background(), shadowRequest())
+	require.NoError(t, err)
+
+	waitShadows(t, s)
+
+	assert.Empty(t, dl.decisions)
+	assert.Equal(t, map[string]float64{ShadowMatch: 1}, shadowResults(t))
+}
+
+func TestShadowSkipped(t *testing.T) {
+	registerShadowViews(t)
+
+	s, dl := newShadowedServer(t, 1,
+		"package test\n\nallowed = true\n\nenabled = true\n\nvisible = false\n",
+		"package test\n\nallowed = false\n\nenabled = true\n\nvisible = true\n",
+	)
+
+	// the maximum number of concurrent shadow evaluations is reached.
+	s.shadows <- struct{}{}
+
+	resp, err := s.Is(context.Background(), shadowRequest())
+	require.NoError(t, err)
+	assert.True(t, resp.Decisions[0].Is)
+
+	assert.Len(t, s.shadows, 1)
+	assert.Empty(t, dl.decisions)
+	assert.Equal(t, map[string]float64{ShadowSkipped: 1}, shadowResults(t))
+}
@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aserto-dev/aserto-grpc/grpcutil"
+	dsi2 "github.com/aserto-dev/go-directory/aserto/directory/importer/v2"
+	dsi3 "github.com/aserto-dev/go-directory/aserto/directory/importer/v3"
+	dsm3 "github.com/aserto-dev/go-directory/aserto/directory/model/v3"
+	dsw2 "github.com/aserto-dev/go-directory/aserto/directory/writer/v2"
+	dsw3 "github.com/aserto-dev/go-directory/aserto/directory/writer/v3"
+	"github.com/aserto-dev/topaz/directory"
+	"google.golang.org/grpc"
+)
+
+// DirectoryChangesMiddleware notifies the directory changes made by the calls of the edge directory writer and importer services
+// and by the manifest writes of the model service.
+type DirectoryChangesMiddleware struct{}
+
+var _ grpcutil.Middleware = &DirectoryChangesMiddleware{}
+
+var directoryWriteServices = []string{
+	dsw2.Writer_ServiceDesc.ServiceName,
+	dsw3.Writer_ServiceDesc.ServiceName,
+	dsi2.Importer_ServiceDesc.ServiceName,
+	dsi3.Importer_ServiceDesc.ServiceName,
+}
+
+// directoryWriteMethods are the methods writing the edge directory in services that also read it.
+var directoryWriteMethods = []string{
+	dsm3.Model_SetManifest_FullMethodName,
+	dsm3.Model_DeleteManifest_FullMethodName,
+}
+
+func (m *DirectoryChangesMiddleware) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if isDirectoryWrite(info.FullMethod) {
+			directory.NotifyChange()
+		}
+		return resp, err
+	}
+}
+
+func (m *DirectoryChangesMiddleware) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, stream)
+		if isDirectoryWrite(info.FullMethod) {
+			directory.NotifyChange()
+		}
+		return err
+	}
+}
+
+// isDirectoryWrite returns true for the methods of the writer and importer services and the manifest writes,
+// failed calls included as an import can fail after writing part of its stream.
+func isDirectoryWrite(fullMethod string) bool {
+	for _, service := range directoryWriteServices {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+	for _, method := range directoryWriteMethods {
+		if fullMethod == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package middlewares_test
+
+import (
+	"context"
+	"testing"
+
+	dsi3 "github.com/aserto-dev/go-directory/aserto/directory/importer/v3"
+	dsm3 "github.com/aserto-dev/go-directory/aserto/directory/model/v3"
+	dsr3 "github.com/aserto-dev/go-directory/aserto/directory/reader/v3"
+	dsw3 "github.com/aserto-dev/go-directory/aserto/directory/writer/v3"
+	"github.com/aserto-dev/topaz/directory"
+	"github.com/aserto-dev/topaz/pkg/app/middlewares"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestDirectoryChanges(t *testing.T) {
+	changes := 0
+	t.Cleanup(directory.OnChange(func() { changes++ }))
+
+	m := &middlewares.DirectoryChangesMiddleware{}
+
+	unary := func(method string, err error) {
+		_, _ = m.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, interface{}) (interface{}, error) {
+			return nil, err
+		})
+	}
+	stream := func(method string, err error) {
+		_ = m.Stream()(nil, nil, &grpc.StreamServerInfo{FullMethod: method}, func(interface{}, grpc.ServerStream) error {
+			return err
+		})
+	}
+
+	tests := []struct {
+		name    string
+		call    func()
+		changed bool
+	}{
+		{"set object", func() { unary(dsw3.Writer_SetObject_FullMethodName, nil) }, true},
+		{"failed delete relation", func() { unary(dsw3.Writer_DeleteRelation_FullMethodName, errors.New("failed")) }, true},
+		{"failed import", func() { stream(dsi3.Importer_Import_FullMethodName, errors.New("failed")) }, true},
+		{"set manifest", func() { stream(dsm3.Model_SetManifest_FullMethodName, nil) }, true},
+		{"delete manifest", func() { unary(dsm3.Model_DeleteManifest_FullMethodName, nil) }, true},
+		{"get manifest", func() { stream(dsm3.Model_GetManifest_FullMethodName, nil) }, false},
+		{"get object", func() { unary(dsr3.Reader_GetObject_FullMethodName, nil) }, false},
+	}
+
+	for _, tc := range tests {
+		changes = 0
+		tc.call()
+		assert.Equal(t, tc.changed, changes == 1, tc.name)
+	}
+}
+
+func TestDirectoryChangesUnregister(t *testing.T) {
+	changes := 0
+	unregister := directory.OnChange(func() { changes++ })
+
+	directory.NotifyChange()
+	unregister()
+	directory.NotifyChange()
+
+	// unregistering twice is a no-op.
+	unregister()
+
+	assert.Equal(t, 1, changes)
+}
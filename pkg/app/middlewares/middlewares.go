@@ -9,6 +9,7 @@ import (
 	"github.com/aserto-dev/aserto-grpc/grpcutil/middlewares/tracing"
 	"github.com/aserto-dev/go-edge-ds/pkg/session"
 	"github.com/aserto-dev/topaz/pkg/app/auth"
+	"github.com/aserto-dev/topaz/pkg/app/mtls"
 	"github.com/aserto-dev/topaz/pkg/cc/config"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
@@ -31,13 +32,22 @@ func GetMiddlewaresForService(ctx context.Context, cfg *config.Config, logger *z
 	if cfg.OPA.Config.Discovery != nil && cfg.OPA.Config.Discovery.Resource != nil {
 		middlewareList = append(middlewareList, NewInstanceMiddleware(cfg, logger))
 	}
+	identities, err := mtls.New(&cfg.Common)
+	if err != nil {
+		return nil, err
+	}
+	if identities != nil {
+		middlewareList = append(middlewareList, NewMTLSIdentityMiddleware(identities, cfg.MTLS.Fallback))
+	}
+
 	// get tenant id from opa instance id.
 	middlewareList = append(middlewareList,
 		request.NewRequestIDMiddleware(),
 		NewTenantIDMiddleware(cfg),
 		tracing.NewTracingMiddleware(logger),
 		gerr.NewErrorMiddleware(),
-		&sessionMiddleware)
+		&sessionMiddleware,
+		&DirectoryChangesMiddleware{})
 
 	var opts []grpc.ServerOption
 	unary, stream := middlewareList.AsGRPCOptions()
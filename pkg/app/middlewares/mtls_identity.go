@@ -0,0 +1,108 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/aserto-dev/aserto-grpc/grpcutil"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
+	"github.com/aserto-dev/topaz/pkg/app/mtls"
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+)
+
+// MTLSIdentityMiddleware sets the client certificate identity of the identity contexts of the mTLS identity type,
+// and of the requests sent without identity context when the fallback is enabled.
+type MTLSIdentityMiddleware struct {
+	identities *mtls.Identities
+	fallback   bool
+}
+
+func NewMTLSIdentityMiddleware(identities *mtls.Identities, fallback bool) *MTLSIdentityMiddleware {
+	return &MTLSIdentityMiddleware{
+		identities: identities,
+		fallback:   fallback,
+	}
+}
+
+var _ grpcutil.Middleware = &MTLSIdentityMiddleware{}
+
+func (m *MTLSIdentityMiddleware) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		identity := m.identity(ctx)
+
+		switch request := req.(type) {
+		case *authorizer.IsRequest:
+			request.IdentityContext = m.identityContext(request.IdentityContext, identity)
+		case *authorizer.DecisionTreeRequest:
+			request.IdentityContext = m.identityContext(request.IdentityContext, identity)
+		case *authorizer.QueryRequest:
+			request.IdentityContext = m.identityContext(request.IdentityContext, identity)
+		case *authorizer.CompileRequest:
+			request.IdentityContext = m.identityContext(request.IdentityContext, identity)
+		case *azv1.IsBatchRequest:
+			for _, r := range request.Requests {
+				r.IdentityContext = m.identityContext(r.IdentityContext, identity)
+			}
+		case *azv1.IsExplainRequest:
+			if request.Request != nil {
+				request.Request.IdentityContext = m.identityContext(request.Request.IdentityContext, identity)
+			}
+		case *azv1.CompileFilterRequest:
+			if request.Compile != nil {
+				request.Compile.IdentityContext = m.identityContext(request.Compile.IdentityContext, identity)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (m *MTLSIdentityMiddleware) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		wrapped := grpcmiddleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+		return handler(srv, &mtlsIdentityStream{WrappedServerStream: wrapped, middleware: m, identity: m.identity(ctx)})
+	}
+}
+
+// identity returns the client certificate identity of the connection, empty when it has none.
+func (m *MTLSIdentityMiddleware) identity(ctx context.Context) string {
+	identity, err := m.identities.FromContext(ctx)
+	if err != nil {
+		return ""
+	}
+	return identity
+}
+
+// identityContext returns the identity context with the client certificate identity. The identity of mTLS identity contexts
+// is always replaced, requests without identity context use the client certificate identity when the fallback is enabled.
+func (m *MTLSIdentityMiddleware) identityContext(identityContext *api.IdentityContext, identity string) *api.IdentityContext {
+	switch {
+	case identityContext.GetType() == mtls.IdentityType:
+		identityContext.Identity = identity
+	case identityContext.GetType() == api.IdentityType_IDENTITY_TYPE_UNKNOWN && m.fallback && identity != "":
+		return &api.IdentityContext{Type: mtls.IdentityType, Identity: identity}
+	}
+
+	return identityContext
+}
+
+type mtlsIdentityStream struct {
+	*grpcmiddleware.WrappedServerStream
+	middleware *MTLSIdentityMiddleware
+	identity   string
+}
+
+func (s *mtlsIdentityStream) RecvMsg(msg interface{}) error {
+	if err := s.WrappedServerStream.RecvMsg(msg); err != nil {
+		return err
+	}
+
+	if request, ok := msg.(*azv1.IsStreamRequest); ok && request.Request != nil {
+		request.Request.IdentityContext = s.middleware.identityContext(request.Request.IdentityContext, s.identity)
+	}
+
+	return nil
+}
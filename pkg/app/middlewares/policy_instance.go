@@ -7,6 +7,7 @@ import (
 	"github.com/aserto-dev/aserto-grpc/grpcutil"
 	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
 	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
 	"github.com/aserto-dev/topaz/pkg/cc/config"
 	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/rs/zerolog"
@@ -31,26 +32,61 @@ func NewInstanceMiddleware(cfg *config.Config, logger *zerolog.Logger) *PolicyIn
 
 var _ grpcutil.Middleware = &PolicyInstanceMiddleware{}
 
-// If the unary operation is an Is request attach configured instance information to request.
+// If the unary operation is an Is, IsBatch or IsExplain request without a policy instance,
+// attach configured instance information to request.
 func (m *PolicyInstanceMiddleware) Unary() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		request, ok := req.(*authorizer.IsRequest)
-		if ok {
-			request.PolicyInstance = &api.PolicyInstance{
-				InstanceLabel: m.instanceLabel,
-				Name:          m.policyName,
+		switch request := req.(type) {
+		case *authorizer.IsRequest:
+			request.PolicyInstance = m.policyInstance(request.PolicyInstance)
+		case *azv1.IsBatchRequest:
+			for _, r := range request.Requests {
+				r.PolicyInstance = m.policyInstance(r.PolicyInstance)
+			}
+		case *azv1.IsExplainRequest:
+			if request.Request != nil {
+				request.Request.PolicyInstance = m.policyInstance(request.Request.PolicyInstance)
 			}
 		}
 		return handler(ctx, req)
 	}
 }
 
-// passthrough as Is call is Unary type operation.
+// policyInstance returns the configured policy instance, unless the request selects a named policy instance.
+func (m *PolicyInstanceMiddleware) policyInstance(requested *api.PolicyInstance) *api.PolicyInstance {
+	if requested.GetName() != "" {
+		return requested
+	}
+
+	return &api.PolicyInstance{
+		InstanceLabel: m.instanceLabel,
+		Name:          m.policyName,
+	}
+}
+
+// Attach configured instance information to the IsStream requests received without a policy instance.
 func (m *PolicyInstanceMiddleware) Stream() grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		ctx := stream.Context()
 		wrapped := grpcmiddleware.WrapServerStream(stream)
 		wrapped.WrappedContext = ctx
-		return handler(srv, wrapped)
+		return handler(srv, &policyInstanceStream{WrappedServerStream: wrapped, middleware: m})
+	}
+}
+
+type policyInstanceStream struct {
+	*grpcmiddleware.WrappedServerStream
+	middleware *PolicyInstanceMiddleware
+}
+
+func (s *policyInstanceStream) RecvMsg(msg interface{}) error {
+	if err := s.WrappedServerStream.RecvMsg(msg); err != nil {
+		return err
 	}
+
+	if request, ok := msg.(*azv1.IsStreamRequest); ok && request.Request != nil {
+		request.Request.PolicyInstance = s.middleware.policyInstance(request.Request.PolicyInstance)
+	}
+
+	return nil
 }
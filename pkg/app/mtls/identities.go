@@ -0,0 +1,204 @@
+package mtls
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aserto-dev/certs"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// IdentityType is the identity type of the client certificate identities. It is not one of the
+	// aserto.authorizer.v2 identity types, clients send the type number (5) in their identity context.
+	IdentityType api.IdentityType = 5
+	// IdentityTypeName is the name of the identity type in the policy input.
+	IdentityTypeName = "IDENTITY_TYPE_MTLS"
+
+	spiffeScheme = "spiffe"
+)
+
+// ErrNoIdentity is returned when the connection has no verified client certificate identity.
+var ErrNoIdentity = aerr.ErrAuthenticationFailed.Msg("no verified client certificate identity")
+
+// Identities derives the identities of the verified client certificates of gRPC connections.
+type Identities struct {
+	cfg       *config.MTLSConfig
+	clientCAs [][]byte
+	// the certificates of the gRPC services, presented by their gateway, carry no identity.
+	serverCerts [][]byte
+}
+
+// New returns the client certificate identities of the configuration, nil when client certificates are not requested.
+func New(cfg *config.Common) (*Identities, error) {
+	if !cfg.MTLS.Enabled() {
+		return nil, nil
+	}
+
+	clientCAs, err := readCertificates(cfg.MTLS.ClientCACertPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read client CA certificates")
+	}
+
+	serverCerts := [][]byte{}
+	for _, service := range cfg.APIConfig.Services {
+		if service.GRPC.Certs.TLSCertPath == "" {
+			continue
+		}
+		certs, err := readCertificates(service.GRPC.Certs.TLSCertPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read gRPC certificate")
+		}
+		serverCerts = append(serverCerts, certs[0])
+	}
+
+	return &Identities{
+		cfg:         &cfg.MTLS,
+		clientCAs:   clientCAs,
+		serverCerts: serverCerts,
+	}, nil
+}
+
+// ServerCreds returns the transport credentials of a gRPC service requesting client certificates.
+// The certificates signed by the CA of the service are accepted too, so that its gateway can connect.
+func (i *Identities) ServerCreds(creds *certs.TLSCredsConfig) (credentials.TransportCredentials, error) {
+	certificate, err := tls.LoadX509KeyPair(creds.TLSCertPath, creds.TLSKeyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load gRPC certs")
+	}
+
+	pool := x509.NewCertPool()
+	for _, ca := range i.clientCAs {
+		cert, err := x509.ParseCertificate(ca)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse client CA certificate")
+		}
+		pool.AddCert(cert)
+	}
+
+	if creds.TLSCACertPath != "" {
+		caCert, err := os.ReadFile(creds.TLSCACertPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read ca cert: %s", creds.TLSCACertPath)
+		}
+		pool.AppendCertsFromPEM(caCert)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// FromContext returns the identity of the client certificate of the gRPC connection,
+// the certificate must be verified by a client CA certificate.
+func (i *Identities) FromContext(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", ErrNoIdentity
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", ErrNoIdentity
+	}
+
+	for _, chain := range tlsInfo.State.VerifiedChains {
+		if len(chain) == 0 || !containsCert(i.clientCAs, chain[len(chain)-1].Raw) {
+			continue
+		}
+
+		if containsCert(i.serverCerts, chain[0].Raw) {
+			return "", ErrNoIdentity
+		}
+
+		return i.FromCertificate(chain[0])
+	}
+
+	return "", ErrNoIdentity
+}
+
+// FromCertificate returns the identity of the first configured identity source present in the certificate.
+func (i *Identities) FromCertificate(cert *x509.Certificate) (string, error) {
+	for _, source := range i.cfg.IdentitySources {
+		switch {
+		case source == config.CertIdentityURISAN && len(cert.URIs) > 0:
+			if cert.URIs[0].Scheme == spiffeScheme {
+				return i.spiffeIdentity(cert.URIs[0])
+			}
+			return cert.URIs[0].String(), nil
+		case source == config.CertIdentityDNSSAN && len(cert.DNSNames) > 0:
+			return cert.DNSNames[0], nil
+		case source == config.CertIdentityCommonName && cert.Subject.CommonName != "":
+			return cert.Subject.CommonName, nil
+		}
+	}
+
+	return "", aerr.ErrAuthenticationFailed.Msgf("client certificate has no identity [%s]", strings.Join(i.cfg.IdentitySources, ", "))
+}
+
+// spiffeIdentity returns the identity of a SPIFFE ID, spiffe://<trust domain>/<workload path>.
+func (i *Identities) spiffeIdentity(id *url.URL) (string, error) {
+	if id.Host == "" || id.User != nil || id.Port() != "" || id.RawQuery != "" || id.Fragment != "" || strings.Trim(id.Path, "/") == "" {
+		return "", aerr.ErrAuthenticationFailed.Msgf("invalid SPIFFE ID %q", id.String())
+	}
+
+	trustDomain := strings.ToLower(id.Host)
+	if len(i.cfg.SPIFFE.TrustDomains) > 0 && !lo.ContainsBy(i.cfg.SPIFFE.TrustDomains, func(td string) bool {
+		return strings.EqualFold(td, trustDomain)
+	}) {
+		return "", aerr.ErrAuthenticationFailed.Msgf("untrusted SPIFFE trust domain %q", trustDomain)
+	}
+
+	if i.cfg.SPIFFE.Identity == config.SPIFFEIdentityPath {
+		return strings.TrimPrefix(id.Path, "/"), nil
+	}
+
+	return spiffeScheme + "://" + trustDomain + id.Path, nil
+}
+
+// readCertificates returns the DER encoded certificates of a PEM file.
+func readCertificates(path string) ([][]byte, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := [][]byte{}
+	for {
+		var block *pem.Block
+		block, buf = pem.Decode(buf)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, block.Bytes)
+		}
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.Errorf("no certificate found in %s", path)
+	}
+
+	return certs, nil
+}
+
+func containsCert(certs [][]byte, raw []byte) bool {
+	return lo.ContainsBy(certs, func(cert []byte) bool {
+		return bytes.Equal(cert, raw)
+	})
+}
@@ -0,0 +1,217 @@
+package mtls_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	builder "github.com/aserto-dev/service-host"
+	"github.com/aserto-dev/topaz/pkg/app/mtls"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+var serial int64
+
+func newCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial++
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+// issue returns a certificate of the CA with the subject alternative names and common name of the template.
+func (ca *testCA) issue(t *testing.T, template *x509.Certificate) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial++
+	template.SerialNumber = big.NewInt(serial)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func writeCert(t *testing.T, cert *x509.Certificate) string {
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600))
+	return path
+}
+
+func newIdentities(t *testing.T, ca *testCA, mtlsCfg config.MTLSConfig, serverCert *x509.Certificate) *mtls.Identities {
+	mtlsCfg.ClientCACertPath = writeCert(t, ca.cert)
+	if len(mtlsCfg.IdentitySources) == 0 {
+		mtlsCfg.IdentitySources = []string{config.CertIdentityURISAN, config.CertIdentityDNSSAN, config.CertIdentityCommonName}
+	}
+
+	cfg := &config.Common{MTLS: mtlsCfg}
+	cfg.APIConfig.Services = map[string]*builder.API{}
+	if serverCert != nil {
+		api := &builder.API{}
+		api.GRPC.Certs.TLSCertPath = writeCert(t, serverCert)
+		cfg.APIConfig.Services["authorizer"] = api
+	}
+
+	identities, err := mtls.New(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, identities)
+
+	return identities
+}
+
+func mustURL(t *testing.T, raw string) *url.URL {
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
+
+// peerContext returns the context of a TLS connection whose client certificate was verified by the chain.
+func peerContext(chain ...*x509.Certificate) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{chain}}},
+	})
+}
+
+func TestFromCertificateSources(t *testing.T) {
+	ca := newCA(t, "client ca")
+	cert := ca.issue(t, &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "billing"},
+		DNSNames: []string{"billing.acme.com", "billing.internal"},
+		URIs:     []*url.URL{mustURL(t, "https://acme.com/billing")},
+	})
+
+	tests := []struct {
+		sources  []string
+		identity string
+	}{
+		{sources: nil, identity: "https://acme.com/billing"},
+		{sources: []string{config.CertIdentityDNSSAN, config.CertIdentityURISAN}, identity: "billing.acme.com"},
+		{sources: []string{config.CertIdentityCommonName}, identity: "billing"},
+	}
+
+	for _, tc := range tests {
+		identities := newIdentities(t, ca, config.MTLSConfig{IdentitySources: tc.sources}, nil)
+
+		identity, err := identities.FromCertificate(cert)
+		require.NoError(t, err, tc.sources)
+		assert.Equal(t, tc.identity, identity, tc.sources)
+	}
+
+	// the first source present in the certificate applies.
+	cnOnly := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}})
+	identities := newIdentities(t, ca, config.MTLSConfig{}, nil)
+	identity, err := identities.FromCertificate(cnOnly)
+	require.NoError(t, err)
+	assert.Equal(t, "billing", identity)
+
+	identities = newIdentities(t, ca, config.MTLSConfig{IdentitySources: []string{config.CertIdentityURISAN, config.CertIdentityDNSSAN}}, nil)
+	_, err = identities.FromCertificate(cnOnly)
+	assert.Error(t, err)
+}
+
+func TestSPIFFEIdentity(t *testing.T) {
+	ca := newCA(t, "client ca")
+	spiffeCert := func(id string) *x509.Certificate {
+		return ca.issue(t, &x509.Certificate{URIs: []*url.URL{mustURL(t, id)}})
+	}
+	billing := spiffeCert("spiffe://Acme.com/ns/prod/sa/billing")
+
+	identities := newIdentities(t, ca, config.MTLSConfig{SPIFFE: config.SPIFFEConfig{Identity: config.SPIFFEIdentityID}}, nil)
+	identity, err := identities.FromCertificate(billing)
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://acme.com/ns/prod/sa/billing", identity)
+
+	identities = newIdentities(t, ca, config.MTLSConfig{SPIFFE: config.SPIFFEConfig{Identity: config.SPIFFEIdentityPath}}, nil)
+	identity, err = identities.FromCertificate(billing)
+	require.NoError(t, err)
+	assert.Equal(t, "ns/prod/sa/billing", identity)
+
+	// trust domains are compared without case.
+	identities = newIdentities(t, ca, config.MTLSConfig{SPIFFE: config.SPIFFEConfig{TrustDomains: []string{"example.org", "ACME.com"}}}, nil)
+	_, err = identities.FromCertificate(billing)
+	require.NoError(t, err)
+
+	identities = newIdentities(t, ca, config.MTLSConfig{SPIFFE: config.SPIFFEConfig{TrustDomains: []string{"example.org"}}}, nil)
+	_, err = identities.FromCertificate(billing)
+	assert.Error(t, err, "untrusted trust domain")
+
+	identities = newIdentities(t, ca, config.MTLSConfig{}, nil)
+	for _, id := range []string{"spiffe://acme.com", "spiffe://acme.com/", "spiffe://acme.com:8443/billing", "spiffe://acme.com/billing?x=1", "spiffe://user@acme.com/billing"} {
+		_, err := identities.FromCertificate(spiffeCert(id))
+		assert.Error(t, err, id)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	ca := newCA(t, "client ca")
+	otherCA := newCA(t, "other ca")
+
+	client := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}})
+	gateway := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "topaz"}, DNSNames: []string{"localhost"}})
+
+	identities := newIdentities(t, ca, config.MTLSConfig{}, gateway)
+
+	identity, err := identities.FromContext(peerContext(client, ca.cert))
+	require.NoError(t, err)
+	assert.Equal(t, "billing", identity)
+
+	// certificates verified by another CA than the client CA carry no identity.
+	other := otherCA.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}})
+	_, err = identities.FromContext(peerContext(other, otherCA.cert))
+	assert.ErrorIs(t, err, mtls.ErrNoIdentity)
+
+	// the gateway connects with the certificate of the gRPC service, it carries no identity.
+	_, err = identities.FromContext(peerContext(gateway, ca.cert))
+	assert.ErrorIs(t, err, mtls.ErrNoIdentity)
+
+	// connections without verified client certificate.
+	_, err = identities.FromContext(context.Background())
+	assert.ErrorIs(t, err, mtls.ErrNoIdentity)
+
+	_, err = identities.FromContext(peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}}))
+	assert.ErrorIs(t, err, mtls.ErrNoIdentity)
+
+	_, err = identities.FromContext(peer.NewContext(context.Background(), &peer.Peer{}))
+	assert.ErrorIs(t, err, mtls.ErrNoIdentity)
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+
+	builder "github.com/aserto-dev/service-host"
+	"github.com/aserto-dev/topaz/pkg/app/proxy"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"google.golang.org/grpc"
+)
+
+// Proxy serves the authorizing reverse proxy on the gateway of its service, backed by the authorizer service.
+type Proxy struct {
+	handler *proxy.Handler
+}
+
+const (
+	proxyService = "proxy"
+)
+
+func NewProxy(cfg *config.ProxyConfig, authorizer *Authorizer, logger *zerolog.Logger) (ServiceTypes, error) {
+	handler, err := proxy.NewHandler(cfg, authorizer.AuthorizerServer, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create proxy")
+	}
+
+	return &Proxy{handler: handler}, nil
+}
+
+func (e *Proxy) AvailableServices() []string {
+	return []string{proxyService}
+}
+
+func (e *Proxy) GetGRPCRegistrations(services ...string) builder.GRPCRegistrations {
+	return func(server *grpc.Server) {
+	}
+}
+
+func (e *Proxy) GetGatewayRegistration(services ...string) builder.HandlerRegistrations {
+	return func(ctx context.Context, mux *runtime.ServeMux, grpcEndpoint string, opts []grpc.DialOption) error {
+		return nil
+	}
+}
+
+func (e *Proxy) Cleanups() []func() {
+	return nil
+}
+
+// Serve replaces the gateway handler of the server with the proxy. The proxy serves all the requests
+// of its gateway, so it is exclusive of the other services: they cannot share its listen addresses,
+// and the gateway does not serve the REST APIs or the endpoints mounted on the gateway mux.
+func (e *Proxy) Serve(server *builder.Server, services []string) error {
+	if len(services) > 1 {
+		return errors.Errorf("proxy cannot share its grpc listen address with %v", lo.Without(services, proxyService))
+	}
+
+	if server.Gateway.Server == nil {
+		return errors.New("proxy needs a gateway listen address")
+	}
+
+	server.Gateway.Server.Handler = e.handler
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/aserto-dev/topaz/pkg/app/extauthz"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+)
+
+// Handler authorizes the HTTP requests it receives and forwards the allowed requests to the upstream server.
+type Handler struct {
+	cfg        *config.ProxyConfig
+	authorizer *extauthz.Server
+	proxy      *httputil.ReverseProxy
+	logger     *zerolog.Logger
+}
+
+func NewHandler(cfg *config.ProxyConfig, authorizer extauthz.Authorizer, logger *zerolog.Logger) (*Handler, error) {
+	if cfg.Upstream == "" {
+		return nil, errors.New("proxy upstream not set")
+	}
+
+	upstream, err := url.Parse(cfg.Upstream)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid proxy upstream")
+	}
+
+	server, err := extauthz.NewServer(cfg.Authorization(), authorizer, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	newLogger := logger.With().Str("component", "proxy").Logger()
+
+	h := &Handler{
+		cfg:        cfg,
+		authorizer: server,
+		proxy:      httputil.NewSingleHostReverseProxy(upstream),
+		logger:     &newLogger,
+	}
+
+	h.proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		h.logger.Error().Err(err).Str("method", r.Method).Str("path", r.URL.Path).Msg("upstream request failed")
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	return h, nil
+}
+
+// ServeHTTP forwards the request to the upstream server when it is authorized, and denies it otherwise.
+//
+// The request is authorized on its escaped path, which the reverse proxy appends as is to the upstream path,
+// so the upstream server receives the path that was authorized. Paths that the upstream server could resolve
+// to another path, holding dot segments or encoded separators, are denied.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	headers := make(map[string]string, len(r.Header))
+	for name, values := range r.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	st := h.authorizer.Authorize(r.Context(), r.Method, r.URL.EscapedPath(), headers)
+	if codes.Code(st.Code) != codes.OK {
+		for name, value := range h.cfg.DeniedHeaders {
+			w.Header().Set(name, value)
+		}
+		http.Error(w, http.StatusText(h.cfg.DeniedStatus), h.cfg.DeniedStatus)
+		return
+	}
+
+	for name, value := range h.cfg.AllowedHeaders {
+		r.Header.Set(name, value)
+	}
+
+	h.proxy.ServeHTTP(w, r)
+}
@@ -0,0 +1,95 @@
+package proxy_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/topaz/pkg/app/proxy"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAuthorizer denies the requests of the todo with id 2, it records the ids of the evaluated requests.
+type testAuthorizer struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (a *testAuthorizer) Is(ctx context.Context, req *authorizer.IsRequest) (*authorizer.IsResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := req.ResourceContext.Fields["id"].GetStringValue()
+	a.ids = append(a.ids, id)
+
+	return &authorizer.IsResponse{Decisions: []*authorizer.Decision{{Decision: "allowed", Is: id != "2"}}}, nil
+}
+
+func TestHandler(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		forwarded []string
+	)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		forwarded = append(forwarded, r.URL.EscapedPath())
+		w.Header().Set("x-authorized-by", r.Header.Get("x-authorized-by"))
+	}))
+	defer upstream.Close()
+
+	authz := &testAuthorizer{}
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+
+	handler, err := proxy.NewHandler(&config.ProxyConfig{
+		Upstream:       upstream.URL + "/api",
+		PolicyRoot:     "todoApp",
+		Decision:       "allowed",
+		Routes:         []config.ExtAuthzRoute{{Path: "/todos/{id}"}},
+		AllowedHeaders: map[string]string{"x-authorized-by": "topaz"},
+		DeniedStatus:   http.StatusForbidden,
+	}, authz, &logger)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(path string) *http.Response {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	// the upstream server receives the authorized path.
+	resp := get("/todos/1")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "topaz", resp.Header.Get("x-authorized-by"))
+
+	resp = get("/todos/a%20b")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = get("/todos/2")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	assert.Equal(t, []string{"/api/todos/1", "/api/todos/a%20b"}, forwarded)
+	assert.Equal(t, []string{"1", "a b", "2"}, authz.ids)
+
+	// paths the upstream server could resolve to another path are denied without evaluation.
+	for _, path := range []string{"/todos/..%2F2", "/todos/%2e%2e", "/todos/./2", "/x/../todos/2", "/todos//2", "/todos/a%5C2"} {
+		resp := get(path)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, path)
+	}
+
+	assert.Len(t, forwarded, 2)
+	assert.Len(t, authz.ids, 3)
+}
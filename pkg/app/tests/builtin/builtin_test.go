@@ -98,6 +98,32 @@ var BuiltinHelpTests = []struct {
 			},
 		},
 	},
+	{
+		name:  "ds.checks",
+		query: "x = ds.checks({})",
+		expected: map[string]interface{}{
+			"ds.checks": map[string]interface{}{
+				"default": map[string]interface{}{
+					"object_type":  "",
+					"object_id":    "",
+					"relation":     "",
+					"subject_type": "",
+					"subject_id":   "",
+					"trace":        false,
+				},
+				"checks": []interface{}{
+					map[string]interface{}{
+						"object_type":  "",
+						"object_id":    "",
+						"relation":     "",
+						"subject_type": "",
+						"subject_id":   "",
+						"trace":        false,
+					},
+				},
+			},
+		},
+	},
 	{
 		name:  "ds.check_relation",
 		query: "x = ds.check_relation({})",
@@ -182,6 +208,31 @@ var BuiltinHelpTests = []struct {
 				"with_empty_subject_relation": false,
 			}},
 	},
+	{
+		name:  "ds.relations_all",
+		query: "x = ds.relations_all({})",
+		expected: map[string]interface{}{
+			"ds.relations_all": map[string]interface{}{
+				"object_id":                   "",
+				"object_type":                 "",
+				"relation":                    "",
+				"subject_id":                  "",
+				"subject_relation":            "",
+				"subject_type":                "",
+				"with_objects":                false,
+				"with_empty_subject_relation": false,
+				"max_results":                 float64(1000),
+			}},
+	},
+	{
+		name:  "ds.objects",
+		query: "x = ds.objects({})",
+		expected: map[string]interface{}{
+			"ds.objects": map[string]interface{}{
+				"object_type": "",
+				"max_results": float64(1000),
+			}},
+	},
 }
 
 var BuiltinNotFoundErrTests = []struct {
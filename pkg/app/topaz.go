@@ -6,16 +6,21 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/aserto-dev/certs"
 	cerr "github.com/aserto-dev/errors"
 	"github.com/aserto-dev/go-aserto/client"
 	eds "github.com/aserto-dev/go-edge-ds"
 	"github.com/aserto-dev/self-decision-logger/logger/self"
 	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/logger/async"
 	"github.com/aserto-dev/topaz/decision_log/logger/file"
 	"github.com/aserto-dev/topaz/decision_log/logger/nop"
+	"github.com/aserto-dev/topaz/decision_log/logger/webhook"
 	"github.com/aserto-dev/topaz/pkg/app/auth"
 	"github.com/aserto-dev/topaz/pkg/app/handlers"
 	"github.com/aserto-dev/topaz/pkg/app/middlewares"
+	"github.com/aserto-dev/topaz/pkg/app/mtls"
+	"github.com/mitchellh/mapstructure"
 	"github.com/samber/lo"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -130,8 +135,9 @@ func (e *Topaz) ConfigServices() error {
 			}
 		}
 
-		server, err := e.ServiceBuilder.CreateService(
+		server, err := e.createService(
 			serviceConfig.API,
+			lo.Contains(serviceConfig.registeredServices, authorizerService),
 			&builder.GRPCOptions{
 				ServerOptions: opts,
 				Registrations: func(server *grpc.Server) {
@@ -174,6 +180,12 @@ func (e *Topaz) ConfigServices() error {
 			}
 		}
 
+		if p, ok := e.Services[proxyService].(*Proxy); ok && lo.Contains(serviceConfig.registeredServices, proxyService) {
+			if err := p.Serve(server, serviceConfig.registeredServices); err != nil {
+				return err
+			}
+		}
+
 		err = e.Manager.AddGRPCServer(server)
 		if err != nil {
 			return err
@@ -183,6 +195,71 @@ func (e *Topaz) ConfigServices() error {
 	return nil
 }
 
+// createService creates the service of the gRPC address. When mTLS identities are enabled, the gRPC server
+// of the authorizer service requests client certificates.
+//
+// The service builder serves the gRPC certificates with transport credentials that don't request client certificates,
+// appended after the given server options. The gRPC server is thus built without the gRPC certificates, and with the
+// transport credentials requesting client certificates, its gateway is built apart as it dials the gRPC server with them.
+func (e *Topaz) createService(
+	cfg *builder.API,
+	authorizer bool,
+	grpcOpts *builder.GRPCOptions,
+	gatewayOpts *builder.GatewayOptions,
+	cleanups ...func(),
+) (*builder.Server, error) {
+	if !authorizer {
+		return e.ServiceBuilder.CreateService(cfg, grpcOpts, gatewayOpts, cleanups...)
+	}
+
+	identities, err := mtls.New(&e.Configuration.Common)
+	if err != nil {
+		return nil, err
+	}
+	if identities == nil {
+		return e.ServiceBuilder.CreateService(cfg, grpcOpts, gatewayOpts, cleanups...)
+	}
+
+	if cfg.GRPC.Certs.TLSCertPath == "" {
+		return nil, errors.New("mtls needs the gRPC certificates of the authorizer service")
+	}
+
+	creds, err := identities.ServerCreds(&cfg.GRPC.Certs)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcCfg := *cfg
+	grpcCfg.GRPC.Certs = certs.TLSCredsConfig{}
+	grpcCfg.Gateway.ListenAddress = ""
+
+	server, err := e.ServiceBuilder.CreateService(
+		&grpcCfg,
+		&builder.GRPCOptions{
+			ServerOptions: append(append([]grpc.ServerOption{}, grpcOpts.ServerOptions...), grpc.Creds(creds)),
+			Registrations: grpcOpts.Registrations,
+		},
+		nil,
+		cleanups...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	server.Config = cfg
+
+	if cfg.Gateway.ListenAddress != "" {
+		gateway, err := newGateway(cfg, gatewayOpts)
+		if err != nil {
+			server.Server.Stop()
+			_ = server.Listener.Close()
+			return nil, err
+		}
+		server.Gateway = gateway
+	}
+
+	return server, nil
+}
+
 func (e *Topaz) setupHealthAndMetrics() ([]grpc.ServerOption, error) {
 	if e.Configuration.APIConfig.Health.ListenAddress != "" {
 		err := e.Manager.SetupHealthServer(e.Configuration.APIConfig.Health.ListenAddress, e.Configuration.APIConfig.Health.Certificates)
@@ -228,6 +305,32 @@ func (e *Topaz) prepareServices() error {
 	if _, ok := e.Configuration.APIConfig.Services[consoleService]; ok {
 		e.Services["console"] = NewConsole()
 	}
+
+	if _, ok := e.Configuration.APIConfig.Services[extAuthzService]; ok {
+		authorizer, ok := e.Services[authorizerService].(*Authorizer)
+		if !ok {
+			return errors.New("ext_authz needs the authorizer service to be configured")
+		}
+
+		extAuthz, err := NewExtAuthz(&e.Configuration.ExtAuthz, authorizer, e.Logger)
+		if err != nil {
+			return err
+		}
+		e.Services[extAuthzService] = extAuthz
+	}
+
+	if _, ok := e.Configuration.APIConfig.Services[proxyService]; ok {
+		authorizer, ok := e.Services[authorizerService].(*Authorizer)
+		if !ok {
+			return errors.New("proxy needs the authorizer service to be configured")
+		}
+
+		proxy, err := NewProxy(&e.Configuration.Proxy, authorizer, e.Logger)
+		if err != nil {
+			return err
+		}
+		e.Services[proxyService] = proxy
+	}
 	return nil
 }
 
@@ -301,6 +404,25 @@ func (e *Topaz) GetDecisionLogger(cfg config.DecisionLogConfig) (decisionlog.Dec
 			return nil, err
 		}
 
+	case "webhook":
+		webhookCfg := webhook.Config{}
+		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			TagName:          "json",
+			WeaklyTypedInput: true,
+			Result:           &webhookCfg,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := dec.Decode(cfg.Config); err != nil {
+			return nil, errors.Wrap(err, "failed to parse webhook decision logger config")
+		}
+
+		decisionlogger, err = webhook.New(e.Context, &webhookCfg, e.Logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create webhook decision logger")
+		}
+
 	default:
 		decisionlogger, err = nop.New(e.Context, e.Logger)
 		if err != nil {
@@ -309,6 +431,14 @@ func (e *Topaz) GetDecisionLogger(cfg config.DecisionLogConfig) (decisionlog.Dec
 
 	}
 
+	if cfg.Async.Enabled {
+		asyncLogger, err := async.New(e.Context, &cfg.Async, e.Logger, decisionlogger)
+		if err != nil {
+			return nil, err
+		}
+		return asyncLogger, nil
+	}
+
 	return decisionlogger, err
 }
 
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aserto-dev/aserto-management/controller"
@@ -13,6 +14,9 @@ import (
 	runtime "github.com/aserto-dev/runtime"
 	"github.com/aserto-dev/topaz/builtins/edge/ds"
 	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/filter"
+	"github.com/aserto-dev/topaz/limits"
+	"github.com/aserto-dev/topaz/pkg/app/directory"
 	"github.com/aserto-dev/topaz/pkg/app/management"
 	"github.com/aserto-dev/topaz/pkg/cc/config"
 	decisionlog_plugin "github.com/aserto-dev/topaz/plugins/decision_log"
@@ -23,8 +27,37 @@ import (
 
 var _ resolvers.RuntimeResolver = (*RuntimeResolver)(nil)
 
+// DefaultRuntimeDrainPeriod is the time a reloaded or unloaded runtime keeps running for its in-flight evaluations
+// to complete, when the evaluations are not bounded by a timeout.
+const DefaultRuntimeDrainPeriod = 30 * time.Second
+
+// RuntimeResolver serves the default opa runtime and the runtimes of the configured policy instances.
 type RuntimeResolver struct {
-	runtime *runtime.Runtime
+	ctx       context.Context
+	logger    *zerolog.Logger
+	cfg       *config.Config
+	opts      []runtime.Option
+	def       *policyRuntime
+	instances map[string]*policyRuntime
+
+	// shadow serves the shadow bundle of the shadowed policy instance, when configured.
+	shadow   *policyRuntime
+	shadowed *policyRuntime
+
+	// retired holds the stop functions of the reloaded and unloaded runtimes until they are drained.
+	retiredMu sync.Mutex
+	retired   map[*runtime.Runtime]func()
+}
+
+// policyRuntime holds the runtime of a policy instance, the runtime is started on first use
+// and can be reloaded or unloaded at any time. An unloaded instance fails its requests until it is reloaded.
+type policyRuntime struct {
+	mu       sync.RWMutex
+	key      string
+	cfg      *runtime.Config
+	runtime  *runtime.Runtime
+	cleanup  func()
+	unloaded bool
 }
 
 func NewRuntimeResolver(
@@ -34,34 +67,67 @@ func NewRuntimeResolver(
 	ctrlf *controller.Factory,
 	decisionLogger decisionlog.DecisionLogger,
 	directoryResolver resolvers.DirectoryResolver) (resolvers.RuntimeResolver, func(), error) {
+	// the ds builtins share the directory cache, when enabled.
+	directoryResolver = directory.NewCachedResolver(directoryResolver, &cfg.DirectoryCache)
 
-	sidecarRuntime, cleanupRuntime, err := runtime.NewRuntime(ctx, logger, &cfg.OPA,
-		// directory get functions
-		runtime.WithBuiltin1(ds.RegisterIdentity(logger, "ds.identity", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterUser(logger, "ds.user", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterObject(logger, "ds.object", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterRelation(logger, "ds.relation", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterRelations(logger, "ds.relations", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterGraph(logger, "ds.graph", directoryResolver)),
-
-		// authorization check functions
-		runtime.WithBuiltin1(ds.RegisterCheck(logger, "ds.check", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterCheckRelation(logger, "ds.check_relation", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterCheckPermission(logger, "ds.check_permission", directoryResolver)),
-
-		// plugins
-		runtime.WithPlugin(decisionlog_plugin.PluginName, decisionlog_plugin.NewFactory(decisionLogger)),
-		runtime.WithPlugin(edge.PluginName, edge.NewPluginFactory(ctx, cfg, logger)),
-	)
+	decisionFilter, err := filter.New(&cfg.DecisionLogger.Filters)
 	if err != nil {
-		return nil, cleanupRuntime, err
+		return nil, func() {}, err
 	}
-	cleanup := func() {
-		if cleanupRuntime != nil {
-			cleanupRuntime()
+
+	r := &RuntimeResolver{
+		ctx:    ctx,
+		logger: logger,
+		cfg:    cfg,
+		opts: []runtime.Option{
+			// directory get functions
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterIdentity(logger, "ds.identity", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterUser(logger, "ds.user", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterObject(logger, "ds.object", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterObjects(logger, "ds.objects", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterRelation(logger, "ds.relation", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterRelations(logger, "ds.relations", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterRelationsAll(logger, "ds.relations_all", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterGraph(logger, "ds.graph", directoryResolver))),
+
+			// authorization check functions
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterCheck(logger, "ds.check", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterChecks(logger, "ds.checks", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterCheckRelation(logger, "ds.check_relation", directoryResolver))),
+			runtime.WithBuiltin1(limits.Builtin1(ds.RegisterCheckPermission(logger, "ds.check_permission", directoryResolver))),
+
+			// plugins
+			runtime.WithPlugin(decisionlog_plugin.PluginName, decisionlog_plugin.NewFactory(decisionLogger, decisionFilter, cfg.DecisionLogger.APIs)),
+			runtime.WithPlugin(edge.PluginName, edge.NewPluginFactory(ctx, cfg, logger)),
+		},
+		def: &policyRuntime{
+			key: defaultPolicyInstanceKey(cfg),
+			cfg: &cfg.OPA,
+		},
+		instances: map[string]*policyRuntime{},
+		retired:   map[*runtime.Runtime]func(){},
+	}
+
+	for i := range cfg.Policies {
+		policy := &cfg.Policies[i]
+		r.instances[policy.Key()] = &policyRuntime{
+			key: policy.Key(),
+			cfg: policyRuntimeConfig(&cfg.OPA, policy),
 		}
 	}
 
+	if cfg.Shadow.Enabled() {
+		if r.shadowed, err = r.instance(cfg.Shadow.Name, cfg.Shadow.InstanceLabel); err != nil {
+			return nil, func() {}, err
+		}
+		r.shadow = &policyRuntime{
+			key: "shadow",
+			cfg: policyRuntimeConfig(&cfg.OPA, cfg.Shadow.PolicyInstance()),
+		}
+	}
+
+	cleanup := r.cleanup
+
 	if cfg.OPA.Config.Discovery != nil && ctrlf != nil {
 		host := os.Getenv("ASERTO_HOSTNAME")
 		if host == "" {
@@ -73,38 +139,77 @@ func NewRuntimeResolver(
 		cleanupController, err := ctrlf.OnRuntimeStarted(ctx, cfg.OPA.InstanceID, "", details[0],
 
 			details[1], host, func(cmdCtx context.Context, cmd *api.Command) error {
-				return management.HandleCommand(cmdCtx, cmd, sidecarRuntime)
+				rt, err := r.load(r.def)
+				if err != nil {
+					return err
+				}
+				return management.HandleCommand(cmdCtx, cmd, rt)
 			})
 
 		cleanup = func() {
 			if cleanupController != nil {
 				cleanupController()
 			}
-			if cleanupRuntime != nil {
-				cleanupRuntime()
-			}
+			r.cleanup()
 		}
 		if err != nil {
 			return nil, cleanup, err
 		}
 	}
 
-	err = sidecarRuntime.Start(ctx)
-	if err != nil {
+	if _, err := r.load(r.def); err != nil {
 		return nil, cleanup, err
 	}
 
-	err = sidecarRuntime.WaitForPlugins(ctx, time.Duration(cfg.OPA.MaxPluginWaitTimeSeconds)*time.Second)
-	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			return nil, cleanup, aerr.ErrRuntimeLoading.Err(err).Msg("timeout while waiting for runtime to load")
+	for _, instance := range r.instances {
+		if _, err := r.load(instance); err != nil {
+			return nil, cleanup, err
+		}
+	}
+
+	if r.shadow != nil {
+		if _, err := r.load(r.shadow); err != nil {
+			return nil, cleanup, err
 		}
-		return nil, cleanup, aerr.ErrBadRuntime.Err(err)
 	}
 
-	return &RuntimeResolver{
-		runtime: sidecarRuntime,
-	}, cleanup, err
+	return r, cleanup, nil
+}
+
+// defaultPolicyInstanceKey returns the policy instance served by the default opa runtime,
+// as set by the discovery resource or the name of its single bundle.
+func defaultPolicyInstanceKey(cfg *config.Config) string {
+	if cfg.OPA.Config.Discovery != nil && cfg.OPA.Config.Discovery.Resource != nil {
+		details := strings.Split(*cfg.OPA.Config.Discovery.Resource, "/")
+		if len(details) > 1 {
+			return config.PolicyInstanceKey(details[0], details[1])
+		}
+	}
+
+	for name := range cfg.OPA.Config.Bundles {
+		return config.PolicyInstanceKey(name, "")
+	}
+
+	return ""
+}
+
+// policyRuntimeConfig derives the runtime configuration of a policy instance from the default opa configuration.
+func policyRuntimeConfig(base *runtime.Config, policy *config.PolicyInstance) *runtime.Config {
+	cfg := *base
+	cfg.LocalBundles = policy.LocalBundles
+	cfg.Config.Services = base.Config.ServicesCopy()
+	cfg.Config.Discovery = nil
+	cfg.Config.Bundles = policy.Bundles
+
+	// the edge directory is synced once per process, by the default runtime.
+	cfg.Config.Plugins = map[string]interface{}{}
+	for name, pluginCfg := range base.Config.Plugins {
+		if name != edge.PluginName {
+			cfg.Config.Plugins[name] = pluginCfg
+		}
+	}
+
+	return &cfg
 }
 
 func (r *RuntimeResolver) RuntimeFromContext(ctx context.Context, policyName, instanceLabel string) (*runtime.Runtime, error) {
@@ -112,24 +217,278 @@ func (r *RuntimeResolver) RuntimeFromContext(ctx context.Context, policyName, in
 }
 
 func (r *RuntimeResolver) GetRuntime(ctx context.Context, opaInstanceID, policyName, instanceLabel string) (*runtime.Runtime, error) {
-	return r.runtime, nil
+	instance, err := r.instance(policyName, instanceLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.load(instance)
 }
 
 func (r *RuntimeResolver) PeekRuntime(ctx context.Context, opaInstanceID, policyName, instanceLabel string) (*runtime.Runtime, error) {
-	return r.runtime, nil
+	instance, err := r.instance(policyName, instanceLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	return instance.runtime, nil
 }
 
+// ReloadRuntime starts a new runtime for the policy instance and replaces the running one,
+// the running runtime keeps serving requests when the new runtime fails to start. Unloaded instances are loaded again.
+// The replaced runtime is stopped once its in-flight evaluations are drained, see drainPeriod.
 func (r *RuntimeResolver) ReloadRuntime(ctx context.Context, opaInstanceID, policyName, instanceLabel string) error {
+	instance, err := r.instance(policyName, instanceLabel)
+	if err != nil {
+		return err
+	}
+
+	rt, cleanup, err := r.start(instance.cfg)
+	if err != nil {
+		return err
+	}
+
+	instance.mu.Lock()
+	previous, previousCleanup := instance.runtime, instance.cleanup
+	instance.runtime, instance.cleanup, instance.unloaded = rt, cleanup, false
+	instance.mu.Unlock()
+
+	r.retire(previous, previousCleanup)
+
+	r.logger.Info().Str("policy_instance", instance.key).Msg("runtime reloaded")
+
 	return nil
 }
 
+// ListRuntimes returns the running runtimes keyed by policy instance key (<name>/<label>),
+// the default runtime is keyed by the empty key when it serves no named policy.
 func (r *RuntimeResolver) ListRuntimes(ctx context.Context) (map[string]*runtime.Runtime, error) {
-	if r.runtime == nil {
+	runtimes := map[string]*runtime.Runtime{}
+
+	instances := []*policyRuntime{r.def}
+	for _, instance := range r.instances {
+		instances = append(instances, instance)
+	}
+
+	for _, instance := range instances {
+		instance.mu.RLock()
+		if instance.runtime != nil {
+			runtimes[instance.key] = instance.runtime
+		}
+		instance.mu.RUnlock()
+	}
+
+	if len(runtimes) == 0 {
 		return nil, nil
 	}
 
-	return map[string]*runtime.Runtime{r.runtime.Config.InstanceID: r.runtime}, nil
+	return runtimes, nil
 }
 
+// UnloadRuntime stops the runtime of the policy instance, the requests to the instance fail until it is reloaded.
+// The runtime is stopped once its in-flight evaluations are drained, see drainPeriod.
 func (r *RuntimeResolver) UnloadRuntime(ctx context.Context, opaInstanceID, policyName, instanceLabel string) {
+	instance, err := r.instance(policyName, instanceLabel)
+	if err != nil {
+		return
+	}
+
+	instance.mu.Lock()
+	rt, cleanup := instance.runtime, instance.cleanup
+	instance.runtime, instance.cleanup, instance.unloaded = nil, nil, true
+	instance.mu.Unlock()
+
+	if rt != nil {
+		r.retire(rt, cleanup)
+		r.logger.Info().Str("policy_instance", instance.key).Msg("runtime unloaded")
+	}
+}
+
+// ShadowRuntime returns the runtime of the shadow bundle when the policy instance is the shadowed instance, nil otherwise.
+func (r *RuntimeResolver) ShadowRuntime(ctx context.Context, policyName, instanceLabel string) (*runtime.Runtime, error) {
+	if r.shadow == nil {
+		return nil, nil
+	}
+
+	instance, err := r.instance(policyName, instanceLabel)
+	if err != nil || instance != r.shadowed {
+		return nil, err
+	}
+
+	return r.load(r.shadow)
+}
+
+// instance returns the policy instance identified by policy name and instance label.
+// Requests without a policy name, or for the policy served by the default runtime, resolve to the default runtime,
+// as do all requests when no policy instances are configured.
+func (r *RuntimeResolver) instance(policyName, instanceLabel string) (*policyRuntime, error) {
+	if policyName == "" || len(r.instances) == 0 {
+		return r.def, nil
+	}
+
+	key := config.PolicyInstanceKey(policyName, instanceLabel)
+	if instance, ok := r.instances[key]; ok {
+		return instance, nil
+	}
+
+	if key == r.def.key {
+		return r.def, nil
+	}
+
+	return nil, aerr.ErrPolicyNotFound.Msgf("policy instance %q not found", key)
+}
+
+// load returns the runtime of the policy instance, starting it when it is not running.
+// It fails when the instance is unloaded.
+func (r *RuntimeResolver) load(instance *policyRuntime) (*runtime.Runtime, error) {
+	instance.mu.RLock()
+	rt, unloaded := instance.runtime, instance.unloaded
+	instance.mu.RUnlock()
+
+	if rt != nil {
+		return rt, nil
+	}
+	if unloaded {
+		return nil, errUnloaded(instance)
+	}
+
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	if instance.runtime != nil {
+		return instance.runtime, nil
+	}
+	if instance.unloaded {
+		return nil, errUnloaded(instance)
+	}
+
+	rt, cleanup, err := r.start(instance.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	instance.runtime, instance.cleanup = rt, cleanup
+
+	return rt, nil
+}
+
+func errUnloaded(instance *policyRuntime) error {
+	return aerr.ErrRuntimeLoading.Msgf("policy instance %q is unloaded", instance.key)
+}
+
+// start creates a runtime and waits for its plugins to be ready.
+func (r *RuntimeResolver) start(cfg *runtime.Config) (*runtime.Runtime, func(), error) {
+	rt, cleanupRuntime, err := runtime.NewRuntime(r.ctx, r.logger, cfg, r.opts...)
+	if err != nil {
+		if cleanupRuntime != nil {
+			cleanupRuntime()
+		}
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		if cleanupRuntime != nil {
+			cleanupRuntime()
+		}
+	}
+
+	err = rt.Start(r.ctx)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	err = rt.WaitForPlugins(r.ctx, time.Duration(cfg.MaxPluginWaitTimeSeconds)*time.Second)
+	if err != nil {
+		cleanup()
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, nil, aerr.ErrRuntimeLoading.Err(err).Msg("timeout while waiting for runtime to load")
+		}
+		return nil, nil, aerr.ErrBadRuntime.Err(err)
+	}
+
+	return rt, cleanup, nil
+}
+
+// retire stops the runtime once the evaluations started before it was replaced had the time to complete.
+func (r *RuntimeResolver) retire(rt *runtime.Runtime, cleanup func()) {
+	if rt == nil {
+		return
+	}
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			if cleanup != nil {
+				cleanup()
+			}
+			resolvers.RuntimeStopped(rt)
+		})
+	}
+
+	r.retiredMu.Lock()
+	r.retired[rt] = stop
+	r.retiredMu.Unlock()
+
+	time.AfterFunc(r.drainPeriod(), func() { r.stopRetired(rt) })
+}
+
+// stopRetired stops the retired runtime, unless it was already stopped.
+func (r *RuntimeResolver) stopRetired(rt *runtime.Runtime) {
+	r.retiredMu.Lock()
+	stop, ok := r.retired[rt]
+	delete(r.retired, rt)
+	r.retiredMu.Unlock()
+
+	if ok {
+		stop()
+	}
+}
+
+// drainPeriod returns the time a retired runtime keeps running, the longest evaluation timeout of the authorizer APIs
+// or DefaultRuntimeDrainPeriod when the evaluations are unbounded.
+func (r *RuntimeResolver) drainPeriod() time.Duration {
+	if timeout := r.cfg.Limits.MaxTimeout(); timeout > 0 {
+		return timeout
+	}
+	return DefaultRuntimeDrainPeriod
+}
+
+// cleanup stops all running and retired runtimes.
+func (r *RuntimeResolver) cleanup() {
+	r.retiredMu.Lock()
+	retired := make([]*runtime.Runtime, 0, len(r.retired))
+	for rt := range r.retired {
+		retired = append(retired, rt)
+	}
+	r.retiredMu.Unlock()
+
+	for _, rt := range retired {
+		r.stopRetired(rt)
+	}
+
+	instances := []*policyRuntime{r.def}
+	for _, instance := range r.instances {
+		instances = append(instances, instance)
+	}
+	if r.shadow != nil {
+		instances = append(instances, r.shadow)
+	}
+
+	for _, instance := range instances {
+		instance.mu.Lock()
+		rt := instance.runtime
+		if instance.cleanup != nil {
+			instance.cleanup()
+		}
+		instance.runtime, instance.cleanup = nil, nil
+		instance.mu.Unlock()
+
+		if rt != nil {
+			resolvers.RuntimeStopped(rt)
+		}
+	}
 }
@@ -0,0 +1,166 @@
+package topaz_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	runtime "github.com/aserto-dev/runtime"
+	"github.com/aserto-dev/topaz/limits"
+	"github.com/aserto-dev/topaz/pkg/app/topaz"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/open-policy-agent/opa/server/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func policyBundle(t *testing.T, module string) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(module), 0o600))
+	return dir
+}
+
+func TestRuntimeResolverUnload(t *testing.T) {
+	ctx := context.Background()
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+
+	cfg := &config.Config{}
+	cfg.OPA.InstanceID = "test"
+	cfg.OPA.MaxPluginWaitTimeSeconds = 30
+	cfg.OPA.LocalBundles.Paths = []string{policyBundle(t, "package test\n\nallowed = true\n")}
+	cfg.OPA.LocalBundles.SkipVerification = true
+	cfg.Policies = []config.PolicyInstance{{
+		Name:         "other",
+		LocalBundles: runtime.LocalBundlesConfig{Paths: []string{policyBundle(t, "package test\n\nallowed = false\n")}, SkipVerification: true},
+	}}
+
+	r, cleanup, err := topaz.NewRuntimeResolver(ctx, &logger, cfg, nil, nil, nil)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	runtimes, err := r.ListRuntimes(ctx)
+	require.NoError(t, err)
+	assert.Len(t, runtimes, 2)
+	assert.Contains(t, runtimes, "")
+	assert.Contains(t, runtimes, "other/other")
+
+	r.UnloadRuntime(ctx, "", "other", "")
+
+	_, err = r.GetRuntime(ctx, "", "other", "")
+	assert.Error(t, err, "unloaded instance served")
+
+	runtimes, err = r.ListRuntimes(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, runtimes, "other/other")
+
+	// the default runtime is not affected.
+	_, err = r.GetRuntime(ctx, "", "", "")
+	assert.NoError(t, err)
+
+	require.NoError(t, r.ReloadRuntime(ctx, "", "other", ""))
+
+	rt, err := r.GetRuntime(ctx, "", "other", "")
+	require.NoError(t, err)
+	assert.NotNil(t, rt)
+}
+
+// stoppedRuntimes records the runtimes stopped by the runtime resolvers.
+type stoppedRuntimes struct {
+	mu       sync.Mutex
+	runtimes []*runtime.Runtime
+}
+
+func recordStoppedRuntimes(t *testing.T) *stoppedRuntimes {
+	s := &stoppedRuntimes{}
+	t.Cleanup(resolvers.OnRuntimeStopped(func(rt *runtime.Runtime) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.runtimes = append(s.runtimes, rt)
+	}))
+	return s
+}
+
+func (s *stoppedRuntimes) stopped(rt *runtime.Runtime) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stopped := range s.runtimes {
+		if stopped == rt {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRuntimeResolverReloadDrain(t *testing.T) {
+	ctx := context.Background()
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+	stopped := recordStoppedRuntimes(t)
+
+	// the evaluations of the policy wait for the release of the http server.
+	requested, release := make(chan struct{}, 1), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requested <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &config.Config{}
+	cfg.OPA.InstanceID = "test"
+	cfg.OPA.MaxPluginWaitTimeSeconds = 30
+	cfg.OPA.LocalBundles.Paths = []string{policyBundle(t, fmt.Sprintf(
+		"package test\n\nallowed { http.send({\"method\": \"GET\", \"url\": %q}).status_code == 200 }\n", srv.URL))}
+	cfg.OPA.LocalBundles.SkipVerification = true
+	cfg.Limits = limits.Config{Default: limits.Set{TimeoutMS: 1000}}
+
+	r, cleanup, err := topaz.NewRuntimeResolver(ctx, &logger, cfg, nil, nil, nil)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	previous, err := r.GetRuntime(ctx, "", "", "")
+	require.NoError(t, err)
+
+	type evaluation struct {
+		result *runtime.Result
+		err    error
+	}
+	evaluated := make(chan evaluation, 1)
+	go func() {
+		result, err := previous.Query(ctx, "x = data.test.allowed", nil, false, false, false, types.ExplainOffV1)
+		evaluated <- evaluation{result, err}
+	}()
+
+	select {
+	case <-requested:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "evaluation not started")
+	}
+
+	// the instance is reloaded during the evaluation.
+	require.NoError(t, r.ReloadRuntime(ctx, "", "", ""))
+
+	rt, err := r.GetRuntime(ctx, "", "", "")
+	require.NoError(t, err)
+	assert.NotSame(t, previous, rt)
+	assert.Never(t, func() bool { return stopped.stopped(previous) }, 200*time.Millisecond, 10*time.Millisecond,
+		"runtime stopped during an evaluation")
+
+	close(release)
+
+	e := <-evaluated
+	require.NoError(t, e.err)
+	require.Len(t, e.result.Result, 1)
+	assert.Equal(t, true, e.result.Result[0].Bindings["x"])
+
+	// the previous runtime is stopped once drained, the reloaded one keeps running.
+	assert.Eventually(t, func() bool { return stopped.stopped(previous) }, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, stopped.stopped(rt))
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aserto-dev/certs"
+	builder "github.com/aserto-dev/service-host"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// newTestService creates the authorizer service of a gRPC service with TLS certificates and an HTTP gateway,
+// client certificates are requested when clientCA is set.
+func newTestService(t *testing.T, clientCA bool) (*builder.Server, *builder.API) {
+	dir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	gen := &certs.CertGenConfig{
+		CommonName:  "authorizer",
+		CertKeyPath: filepath.Join(dir, "grpc.key"),
+		CertPath:    filepath.Join(dir, "grpc.crt"),
+		CACertPath:  filepath.Join(dir, "grpc-ca.crt"),
+		DNSNames:    []string{"localhost"},
+	}
+	require.NoError(t, certs.NewGenerator(&logger).MakeDevCert(gen))
+
+	api := &builder.API{}
+	api.GRPC.ListenAddress = "127.0.0.1:0"
+	api.GRPC.Certs = certs.TLSCredsConfig{TLSCertPath: gen.CertPath, TLSKeyPath: gen.CertKeyPath, TLSCACertPath: gen.CACertPath}
+	api.Gateway.ListenAddress = "127.0.0.1:0"
+	api.Gateway.HTTP = true
+
+	cfg := &config.Config{}
+	cfg.APIConfig.Services = map[string]*builder.API{authorizerService: api}
+	if clientCA {
+		cfg.MTLS.ClientCACertPath = gen.CACertPath
+	}
+
+	e := &Topaz{Configuration: cfg, ServiceBuilder: builder.NewServiceFactory()}
+
+	registered := false
+	server, err := e.createService(
+		api,
+		true,
+		&builder.GRPCOptions{Registrations: func(*grpc.Server) { registered = true }},
+		&builder.GatewayOptions{
+			HandlerRegistrations: func(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error { return nil },
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, registered)
+
+	go func() { _ = server.Server.Serve(server.Listener) }()
+	t.Cleanup(server.Server.Stop)
+
+	return server, api
+}
+
+// requestsClientCertificate reports whether the gRPC server of the service requests a client certificate.
+func requestsClientCertificate(t *testing.T, server *builder.Server) bool {
+	requested := false
+
+	conn, err := tls.Dial("tcp", server.Listener.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // the handshake is tested, not the server certificate.
+		NextProtos:         []string{"h2"},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			requested = true
+			return &tls.Certificate{}, nil
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	return requested
+}
+
+func TestCreateServiceClientCertificates(t *testing.T) {
+	server, api := newTestService(t, true)
+
+	assert.True(t, requestsClientCertificate(t, server))
+
+	// the service keeps its configuration and gateway.
+	assert.Same(t, api, server.Config)
+	assert.NotNil(t, server.Gateway.Server)
+	assert.NotNil(t, server.Gateway.Mux)
+}
+
+func TestCreateServiceWithoutClientCertificates(t *testing.T) {
+	server, _ := newTestService(t, false)
+
+	assert.False(t, requestsClientCertificate(t, server))
+	assert.NotNil(t, server.Gateway.Server)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/aserto-dev/logger"
 	"github.com/aserto-dev/runtime"
 	builder "github.com/aserto-dev/service-host"
+	"github.com/aserto-dev/topaz/limits"
 	"github.com/aserto-dev/topaz/pkg/debug"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -61,6 +62,8 @@ type Common struct {
 		// Specifies the duration in which exp (Expiry) and nbf (Not Before)
 		// claims may differ by. This value should be positive.
 		AcceptableTimeSkewSeconds int `json:"acceptable_time_skew_seconds"`
+		// Trusted token issuers, when set tokens of other issuers are rejected.
+		Issuers []JWTIssuer `json:"issuers"`
 	} `json:"jwt"`
 
 	// Directory configuration
@@ -71,6 +74,27 @@ type Common struct {
 
 	// Default OPA configuration
 	OPA runtime.Config `json:"opa"`
+
+	// Policy evaluation limits of the authorizer APIs
+	Limits limits.Config `json:"limits"`
+
+	// Resource context schema validation
+	ResourceSchemas ResourceSchemasConfig `json:"resource_schemas"`
+
+	// Shadow policy evaluation
+	Shadow ShadowConfig `json:"shadow"`
+
+	// Cache of the directory results of the ds builtins
+	DirectoryCache DirectoryCacheConfig `json:"directory_cache"`
+
+	// Cache of the users resolved from identities
+	IdentityCache IdentityCacheConfig `json:"identity_cache"`
+
+	// Ordered chain of the resolvers of the users of identities
+	IdentityResolvers []IdentityResolverConfig `json:"identity_resolvers"`
+
+	// Identities of the client certificates of the authorizer gRPC connections
+	MTLS MTLSConfig `json:"mtls"`
 }
 
 // LoggerConfig is a basic Config copy that gets loaded before everything else,
@@ -141,8 +165,8 @@ func NewConfig(configPath Path, log *zerolog.Logger, overrides Overrider, certsG
 			}
 		}
 
-		if configLoader.Configuration.JWT.AcceptableTimeSkewSeconds < 0 {
-			return errors.New("jwt.acceptable_time_skew_seconds must be positive or 0")
+		if err := configLoader.Configuration.validateJWT(); err != nil {
+			return err
 		}
 
 		return configLoader.Configuration.validation()
@@ -0,0 +1,33 @@
+package config
+
+import "github.com/pkg/errors"
+
+// DefaultDirectoryCacheSize is the maximum number of cached directory results when the max size is not set.
+const DefaultDirectoryCacheSize = 10000
+
+// DirectoryCacheConfig configures the cache of the directory results of the ds.check, ds.object, ds.relation and ds.graph builtins,
+// shared by all policy evaluations. The cache is dropped whenever the edge directory is written or synced.
+type DirectoryCacheConfig struct {
+	// Time to live of the cached results, the cache is disabled when 0.
+	TTLSeconds int `json:"ttl_seconds"`
+	// Maximum number of cached results, the least recently used results are evicted.
+	MaxSize int `json:"max_size"`
+}
+
+// Enabled returns true when the directory results are cached.
+func (c *DirectoryCacheConfig) Enabled() bool {
+	return c.TTLSeconds > 0
+}
+
+func (c *DirectoryCacheConfig) validate() error {
+	if c.TTLSeconds < 0 {
+		return errors.New("ttl_seconds must be positive or 0")
+	}
+	if c.MaxSize < 0 {
+		return errors.New("max_size must be positive or 0")
+	}
+	if c.MaxSize == 0 {
+		c.MaxSize = DefaultDirectoryCacheSize
+	}
+	return nil
+}
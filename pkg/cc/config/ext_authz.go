@@ -0,0 +1,79 @@
+package config
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultExtAuthzDecision     = "allowed"
+	defaultExtAuthzDeniedStatus = http.StatusForbidden
+)
+
+// ExtAuthzConfig maps the HTTP requests checked by Envoy to Is requests.
+type ExtAuthzConfig struct {
+	// Root of the policy paths of routes without policy path, e.g. todoApp.
+	PolicyRoot string `json:"policy_root"`
+	// Decision evaluated for every request (default: allowed).
+	Decision string `json:"decision"`
+	// Routes map request paths to policy paths, the first matching route applies and unmatched requests are denied.
+	Routes []ExtAuthzRoute `json:"routes"`
+	// Request headers added to the resource context, keyed by header name, valued by resource context field.
+	ResourceHeaders map[string]string `json:"resource_headers"`
+	// Headers added to the upstream request of allowed requests.
+	AllowedHeaders map[string]string `json:"allowed_headers"`
+	// Headers added to the response of denied requests.
+	DeniedHeaders map[string]string `json:"denied_headers"`
+	// HTTP status of denied requests (default: 403).
+	DeniedStatus int `json:"denied_status"`
+}
+
+// ExtAuthzRoute maps the requests matching a path template to a policy path.
+type ExtAuthzRoute struct {
+	// Methods matched by the route, all methods when empty.
+	Methods []string `json:"methods"`
+	// Path template of literal and {param} segments, e.g. /todos/{id}. The path parameters are added to the resource context.
+	Path string `json:"path"`
+	// Policy path, defaults to <policy_root>.<METHOD>.<path segments> where parameters are named __<param>, e.g. todoApp.GET.todos.__id.
+	PolicyPath string `json:"policy_path"`
+}
+
+func (c *ExtAuthzConfig) validate() error {
+	if c.Decision == "" {
+		c.Decision = defaultExtAuthzDecision
+	}
+
+	if c.DeniedStatus == 0 {
+		c.DeniedStatus = defaultExtAuthzDeniedStatus
+	}
+	if c.DeniedStatus < 400 || c.DeniedStatus > 599 {
+		return errors.Errorf("denied_status - %d is not an HTTP error status", c.DeniedStatus)
+	}
+
+	for i := range c.Routes {
+		route := &c.Routes[i]
+		if !strings.HasPrefix(route.Path, "/") {
+			return errors.Errorf("routes[%d].path - %q must start with /", i, route.Path)
+		}
+		if route.PolicyPath == "" && c.PolicyRoot == "" {
+			return errors.Errorf("routes[%d] - policy_path or policy_root must be set", i)
+		}
+		for j, method := range route.Methods {
+			route.Methods[j] = strings.ToUpper(method)
+		}
+	}
+
+	// Envoy passes header names in lower case.
+	headers := make(map[string]string, len(c.ResourceHeaders))
+	for header, field := range c.ResourceHeaders {
+		if field == "" {
+			return errors.Errorf("resource_headers.%s - resource context field not set", header)
+		}
+		headers[strings.ToLower(header)] = field
+	}
+	c.ResourceHeaders = headers
+
+	return nil
+}
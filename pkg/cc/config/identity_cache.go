@@ -0,0 +1,38 @@
+package config
+
+import "github.com/pkg/errors"
+
+// DefaultIdentityCacheSize is the maximum number of cached identities when the max size is not set.
+const DefaultIdentityCacheSize = 10000
+
+// IdentityCacheConfig configures the cache of the users resolved from identities by the authorizer.
+// The cache is dropped whenever the edge directory is written or synced.
+type IdentityCacheConfig struct {
+	// Time to live of the resolved users, the cache is disabled when 0.
+	TTLSeconds int `json:"ttl_seconds"`
+	// Time to live of the identities without user, these identities are not cached when 0.
+	NegativeTTLSeconds int `json:"negative_ttl_seconds"`
+	// Maximum number of cached identities, the least recently used identities are evicted.
+	MaxSize int `json:"max_size"`
+}
+
+// Enabled returns true when the resolved users are cached.
+func (c *IdentityCacheConfig) Enabled() bool {
+	return c.TTLSeconds > 0
+}
+
+func (c *IdentityCacheConfig) validate() error {
+	if c.TTLSeconds < 0 {
+		return errors.New("ttl_seconds must be positive or 0")
+	}
+	if c.NegativeTTLSeconds < 0 {
+		return errors.New("negative_ttl_seconds must be positive or 0")
+	}
+	if c.MaxSize < 0 {
+		return errors.New("max_size must be positive or 0")
+	}
+	if c.MaxSize == 0 {
+		c.MaxSize = DefaultIdentityCacheSize
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Identity resolver types.
+const (
+	// IdentityResolverRelation resolves the subject of the relation of the identity object.
+	IdentityResolverRelation = "identity_relation"
+	// IdentityResolverObject resolves the object whose id is the identity.
+	IdentityResolverObject = "object"
+	// IdentityResolverClaims resolves a user built from the validated claims of JWT identities.
+	IdentityResolverClaims = "claims"
+	// IdentityResolverStatic resolves the users of a static users file.
+	IdentityResolverStatic = "static"
+)
+
+// IdentityResolverConfig configures a resolver of the users of identities.
+type IdentityResolverConfig struct {
+	// Type of the resolver: identity_relation, object, claims or static.
+	Type string `json:"type"`
+	// Object type of the identity objects (identity_relation, default: identity), of the looked up objects (object, default: user)
+	// or of the users built from claims (claims, default: user).
+	ObjectType string `json:"object_type"`
+	// Relation of the identity objects to their subject (identity_relation, default: identifier).
+	Relation string `json:"relation"`
+	// Subject type of the relation (identity_relation, default: user).
+	SubjectType string `json:"subject_type"`
+	// Claim holding the id of the users built from claims (claims, default: sub).
+	IDClaim string `json:"id_claim"`
+	// Path of the users file, a YAML or JSON map of identities to user objects (static).
+	Path string `json:"path"`
+}
+
+// DefaultIdentityResolvers returns the resolvers used when none is configured, the subject of the identity:identifier
+// relation of the identity and, when the identity has no identity object, the user whose id is the identity.
+func DefaultIdentityResolvers() []IdentityResolverConfig {
+	return []IdentityResolverConfig{
+		{Type: IdentityResolverRelation, ObjectType: "identity", Relation: "identifier", SubjectType: "user"},
+		{Type: IdentityResolverObject, ObjectType: "user"},
+	}
+}
+
+func (c *IdentityResolverConfig) validate() error {
+	switch c.Type {
+	case IdentityResolverRelation:
+		c.ObjectType = valueOrDefault(c.ObjectType, "identity")
+		c.Relation = valueOrDefault(c.Relation, "identifier")
+		c.SubjectType = valueOrDefault(c.SubjectType, "user")
+	case IdentityResolverObject:
+		c.ObjectType = valueOrDefault(c.ObjectType, "user")
+	case IdentityResolverClaims:
+		c.ObjectType = valueOrDefault(c.ObjectType, "user")
+		c.IDClaim = valueOrDefault(c.IDClaim, "sub")
+	case IdentityResolverStatic:
+		if c.Path == "" {
+			return errors.New("path not set")
+		}
+		if _, err := os.Stat(c.Path); err != nil {
+			return errors.Wrap(err, "path")
+		}
+	default:
+		return errors.Errorf("unknown type %q, must be one of %s, %s, %s or %s", c.Type,
+			IdentityResolverRelation, IdentityResolverObject, IdentityResolverClaims, IdentityResolverStatic)
+	}
+
+	return nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
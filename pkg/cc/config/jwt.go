@@ -0,0 +1,85 @@
+package config
+
+import (
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/pkg/errors"
+)
+
+// JWTIssuer configures an issuer trusted to sign identity tokens.
+type JWTIssuer struct {
+	// Issuer, matched against the iss claim of the token.
+	Issuer string `json:"issuer"`
+	// URL of the issuer JWKS, when no keys are configured the JWKS URL is discovered from the issuer.
+	JWKSURL string `json:"jwks_url"`
+	// Path of a static JWKS file.
+	JWKSFile string `json:"jwks_file"`
+	// Inline static JWKS document.
+	JWKS string `json:"jwks"`
+	// The aud claim of the token must contain at least one of the audiences, when set.
+	Audiences []string `json:"audiences"`
+	// Signature algorithms accepted for the issuer tokens, when set.
+	Algorithms []string `json:"algorithms"`
+	// Claims mapped to the identity of the token, defaults to the sub claim.
+	ClaimMapping JWTClaimMapping `json:"claim_mapping"`
+}
+
+// JWTClaimMapping selects the token claim holding the identity.
+type JWTClaimMapping struct {
+	// Primary identity claim.
+	Identity string `json:"identity"`
+	// Claims used, in order, when the primary identity claim is not present in the token.
+	Fallbacks []string `json:"fallbacks"`
+}
+
+// Claims returns the identity claims in the order they are looked up.
+func (m *JWTClaimMapping) Claims() []string {
+	claims := []string{}
+	if m.Identity != "" {
+		claims = append(claims, m.Identity)
+	}
+	return append(claims, m.Fallbacks...)
+}
+
+func (c *Common) validateJWT() error {
+	if c.JWT.AcceptableTimeSkewSeconds < 0 {
+		return errors.New("jwt.acceptable_time_skew_seconds must be positive or 0")
+	}
+
+	issuers := map[string]bool{}
+	for i, issuer := range c.JWT.Issuers {
+		if issuer.Issuer == "" {
+			return errors.Errorf("jwt.issuers[%d].issuer not set", i)
+		}
+
+		if issuers[issuer.Issuer] {
+			return errors.Errorf("jwt.issuers[%d] - duplicate issuer %q", i, issuer.Issuer)
+		}
+		issuers[issuer.Issuer] = true
+
+		sources := 0
+		for _, source := range []string{issuer.JWKSURL, issuer.JWKSFile, issuer.JWKS} {
+			if source != "" {
+				sources++
+			}
+		}
+		if sources > 1 {
+			return errors.Errorf("jwt.issuers[%d] - only one of jwks_url, jwks_file or jwks can be set", i)
+		}
+
+		if issuer.ClaimMapping.Identity == "" && len(issuer.ClaimMapping.Fallbacks) > 0 {
+			return errors.Errorf("jwt.issuers[%d].claim_mapping.identity not set", i)
+		}
+
+		for _, alg := range issuer.Algorithms {
+			var algorithm jwa.SignatureAlgorithm
+			if err := algorithm.Accept(alg); err != nil {
+				return errors.Wrapf(err, "jwt.issuers[%d].algorithms", i)
+			}
+			if algorithm == jwa.NoSignature {
+				return errors.Errorf("jwt.issuers[%d].algorithms - algorithm %q not allowed", i, alg)
+			}
+		}
+	}
+
+	return nil
+}
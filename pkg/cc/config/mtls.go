@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// Sources of the identities of client certificates.
+const (
+	// CertIdentityURISAN is the first URI subject alternative name of the certificate.
+	CertIdentityURISAN = "uri_san"
+	// CertIdentityDNSSAN is the first DNS subject alternative name of the certificate.
+	CertIdentityDNSSAN = "dns_san"
+	// CertIdentityCommonName is the subject common name of the certificate.
+	CertIdentityCommonName = "common_name"
+)
+
+// Identities derived from SPIFFE IDs.
+const (
+	// SPIFFEIdentityID is the SPIFFE ID, e.g. spiffe://acme.com/ns/prod/sa/billing.
+	SPIFFEIdentityID = "id"
+	// SPIFFEIdentityPath is the workload path of the SPIFFE ID, without leading slash, e.g. ns/prod/sa/billing.
+	SPIFFEIdentityPath = "path"
+)
+
+// MTLSConfig configures the identities derived from the verified client certificates of the authorizer gRPC connections.
+type MTLSConfig struct {
+	// CA certificates verifying the client certificates, client certificates are requested when set.
+	ClientCACertPath string `json:"client_ca_cert_path"`
+	// Ordered sources of the certificate identity: uri_san, dns_san or common_name (default: all, in this order).
+	IdentitySources []string `json:"identity_sources"`
+	// SPIFFE ID parsing of spiffe:// URI SANs.
+	SPIFFE SPIFFEConfig `json:"spiffe"`
+	// Directory object type of the certificate identities, the identity resolvers resolve the identities when not set.
+	SubjectType string `json:"subject_type"`
+	// Use the certificate identity of requests sent without identity context.
+	Fallback bool `json:"fallback"`
+}
+
+// SPIFFEConfig configures the parsing of SPIFFE IDs.
+type SPIFFEConfig struct {
+	// Trusted trust domains, when set the SPIFFE IDs of other trust domains are rejected.
+	TrustDomains []string `json:"trust_domains"`
+	// Identity derived from the SPIFFE ID: id (default) or path.
+	Identity string `json:"identity"`
+}
+
+// Enabled returns true when client certificates are requested.
+func (c *MTLSConfig) Enabled() bool {
+	return c.ClientCACertPath != ""
+}
+
+func (c *MTLSConfig) validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	if _, err := os.Stat(c.ClientCACertPath); err != nil {
+		return errors.Wrap(err, "client_ca_cert_path")
+	}
+
+	if len(c.IdentitySources) == 0 {
+		c.IdentitySources = []string{CertIdentityURISAN, CertIdentityDNSSAN, CertIdentityCommonName}
+	}
+	for _, source := range c.IdentitySources {
+		if !lo.Contains([]string{CertIdentityURISAN, CertIdentityDNSSAN, CertIdentityCommonName}, source) {
+			return errors.Errorf("identity_sources - unknown source %q, must be one of %s, %s or %s",
+				source, CertIdentityURISAN, CertIdentityDNSSAN, CertIdentityCommonName)
+		}
+	}
+
+	switch c.SPIFFE.Identity {
+	case "":
+		c.SPIFFE.Identity = SPIFFEIdentityID
+	case SPIFFEIdentityID, SPIFFEIdentityPath:
+	default:
+		return errors.Errorf("spiffe.identity - unknown identity %q, must be %s or %s", c.SPIFFE.Identity, SPIFFEIdentityID, SPIFFEIdentityPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// ProxyConfig configures the authorizing reverse proxy, requests are authorized with route rules
+// taking the settings of the ext_authz section, and allowed requests are forwarded to the upstream URL.
+type ProxyConfig struct {
+	// URL of the upstream server.
+	Upstream string `json:"upstream"`
+
+	PolicyRoot      string            `json:"policy_root"`
+	Decision        string            `json:"decision"`
+	Routes          []ExtAuthzRoute   `json:"routes"`
+	ResourceHeaders map[string]string `json:"resource_headers"`
+	// Headers added to the forwarded requests.
+	AllowedHeaders map[string]string `json:"allowed_headers"`
+	DeniedHeaders  map[string]string `json:"denied_headers"`
+	DeniedStatus   int               `json:"denied_status"`
+}
+
+// Authorization returns the route rules authorizing the proxied requests.
+func (c *ProxyConfig) Authorization() *ExtAuthzConfig {
+	return &ExtAuthzConfig{
+		PolicyRoot:      c.PolicyRoot,
+		Decision:        c.Decision,
+		Routes:          c.Routes,
+		ResourceHeaders: c.ResourceHeaders,
+		AllowedHeaders:  c.AllowedHeaders,
+		DeniedHeaders:   c.DeniedHeaders,
+		DeniedStatus:    c.DeniedStatus,
+	}
+}
+
+func (c *ProxyConfig) validate() error {
+	if c.Upstream != "" {
+		upstream, err := url.Parse(c.Upstream)
+		if err != nil {
+			return errors.Wrap(err, "upstream")
+		}
+		if upstream.Scheme != "http" && upstream.Scheme != "https" {
+			return errors.Errorf("upstream - %q is not an http or https URL", c.Upstream)
+		}
+	}
+
+	authz := c.Authorization()
+	if err := authz.validate(); err != nil {
+		return err
+	}
+
+	// keep the defaults and normalized headers of the route rules.
+	c.Decision, c.DeniedStatus, c.ResourceHeaders = authz.Decision, authz.DeniedStatus, authz.ResourceHeaders
+
+	return nil
+}
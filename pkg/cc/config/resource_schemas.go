@@ -0,0 +1,35 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Resource context schema validation modes.
+const (
+	// SchemaValidationOff does not validate resource contexts.
+	SchemaValidationOff = "off"
+	// SchemaValidationWarn logs the resource contexts that do not match their schema.
+	SchemaValidationWarn = "warn"
+	// SchemaValidationEnforce rejects the requests whose resource context does not match its schema.
+	SchemaValidationEnforce = "enforce"
+)
+
+// ResourceSchemasConfig configures the validation of resource contexts against the JSON schemas shipped with the policy,
+// the schema of a policy path is read from the schemas/<path>.json file of the local bundles, or else from data.schemas["<path>"].
+type ResourceSchemasConfig struct {
+	// Validation mode: off (default), warn or enforce.
+	Validation string `json:"validation"`
+}
+
+func (c *ResourceSchemasConfig) validate() error {
+	switch c.Validation {
+	case "":
+		c.Validation = SchemaValidationOff
+		return nil
+	case SchemaValidationOff, SchemaValidationWarn, SchemaValidationEnforce:
+		return nil
+	default:
+		return errors.Errorf("unknown validation mode %q, must be one of %s, %s or %s",
+			c.Validation, SchemaValidationOff, SchemaValidationWarn, SchemaValidationEnforce)
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	runtime "github.com/aserto-dev/runtime"
+	bundleplugin "github.com/open-policy-agent/opa/plugins/bundle"
+	"github.com/pkg/errors"
+)
+
+// DefaultShadowMaxConcurrency is the maximum number of concurrent shadow evaluations when the maximum is not set.
+const DefaultShadowMaxConcurrency = 100
+
+// ShadowConfig configures a shadow policy bundle. The Is requests of the shadowed policy instance are also evaluated
+// against the shadow bundle, the decisions whose shadow outcome differs are reported to the decision log.
+type ShadowConfig struct {
+	// Shadowed policy instance, defaults to the policy served by the default opa runtime.
+	Name          string                          `json:"name"`
+	InstanceLabel string                          `json:"instance_label"`
+	LocalBundles  runtime.LocalBundlesConfig      `json:"local_bundles"`
+	Bundles       map[string]*bundleplugin.Source `json:"bundles"`
+	// Maximum number of concurrent shadow evaluations, requests are not shadowed while the maximum is reached.
+	MaxConcurrency int `json:"max_concurrency"`
+}
+
+// Enabled returns true when a shadow bundle is configured.
+func (c *ShadowConfig) Enabled() bool {
+	return len(c.Bundles) > 0 || len(c.LocalBundles.Paths) > 0 || c.LocalBundles.LocalPolicyImage != ""
+}
+
+// PolicyInstance returns the policy instance serving the shadow bundle.
+func (c *ShadowConfig) PolicyInstance() *PolicyInstance {
+	return &PolicyInstance{
+		Name:          c.Name,
+		InstanceLabel: c.InstanceLabel,
+		LocalBundles:  c.LocalBundles,
+		Bundles:       c.Bundles,
+	}
+}
+
+func (c *ShadowConfig) validate() error {
+	if c.MaxConcurrency < 0 {
+		return errors.New("max_concurrency must be positive or 0")
+	}
+	if c.MaxConcurrency == 0 {
+		c.MaxConcurrency = DefaultShadowMaxConcurrency
+	}
+	if len(c.Bundles) > 1 {
+		return errors.New("bundles - too many bundles")
+	}
+	return nil
+}
@@ -4,7 +4,13 @@ import (
 	"strings"
 
 	"github.com/aserto-dev/aserto-management/controller"
+	runtime "github.com/aserto-dev/runtime"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/filter"
+	"github.com/aserto-dev/topaz/decision_log/logger/async"
+	bundleplugin "github.com/open-policy-agent/opa/plugins/bundle"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 )
 
 const ConfigFileVersion = 2
@@ -14,11 +20,55 @@ type Config struct {
 	Auth             AuthnConfig        `json:"auth"`
 	DecisionLogger   DecisionLogConfig  `json:"decision_logger"`
 	ControllerConfig *controller.Config `json:"controller"`
+	Policies         []PolicyInstance   `json:"policies"`
+	ExtAuthz         ExtAuthzConfig     `json:"ext_authz"`
+	Proxy            ProxyConfig        `json:"proxy"`
+}
+
+// PolicyInstance configures a named policy instance, served by its own runtime next to the default opa runtime.
+// Apart from the bundles, the runtime settings are inherited from the opa section.
+type PolicyInstance struct {
+	Name          string                          `json:"name"`
+	InstanceLabel string                          `json:"instance_label"`
+	LocalBundles  runtime.LocalBundlesConfig      `json:"local_bundles"`
+	Bundles       map[string]*bundleplugin.Source `json:"bundles"`
+}
+
+// Key returns the policy instance key "<name>/<instance_label>", the instance label defaults to the name.
+func (p *PolicyInstance) Key() string {
+	return PolicyInstanceKey(p.Name, p.InstanceLabel)
+}
+
+// PolicyInstanceKey returns the key identifying a policy instance, the instance label defaults to the policy name.
+func PolicyInstanceKey(policyName, instanceLabel string) string {
+	if instanceLabel == "" {
+		instanceLabel = policyName
+	}
+	return policyName + "/" + instanceLabel
 }
 
 type DecisionLogConfig struct {
-	Type   string                 `json:"type"`
-	Config map[string]interface{} `json:"config"`
+	Type    string                 `json:"type"`
+	Config  map[string]interface{} `json:"config"`
+	Async   async.Config           `json:"async"`
+	Filters filter.Config          `json:"filters"`
+	// Authorizer APIs whose decisions are logged, defaults to is.
+	APIs []string `json:"apis"`
+}
+
+// validateAPIs checks the audited APIs, defaulting to the Is API.
+func (c *DecisionLogConfig) validateAPIs() error {
+	if c.APIs == nil {
+		c.APIs = []string{decisionlog.APIIs}
+	}
+
+	for _, api := range c.APIs {
+		if !lo.Contains(decisionlog.APIs, api) {
+			return errors.Errorf("unknown api %q, must be one of %s", api, strings.Join(decisionlog.APIs, ", "))
+		}
+	}
+
+	return nil
 }
 
 type AuthnConfig struct {
@@ -71,10 +121,63 @@ func (c *Config) validation() error {
 			return errors.New("opa.instance_id not set")
 		}
 		if len(c.OPA.Config.Bundles) > 1 {
-			return errors.New("opa.config.bundles - too many bundles")
+			return errors.New("opa.config.bundles - too many bundles, use policies to serve multiple bundles")
+		}
+		if err := c.validatePolicies(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Limits.Validate(); err != nil {
+		return errors.Wrap(err, "limits")
+	}
+
+	if err := c.ResourceSchemas.validate(); err != nil {
+		return errors.Wrap(err, "resource_schemas.validation")
+	}
+
+	if err := c.Shadow.validate(); err != nil {
+		return errors.Wrap(err, "shadow")
+	}
+
+	if err := c.DirectoryCache.validate(); err != nil {
+		return errors.Wrap(err, "directory_cache")
+	}
+
+	if err := c.IdentityCache.validate(); err != nil {
+		return errors.Wrap(err, "identity_cache")
+	}
+
+	for i := range c.IdentityResolvers {
+		if err := c.IdentityResolvers[i].validate(); err != nil {
+			return errors.Wrapf(err, "identity_resolvers[%d]", i)
 		}
 	}
 
+	if err := c.MTLS.validate(); err != nil {
+		return errors.Wrap(err, "mtls")
+	}
+
+	if err := c.ExtAuthz.validate(); err != nil {
+		return errors.Wrap(err, "ext_authz")
+	}
+
+	if err := c.Proxy.validate(); err != nil {
+		return errors.Wrap(err, "proxy")
+	}
+
+	if err := c.DecisionLogger.Async.Validate(); err != nil {
+		return errors.Wrap(err, "decision_logger.async")
+	}
+
+	if err := c.DecisionLogger.Filters.Validate(); err != nil {
+		return errors.Wrap(err, "decision_logger.filters")
+	}
+
+	if err := c.DecisionLogger.validateAPIs(); err != nil {
+		return errors.Wrap(err, "decision_logger.apis")
+	}
+
 	if len(c.APIConfig.Services) == 0 {
 		return errors.New("no api services configured")
 	}
@@ -90,6 +193,32 @@ func (c *Config) validation() error {
 	return nil
 }
 
+func (c *Config) validatePolicies() error {
+	keys := map[string]bool{}
+	for i := range c.Policies {
+		policy := &c.Policies[i]
+		if policy.Name == "" {
+			return errors.Errorf("policies[%d].name not set", i)
+		}
+		if strings.Contains(policy.Name, "/") || strings.Contains(policy.InstanceLabel, "/") {
+			return errors.Errorf("policies[%d] - name and instance_label must not contain '/'", i)
+		}
+		if keys[policy.Key()] {
+			return errors.Errorf("policies[%d] - duplicate policy instance %q", i, policy.Key())
+		}
+		keys[policy.Key()] = true
+
+		if len(policy.Bundles) > 1 {
+			return errors.Errorf("policies[%d].bundles - too many bundles", i)
+		}
+		if len(policy.Bundles) == 0 && len(policy.LocalBundles.Paths) == 0 && policy.LocalBundles.LocalPolicyImage == "" {
+			return errors.Errorf("policies[%d] - no bundle configured", i)
+		}
+	}
+
+	return nil
+}
+
 func setDefaultCallsAuthz(cfg *Config) {
 	if len(cfg.Auth.Options.Overrides) == 0 {
 		infoPath := OptionOverrides{
@@ -12,6 +12,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
 	"github.com/aserto-dev/topaz/pkg/cli/cc"
 )
 
@@ -24,6 +25,25 @@ type AuthorizerConfig struct {
 }
 
 func NewAuthorizerClient(c *cc.CommonCtx, cfg *AuthorizerConfig) (authorizer.AuthorizerClient, error) {
+	conn, err := newAuthorizerConnection(c, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return authorizer.NewAuthorizerClient(conn), nil
+}
+
+// NewTopazAuthorizerClient returns a client of the topaz authorizer extension APIs (batch evaluation).
+func NewTopazAuthorizerClient(c *cc.CommonCtx, cfg *AuthorizerConfig) (azv1.AuthorizerClient, error) {
+	conn, err := newAuthorizerConnection(c, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return azv1.NewAuthorizerClient(conn), nil
+}
+
+func newAuthorizerConnection(c *cc.CommonCtx, cfg *AuthorizerConfig) (*grpc.ClientConn, error) {
 	if cfg.Host == "" {
 		return nil, fmt.Errorf("no host specified")
 	}
@@ -49,12 +69,7 @@ func NewAuthorizerClient(c *cc.CommonCtx, cfg *AuthorizerConfig) (authorizer.Aut
 		opts = append(opts, azc.WithTenantID(cfg.TenantID))
 	}
 
-	conn, err := azc.NewConnection(c.Context, opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	return authorizer.NewAuthorizerClient(conn), nil
+	return azc.NewConnection(c.Context, opts...)
 }
 
 func (cfg *AuthorizerConfig) validate() error {
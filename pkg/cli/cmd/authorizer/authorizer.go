@@ -7,4 +7,5 @@ type AuthorizerCmd struct {
 	GetPolicy     GetPolicyCmd    `cmd:"" help:"get policy"`
 	ListPolicies  ListPoliciesCmd `cmd:"" help:"list policies"`
 	Test          TestCmd         `cmd:"" help:"execute authorizer assertions"`
+	Replay        ReplayCmd       `cmd:"" help:"replay recorded decisions against a candidate policy bundle"`
 }
@@ -3,6 +3,7 @@ package authorizer
 import (
 	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
 	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	azv1 "github.com/aserto-dev/topaz/api/topaz/authorizer/v1"
 	"github.com/aserto-dev/topaz/pkg/cli/cc"
 	"github.com/aserto-dev/topaz/pkg/cli/clients"
 	"github.com/aserto-dev/topaz/pkg/cli/edit"
@@ -18,13 +19,28 @@ type EvalCmd struct {
 	Request  string `arg:"" type:"string" name:"request" optional:"" help:"json request or file path to eval policy request or '-' to read from stdin"`
 	Template bool   `name:"template" short:"t" help:"prints a check permission request template on stdout"`
 	Editor   bool   `name:"edit" short:"e" help:"edit request" hidden:"" type:"fflag.Editor"`
+	Batch    bool   `name:"batch" help:"evaluate a batch of policy decision requests in a single call"`
+	Explain  string `name:"explain" enum:"off,full,notes,fails" default:"off" help:"explain the decisions (off, full, notes, fails)"`
 	clients.AuthorizerConfig
 }
 
 func (cmd *EvalCmd) Run(c *cc.CommonCtx) error {
+	if cmd.Template && cmd.Batch {
+		return jsonx.OutputJSONPB(c.UI.Output(), cmd.batchTemplate())
+	}
+
 	if cmd.Template {
 		return jsonx.OutputJSONPB(c.UI.Output(), cmd.template())
 	}
+
+	if cmd.Batch {
+		return cmd.runBatch(c)
+	}
+
+	if cmd.Explain != "off" {
+		return cmd.runExplain(c)
+	}
+
 	client, err := clients.NewAuthorizerClient(c, &cmd.AuthorizerConfig)
 	if err != nil {
 		return errors.Wrap(err, "failed to get authorizer client")
@@ -81,3 +97,76 @@ func (cmd *EvalCmd) template() proto.Message {
 		ResourceContext: &structpb.Struct{},
 	}
 }
+
+func (cmd *EvalCmd) runBatch(c *cc.CommonCtx) error {
+	client, err := clients.NewTopazAuthorizerClient(c, &cmd.AuthorizerConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to get authorizer client")
+	}
+
+	if cmd.Request == "" && cmd.Editor && fflag.Enabled(fflag.Editor) {
+		req, err := edit.Msg(cmd.batchTemplate())
+		if err != nil {
+			return err
+		}
+		cmd.Request = req
+	}
+
+	if cmd.Request == "" {
+		return errors.New("request argument is required")
+	}
+
+	var req azv1.IsBatchRequest
+	err = clients.UnmarshalRequest(cmd.Request, &req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.IsBatch(c.Context, &req)
+	if err != nil {
+		return err
+	}
+
+	return jsonx.OutputJSONPB(c.UI.Output(), resp)
+}
+
+func (cmd *EvalCmd) runExplain(c *cc.CommonCtx) error {
+	client, err := clients.NewTopazAuthorizerClient(c, &cmd.AuthorizerConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to get authorizer client")
+	}
+
+	if cmd.Request == "" {
+		return errors.New("request argument is required")
+	}
+
+	var req authorizer.IsRequest
+	err = clients.UnmarshalRequest(cmd.Request, &req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.IsExplain(c.Context, &azv1.IsExplainRequest{
+		Request: &req,
+		Trace:   traceLevels[cmd.Explain],
+	})
+	if err != nil {
+		return err
+	}
+
+	return jsonx.OutputJSONPB(c.UI.Output(), resp)
+}
+
+var traceLevels = map[string]authorizer.TraceLevel{
+	"full":  authorizer.TraceLevel_TRACE_LEVEL_FULL,
+	"notes": authorizer.TraceLevel_TRACE_LEVEL_NOTES,
+	"fails": authorizer.TraceLevel_TRACE_LEVEL_FAILS,
+}
+
+func (cmd *EvalCmd) batchTemplate() proto.Message {
+	return &azv1.IsBatchRequest{
+		Requests: []*authorizer.IsRequest{
+			cmd.template().(*authorizer.IsRequest),
+		},
+	}
+}
@@ -0,0 +1,255 @@
+package authorizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aserto-dev/go-aserto/client"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/pkg/app/directory"
+	"github.com/aserto-dev/topaz/pkg/app/impl"
+	"github.com/aserto-dev/topaz/pkg/app/topaz"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+	"github.com/aserto-dev/topaz/pkg/cli/clients"
+	"github.com/aserto-dev/topaz/pkg/cli/cmd/decisions"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const replayInstanceID = "replay"
+
+type ReplayCmd struct {
+	decisions.LogFileFlags
+	Bundle       string `name:"bundle" required:"" help:"candidate policy bundle directory or bundle file"`
+	Format       string `name:"format" short:"o" enum:"table,json" default:"table" help:"report format (table, json)"`
+	FailOnChange bool   `name:"fail-on-change" help:"exit with an error when an outcome changes"`
+	clients.DirectoryConfig
+}
+
+// replayResult is the report entry of a replayed decision with changed outcomes.
+type replayResult struct {
+	ID        string          `json:"id"`
+	Timestamp time.Time       `json:"timestamp"`
+	Path      string          `json:"path"`
+	User      string          `json:"user"`
+	Changes   []outcomeChange `json:"changes,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+type outcomeChange struct {
+	Decision string `json:"decision"`
+	Recorded bool   `json:"recorded"`
+	Replayed bool   `json:"replayed"`
+}
+
+// Run evaluates the recorded decisions against the candidate bundle and reports the decisions with changed outcomes.
+//
+// Identities are resolved and ds builtins are evaluated against the directory service,
+// the JWT settings of the active configuration apply to the replayed identities.
+func (cmd *ReplayCmd) Run(c *cc.CommonCtx) error {
+	logPath, err := cmd.LogPath(c)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(cmd.Bundle); err != nil {
+		return errors.Wrap(err, "failed to open bundle")
+	}
+
+	cfg, err := cmd.config(c)
+	if err != nil {
+		return err
+	}
+
+	logger := zerolog.Nop()
+
+	dirResolver := directory.NewResolver(&logger, &cfg.DirectoryResolver)
+
+	rtResolver, cleanup, err := topaz.NewRuntimeResolver(c.Context, &logger, cfg, nil, nil, dirResolver)
+	defer cleanup()
+	if err != nil {
+		return errors.Wrapf(err, "failed to load bundle %s", cmd.Bundle)
+	}
+
+	rf := resolvers.New()
+	rf.SetRuntimeResolver(rtResolver)
+	rf.SetDirectoryResolver(dirResolver)
+
+	authServer, err := impl.NewAuthorizerServer(c.Context, &logger, &cfg.Common, rf)
+	if err != nil {
+		return err
+	}
+
+	var (
+		results  []*replayResult
+		replayed int
+		failed   int
+	)
+
+	err = decisions.ReadDecisions(logPath, func(d *api.Decision) error {
+		if err := c.Context.Err(); err != nil {
+			return err
+		}
+
+		// only Is decisions record outcomes that can be compared.
+		if decisionlog.DecisionAPI(d.GetAnnotations()) != decisionlog.APIIs {
+			return nil
+		}
+
+		replayed++
+
+		result := replay(c, authServer, d)
+		if result == nil {
+			return nil
+		}
+
+		if result.Error != "" {
+			failed++
+		}
+		results = append(results, result)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.report(c, results); err != nil {
+		return err
+	}
+
+	changed := len(results) - failed
+	fmt.Fprintf(c.UI.Err(), "replayed %d decisions: %d changed, %d failed\n", replayed, changed, failed)
+
+	if cmd.FailOnChange && changed > 0 {
+		return errors.Errorf("%d decisions changed", changed)
+	}
+
+	return nil
+}
+
+// config returns the configuration of the replay runtime, serving the candidate bundle as the default runtime.
+func (cmd *ReplayCmd) config(c *cc.CommonCtx) (*config.Config, error) {
+	cfg := &config.Config{}
+
+	if _, err := os.Stat(c.Config.Active.ConfigFile); err == nil {
+		loader, err := config.LoadConfiguration(c.Config.Active.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.JWT = loader.Configuration.JWT
+	}
+
+	cfg.DirectoryResolver = client.Config{
+		Address:  cmd.DirectoryConfig.Host,
+		APIKey:   cmd.DirectoryConfig.APIKey,
+		Token:    cmd.DirectoryConfig.Token,
+		TenantID: cmd.DirectoryConfig.TenantID,
+		Insecure: cmd.DirectoryConfig.Insecure,
+	}
+
+	cfg.OPA.InstanceID = replayInstanceID
+	cfg.OPA.MaxPluginWaitTimeSeconds = 30
+	cfg.OPA.LocalBundles.Paths = []string{cmd.Bundle}
+	cfg.OPA.LocalBundles.SkipVerification = true
+
+	return cfg, nil
+}
+
+// replay evaluates a recorded decision, it returns nil when none of the recorded outcomes changed.
+func replay(c *cc.CommonCtx, authServer *impl.AuthorizerServer, d *api.Decision) *replayResult {
+	result := &replayResult{
+		ID:        d.GetId(),
+		Timestamp: d.GetTimestamp().AsTime(),
+		Path:      d.GetPolicy().GetContext().GetPath(),
+		User:      decisionUser(d),
+	}
+
+	// the candidate bundle is served by the default runtime, the recorded policy instance does not apply.
+	resp, err := authServer.Is(c.Context, &authorizer.IsRequest{
+		PolicyContext:   d.GetPolicy().GetContext(),
+		IdentityContext: d.GetUser().GetContext(),
+		ResourceContext: d.GetResource(),
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	for _, decision := range resp.Decisions {
+		recorded, ok := d.GetOutcomes()[decision.Decision]
+		if !ok || recorded == decision.Is {
+			continue
+		}
+		result.Changes = append(result.Changes, outcomeChange{
+			Decision: decision.Decision,
+			Recorded: recorded,
+			Replayed: decision.Is,
+		})
+	}
+
+	if len(result.Changes) == 0 {
+		return nil
+	}
+
+	sort.Slice(result.Changes, func(i, j int) bool {
+		return result.Changes[i].Decision < result.Changes[j].Decision
+	})
+
+	return result
+}
+
+func decisionUser(d *api.Decision) string {
+	switch {
+	case d.GetUser().GetEmail() != "":
+		return d.GetUser().GetEmail()
+	case d.GetUser().GetId() != "":
+		return d.GetUser().GetId()
+	default:
+		return d.GetUser().GetContext().GetIdentity()
+	}
+}
+
+func (cmd *ReplayCmd) report(c *cc.CommonCtx, results []*replayResult) error {
+	if cmd.Format == "json" {
+		enc := json.NewEncoder(c.UI.Output())
+		for _, result := range results {
+			if err := enc.Encode(result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	table := c.UI.Normal().WithTable("Time", "Path", "User", "Decision", "Recorded", "Replayed", "ID")
+	table.WithTableNoAutoWrapText()
+
+	for _, result := range results {
+		ts := result.Timestamp.Format(time.RFC3339)
+
+		if result.Error != "" {
+			table.WithTableRow(ts, result.Path, result.User, "", "", "error: "+result.Error, result.ID)
+			continue
+		}
+
+		for _, change := range result.Changes {
+			table.WithTableRow(ts, result.Path, result.User, change.Decision,
+				fmt.Sprint(change.Recorded), fmt.Sprint(change.Replayed), result.ID)
+		}
+	}
+
+	table.Do()
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package authorizer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/clui"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+	"github.com/aserto-dev/topaz/pkg/cli/cmd/decisions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const candidatePolicy = `package todo
+
+default allowed = false
+
+allowed {
+	input.resource.id == "1"
+}
+
+visible = false
+`
+
+func recordedDecision(id, resourceID string, outcomes map[string]bool) *api.Decision {
+	names := make([]string, 0, len(outcomes))
+	for name := range outcomes {
+		names = append(names, name)
+	}
+
+	return &api.Decision{
+		Id:        id,
+		Timestamp: timestamppb.New(time.Now()),
+		Path:      "todo",
+		User:      &api.DecisionUser{Id: "u" + id, Context: &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_NONE}},
+		Policy:    &api.DecisionPolicy{Context: &api.PolicyContext{Path: "todo", Decisions: names}},
+		Resource:  &structpb.Struct{Fields: map[string]*structpb.Value{"id": structpb.NewStringValue(resourceID)}},
+		Outcomes:  outcomes,
+	}
+}
+
+// writeDecisionLog writes the decisions as the file decision logger does.
+func writeDecisionLog(t *testing.T, ds ...*api.Decision) string {
+	buf := &bytes.Buffer{}
+	for _, d := range ds {
+		msg, err := json.Marshal(d)
+		require.NoError(t, err)
+
+		line, err := json.Marshal(map[string]string{"level": "info", "message": string(msg)})
+		require.NoError(t, err)
+
+		buf.Write(append(line, '\n'))
+	}
+
+	logPath := filepath.Join(t.TempDir(), "decisions.log")
+	require.NoError(t, os.WriteFile(logPath, buf.Bytes(), 0o600))
+
+	return logPath
+}
+
+func candidateBundle(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(candidatePolicy), 0o600))
+	return dir
+}
+
+func TestReplay(t *testing.T) {
+	noPolicyContext := recordedDecision("5", "1", map[string]bool{"allowed": true})
+	noPolicyContext.Policy.Context = nil
+
+	query := recordedDecision("6", "1", map[string]bool{"allowed": false})
+	query.Annotations = map[string]string{decisionlog.AnnotationAPI: decisionlog.APIQuery}
+
+	logPath := writeDecisionLog(t,
+		// allowed now.
+		recordedDecision("1", "1", map[string]bool{"allowed": false}),
+		// unchanged.
+		recordedDecision("2", "2", map[string]bool{"allowed": false}),
+		// both outcomes changed.
+		recordedDecision("3", "2", map[string]bool{"visible": true, "allowed": true}),
+		// only the visible outcome changed.
+		recordedDecision("4", "1", map[string]bool{"visible": true, "allowed": true}),
+		// fails to evaluate.
+		noPolicyContext,
+		// not an Is decision, not replayed.
+		query,
+	)
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	c := &cc.CommonCtx{
+		Context: context.Background(),
+		UI:      clui.NewUIWithOutputErrorAndInput(out, errOut, nil),
+		Config:  &cc.CLIConfig{Active: cc.ActiveConfig{ConfigFile: filepath.Join(t.TempDir(), "missing.yaml")}},
+	}
+
+	cmd := &ReplayCmd{
+		LogFileFlags: decisions.LogFileFlags{File: logPath},
+		Bundle:       candidateBundle(t),
+		Format:       "json",
+	}
+	require.NoError(t, cmd.Run(c))
+
+	results := map[string]*replayResult{}
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var result replayResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &result))
+		results[result.ID] = &result
+	}
+	require.Len(t, results, 4, out.String())
+
+	assert.Equal(t, []outcomeChange{{Decision: "allowed", Recorded: false, Replayed: true}}, results["1"].Changes)
+	assert.Equal(t, "todo", results["1"].Path)
+	assert.Equal(t, "u1", results["1"].User)
+
+	assert.NotContains(t, results, "2", "unchanged decision reported")
+
+	assert.Equal(t, []outcomeChange{
+		{Decision: "allowed", Recorded: true, Replayed: false},
+		{Decision: "visible", Recorded: true, Replayed: false},
+	}, results["3"].Changes, "changes are sorted by decision")
+
+	assert.Equal(t, []outcomeChange{{Decision: "visible", Recorded: true, Replayed: false}}, results["4"].Changes)
+
+	assert.NotEmpty(t, results["5"].Error)
+	assert.Empty(t, results["5"].Changes)
+
+	assert.Contains(t, errOut.String(), "replayed 5 decisions: 3 changed, 1 failed")
+
+	// changed outcomes fail the command when requested.
+	cmd.FailOnChange = true
+	assert.Error(t, cmd.Run(c))
+}
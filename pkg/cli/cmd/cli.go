@@ -5,6 +5,7 @@ import (
 	"github.com/aserto-dev/topaz/pkg/cli/cmd/authorizer"
 	"github.com/aserto-dev/topaz/pkg/cli/cmd/certs"
 	"github.com/aserto-dev/topaz/pkg/cli/cmd/configure"
+	"github.com/aserto-dev/topaz/pkg/cli/cmd/decisions"
 	"github.com/aserto-dev/topaz/pkg/cli/cmd/directory"
 	"github.com/aserto-dev/topaz/pkg/cli/cmd/templates"
 	"github.com/aserto-dev/topaz/pkg/cli/cmd/topaz"
@@ -28,6 +29,7 @@ type CLI struct {
 	Console    topaz.ConsoleCmd         `cmd:"" help:"open topaz console in the browser"`
 	Directory  directory.DirectoryCmd   `cmd:"" aliases:"ds" help:"directory commands"`
 	Authorizer authorizer.AuthorizerCmd `cmd:"" aliases:"az" help:"authorizer commands"`
+	Decisions  decisions.DecisionsCmd   `cmd:"" help:"query decision log files"`
 	Certs      certs.CertsCmd           `cmd:"" help:"certificate management"`
 	Install    topaz.InstallCmd         `cmd:"" help:"install topaz container"`
 	Uninstall  topaz.UninstallCmd       `cmd:"" help:"uninstall topaz container"`
@@ -0,0 +1,342 @@
+package decisions
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var errNoLogFile = errors.New("decision log file not found")
+
+type DecisionsCmd struct {
+	Tail  TailCmd  `cmd:"" help:"print the latest decisions and follow new decisions"`
+	Query QueryCmd `cmd:"" help:"query decisions"`
+	Stats StatsCmd `cmd:"" help:"summarize allow and deny decisions per path and per user"`
+}
+
+// LogFileFlags selects the decision log file, written by the file decision logger.
+type LogFileFlags struct {
+	File string `name:"file" help:"decision log file path (default: log_file_path of the active configuration)"`
+}
+
+// LogPath returns the decision log file, defaulting to the file decision logger path of the active configuration.
+func (f *LogFileFlags) LogPath(c *cc.CommonCtx) (string, error) {
+	if f.File != "" {
+		return f.File, nil
+	}
+
+	if _, err := os.Stat(c.Config.Active.ConfigFile); errors.Is(err, os.ErrNotExist) {
+		return "", errors.Errorf("%s does not exist, use --file to set the decision log file", c.Config.Active.ConfigFile)
+	}
+
+	cfg, err := config.LoadConfiguration(c.Config.Active.ConfigFile)
+	if err != nil {
+		return "", err
+	}
+
+	logPath, ok := cfg.Configuration.DecisionLogger.Config["log_file_path"].(string)
+	if cfg.Configuration.DecisionLogger.Type != "file" || !ok || logPath == "" {
+		return "", errors.Errorf("%s does not configure a file decision logger, use --file to set the decision log file", c.Config.Active.ConfigFile)
+	}
+
+	return logPath, nil
+}
+
+// record is a decision as written by the file decision logger.
+type record struct {
+	ID        string `json:"id"`
+	Timestamp struct {
+		Seconds int64 `json:"seconds"`
+		Nanos   int64 `json:"nanos"`
+	} `json:"timestamp"`
+	Path string `json:"path"`
+	User struct {
+		Context struct {
+			Identity string `json:"identity"`
+			Type     int    `json:"type"`
+		} `json:"context"`
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	} `json:"user"`
+	Policy struct {
+		RegistryService string `json:"registry_service"`
+		RegistryImage   string `json:"registry_image"`
+		RegistryTag     string `json:"registry_tag"`
+		PolicyInstance  struct {
+			Name          string `json:"name"`
+			InstanceLabel string `json:"instance_label"`
+		} `json:"policy_instance"`
+	} `json:"policy"`
+	Outcomes    map[string]bool   `json:"outcomes"`
+	Annotations map[string]string `json:"annotations"`
+	TenantID    string            `json:"tenant_id"`
+
+	raw json.RawMessage
+}
+
+func (r *record) time() time.Time {
+	return time.Unix(r.Timestamp.Seconds, r.Timestamp.Nanos).UTC()
+}
+
+// allowed reports whether all outcomes of the decision are true.
+func (r *record) allowed() bool {
+	if len(r.Outcomes) == 0 {
+		return false
+	}
+	for _, outcome := range r.Outcomes {
+		if !outcome {
+			return false
+		}
+	}
+	return true
+}
+
+// isDecision reports whether the record is an Is decision, Query, DecisionTree and Compile records have no outcomes.
+func (r *record) isDecision() bool {
+	return decisionlog.DecisionAPI(r.Annotations) == decisionlog.APIIs
+}
+
+// outcome returns allow or deny for Is decisions, and the API of other records.
+func (r *record) outcome() string {
+	if !r.isDecision() {
+		return decisionlog.DecisionAPI(r.Annotations)
+	}
+	if r.allowed() {
+		return "allow"
+	}
+	return "deny"
+}
+
+func (r *record) user() string {
+	switch {
+	case r.User.Email != "":
+		return r.User.Email
+	case r.User.ID != "":
+		return r.User.ID
+	default:
+		return r.User.Context.Identity
+	}
+}
+
+func (r *record) policy() string {
+	if r.Policy.PolicyInstance.Name == "" {
+		return ""
+	}
+	return config.PolicyInstanceKey(r.Policy.PolicyInstance.Name, r.Policy.PolicyInstance.InstanceLabel)
+}
+
+func (r *record) outcomes() string {
+	names := make([]string, 0, len(r.Outcomes))
+	for name, outcome := range r.Outcomes {
+		if outcome {
+			names = append(names, name+"=true")
+		} else {
+			names = append(names, name+"=false")
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// decision converts the record into the logged decision.
+func (r *record) decision() (*api.Decision, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(r.raw, &fields); err != nil {
+		return nil, err
+	}
+
+	// the file logger encodes the timestamp as seconds and nanos, protojson expects RFC3339.
+	ts, err := json.Marshal(r.time().Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, err
+	}
+	fields["timestamp"] = ts
+
+	buf, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var d api.Decision
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(buf, &d); err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// parseRecord parses a line of the decision log, the file logger writes the decision
+// as the message of a JSON log line.
+func parseRecord(line []byte) (*record, error) {
+	var entry struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return nil, err
+	}
+
+	r := &record{raw: json.RawMessage(entry.Message)}
+	if err := json.Unmarshal(r.raw, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// logFiles returns the rotated backups of the decision log file, oldest first, followed by the decision log file.
+func logFiles(logPath string) ([]string, error) {
+	ext := filepath.Ext(logPath)
+	prefix := strings.TrimSuffix(logPath, ext) + "-"
+
+	backups, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return nil, err
+	}
+
+	// backups are named <name>-<timestamp><ext>, the timestamp sorts chronologically.
+	sort.Strings(backups)
+
+	if _, err := os.Stat(logPath); err == nil {
+		backups = append(backups, logPath)
+	}
+
+	if len(backups) == 0 {
+		return nil, errors.Wrap(errNoLogFile, logPath)
+	}
+
+	return backups, nil
+}
+
+// readRecords calls fn for each decision of the reader, lines that are not decisions are skipped.
+func readRecords(r io.Reader, fn func(*record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		rec, err := parseRecord(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// readLogFiles calls fn for each decision of the decision log file and its rotated backups.
+func readLogFiles(logPath string, fn func(*record) error) error {
+	files, err := logFiles(logPath)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := readLogFile(file, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadDecisions calls fn for each decision of the decision log file and its rotated backups, oldest first.
+func ReadDecisions(logPath string, fn func(*api.Decision) error) error {
+	return readLogFiles(logPath, func(r *record) error {
+		d, err := r.decision()
+		if err != nil {
+			return nil
+		}
+		return fn(d)
+	})
+}
+
+func readLogFile(file string, fn func(*record) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return readRecords(f, fn)
+}
+
+// FilterFlags select decisions.
+type FilterFlags struct {
+	Since   string `name:"since" help:"only decisions after this time (RFC3339) or duration ago (e.g. 1h)"`
+	Until   string `name:"until" help:"only decisions before this time (RFC3339) or duration ago (e.g. 10m)"`
+	Path    string `name:"path" help:"only decisions of the policy path, glob patterns are supported (e.g. todoApp.GET.*)"`
+	User    string `name:"user" help:"only decisions of the user id or email"`
+	Outcome string `name:"outcome" enum:",allow,deny" default:"" help:"only allow or deny decisions"`
+	Policy  string `name:"policy" help:"only decisions of the policy instance (name or name/instance_label)"`
+}
+
+type recordFilter func(*record) bool
+
+func (f *FilterFlags) filter() (recordFilter, error) {
+	now := time.Now()
+
+	since, err := parseTime(f.Since, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid --since")
+	}
+
+	until, err := parseTime(f.Until, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid --until")
+	}
+
+	if _, err := filepath.Match(f.Path, ""); err != nil {
+		return nil, errors.Wrap(err, "invalid --path")
+	}
+
+	return func(r *record) bool {
+		ts := r.time()
+		if !since.IsZero() && ts.Before(since) {
+			return false
+		}
+		if !until.IsZero() && ts.After(until) {
+			return false
+		}
+		if f.Path != "" {
+			if ok, _ := filepath.Match(f.Path, r.Path); !ok {
+				return false
+			}
+		}
+		if f.User != "" && f.User != r.User.ID && f.User != r.User.Email {
+			return false
+		}
+		if f.Outcome != "" && f.Outcome != r.outcome() {
+			return false
+		}
+		if f.Policy != "" && f.Policy != r.Policy.PolicyInstance.Name && f.Policy != r.policy() {
+			return false
+		}
+		return true
+	}, nil
+}
+
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
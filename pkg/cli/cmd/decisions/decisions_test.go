@@ -0,0 +1,235 @@
+package decisions
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/clui"
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// logLine returns the decision as written by the file decision logger.
+func logLine(t *testing.T, d *api.Decision) string {
+	buf, err := json.Marshal(d)
+	require.NoError(t, err)
+
+	line, err := json.Marshal(map[string]string{"level": "info", "message": string(buf)})
+	require.NoError(t, err)
+
+	return string(line)
+}
+
+func testDecision(id, path string, user *api.DecisionUser, allowed bool, ts time.Time) *api.Decision {
+	return &api.Decision{
+		Id:        id,
+		Timestamp: timestamppb.New(ts),
+		Path:      path,
+		User:      user,
+		Policy:    &api.DecisionPolicy{},
+		Outcomes:  map[string]bool{"allowed": allowed},
+	}
+}
+
+// writeDecisionLog writes a decision log file and a rotated backup, the backup holds the oldest decision.
+func writeDecisionLog(t *testing.T) string {
+	now := time.Now()
+	alice := &api.DecisionUser{Id: "alice", Email: "alice@test"}
+	bob := &api.DecisionUser{Id: "bob"}
+
+	first := testDecision("1", "todoApp.GET.todos", alice, true, now.Add(-2*time.Hour))
+	first.Policy.PolicyInstance = &api.PolicyInstance{Name: "todo", InstanceLabel: "todo"}
+
+	query := &api.Decision{
+		Id:          "4",
+		Timestamp:   timestamppb.New(now.Add(-10 * time.Minute)),
+		Policy:      &api.DecisionPolicy{},
+		User:        alice,
+		Annotations: map[string]string{decisionlog.AnnotationAPI: decisionlog.APIQuery},
+	}
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "decisions.log")
+
+	backup := logLine(t, first) + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "decisions-2024-01-01T00-00-00.000.log"), []byte(backup), 0o600))
+
+	current := strings.Join([]string{
+		`{"level":"info","message":"not a decision"}`,
+		logLine(t, testDecision("2", "todoApp.POST.todos", alice, false, now.Add(-time.Hour))),
+		logLine(t, testDecision("3", "todoApp.GET.todos", bob, true, now.Add(-30*time.Minute))),
+		logLine(t, query),
+	}, "\n") + "\n"
+	require.NoError(t, os.WriteFile(logPath, []byte(current), 0o600))
+
+	return logPath
+}
+
+func testContext() (*cc.CommonCtx, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return &cc.CommonCtx{Context: context.Background(), UI: clui.NewUIWithOutput(out)}, out
+}
+
+// csvIDs returns the decision IDs of the CSV output.
+func csvIDs(t *testing.T, out *bytes.Buffer) []string {
+	rows, err := csv.NewReader(out).ReadAll()
+	require.NoError(t, err)
+
+	ids := []string{}
+	if len(rows) == 0 {
+		return ids
+	}
+
+	require.Equal(t, columns, rows[0])
+	for _, row := range rows[1:] {
+		ids = append(ids, row[len(row)-1])
+	}
+	return ids
+}
+
+func TestQuery(t *testing.T) {
+	logPath := writeDecisionLog(t)
+
+	tests := []struct {
+		name   string
+		filter FilterFlags
+		limit  int
+		ids    []string
+	}{
+		{name: "all decisions, oldest first", ids: []string{"1", "2", "3", "4"}},
+		{name: "path", filter: FilterFlags{Path: "todoApp.GET.*"}, ids: []string{"1", "3"}},
+		{name: "user email", filter: FilterFlags{User: "alice@test"}, ids: []string{"1", "2", "4"}},
+		{name: "user id", filter: FilterFlags{User: "bob"}, ids: []string{"3"}},
+		{name: "allow", filter: FilterFlags{Outcome: "allow"}, ids: []string{"1", "3"}},
+		{name: "deny", filter: FilterFlags{Outcome: "deny"}, ids: []string{"2"}},
+		{name: "since duration", filter: FilterFlags{Since: "45m"}, ids: []string{"3", "4"}},
+		{name: "until duration", filter: FilterFlags{Until: "45m"}, ids: []string{"1", "2"}},
+		{name: "policy name", filter: FilterFlags{Policy: "todo"}, ids: []string{"1"}},
+		{name: "policy instance", filter: FilterFlags{Policy: "todo/todo"}, ids: []string{"1"}},
+		{name: "no match", filter: FilterFlags{Path: "other.*"}, ids: []string{}},
+		{name: "limit", limit: 2, ids: []string{"1", "2"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, out := testContext()
+
+			cmd := &QueryCmd{
+				LogFileFlags: LogFileFlags{File: logPath},
+				FilterFlags:  tc.filter,
+				Format:       formatCSV,
+				Limit:        tc.limit,
+			}
+			require.NoError(t, cmd.Run(c))
+
+			assert.Equal(t, tc.ids, csvIDs(t, out))
+		})
+	}
+}
+
+func TestQueryJSON(t *testing.T) {
+	logPath := writeDecisionLog(t)
+	c, out := testContext()
+
+	cmd := &QueryCmd{LogFileFlags: LogFileFlags{File: logPath}, FilterFlags: FilterFlags{Outcome: "deny"}, Format: formatJSON}
+	require.NoError(t, cmd.Run(c))
+
+	// decisions are printed as logged.
+	var d api.Decision
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(out.Bytes()), &d))
+	assert.Equal(t, "2", d.Id)
+	assert.Equal(t, map[string]bool{"allowed": false}, d.Outcomes)
+}
+
+func TestQueryInvalidFilter(t *testing.T) {
+	logPath := writeDecisionLog(t)
+
+	for _, filter := range []FilterFlags{{Since: "yesterday"}, {Until: "1x"}, {Path: "["}} {
+		c, _ := testContext()
+		cmd := &QueryCmd{LogFileFlags: LogFileFlags{File: logPath}, FilterFlags: filter, Format: formatCSV}
+		assert.Error(t, cmd.Run(c), "%+v", filter)
+	}
+
+	c, _ := testContext()
+	cmd := &QueryCmd{LogFileFlags: LogFileFlags{File: filepath.Join(t.TempDir(), "missing.log")}, Format: formatCSV}
+	assert.ErrorIs(t, cmd.Run(c), errNoLogFile)
+}
+
+// statsRows returns the rows of the stats table, by their first column.
+func statsRows(out *bytes.Buffer) map[string][]string {
+	rows := map[string][]string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		fields := strings.Fields(strings.NewReplacer("|", " ", "+", " ", "-", " ").Replace(line))
+		if len(fields) == 4 {
+			rows[fields[0]] = fields[1:]
+		}
+	}
+	return rows
+}
+
+func TestStats(t *testing.T) {
+	logPath := writeDecisionLog(t)
+
+	tests := []struct {
+		name   string
+		by     string
+		filter FilterFlags
+		rows   map[string][]string
+	}{
+		{
+			name: "by path",
+			by:   "path",
+			rows: map[string][]string{
+				"todoApp.GET.todos":  {"2", "0", "2"},
+				"todoApp.POST.todos": {"0", "1", "1"},
+				"TOTAL":              {"2", "1", "3"},
+			},
+		},
+		{
+			name: "by user",
+			by:   "user",
+			rows: map[string][]string{
+				"alice@test": {"1", "1", "2"},
+				"bob":        {"1", "0", "1"},
+				"TOTAL":      {"2", "1", "3"},
+			},
+		},
+		{
+			name:   "filtered",
+			by:     "path",
+			filter: FilterFlags{Since: "90m"},
+			rows: map[string][]string{
+				"todoApp.GET.todos":  {"1", "0", "1"},
+				"todoApp.POST.todos": {"0", "1", "1"},
+				"TOTAL":              {"1", "1", "2"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, out := testContext()
+
+			cmd := &StatsCmd{LogFileFlags: LogFileFlags{File: logPath}, FilterFlags: tc.filter, By: tc.by}
+			require.NoError(t, cmd.Run(c))
+
+			rows := statsRows(out)
+			for key, row := range tc.rows {
+				assert.Equal(t, row, rows[key], key)
+			}
+			// Query, DecisionTree and Compile records have no outcomes and are not counted.
+			assert.Len(t, rows, len(tc.rows)+1, out.String())
+		})
+	}
+}
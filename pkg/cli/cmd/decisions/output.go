@@ -0,0 +1,115 @@
+package decisions
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+var columns = []string{"Time", "Path", "User", "Outcome", "Decisions", "Policy", "ID"}
+
+func (r *record) row() []string {
+	return []string{
+		r.time().Format(time.RFC3339),
+		r.Path,
+		r.user(),
+		r.outcome(),
+		r.outcomes(),
+		r.policy(),
+		r.ID,
+	}
+}
+
+// printer writes decisions in the selected output format.
+type printer interface {
+	print(*record) error
+	flush() error
+}
+
+func newPrinter(c *cc.CommonCtx, format string) printer {
+	switch format {
+	case formatJSON:
+		return &jsonPrinter{w: c.UI.Output()}
+	case formatCSV:
+		w := csv.NewWriter(c.UI.Output())
+		return &csvPrinter{w: w}
+	default:
+		return &tablePrinter{c: c}
+	}
+}
+
+// jsonPrinter writes one decision per line, as logged.
+type jsonPrinter struct {
+	w io.Writer
+}
+
+func (p *jsonPrinter) print(r *record) error {
+	_, err := fmt.Fprintln(p.w, string(r.raw))
+	return err
+}
+
+func (p *jsonPrinter) flush() error {
+	return nil
+}
+
+type csvPrinter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (p *csvPrinter) print(r *record) error {
+	if !p.header {
+		p.header = true
+		if err := p.w.Write(columns); err != nil {
+			return err
+		}
+	}
+
+	if err := p.w.Write(r.row()); err != nil {
+		return err
+	}
+
+	p.w.Flush()
+	return p.w.Error()
+}
+
+func (p *csvPrinter) flush() error {
+	p.w.Flush()
+	return p.w.Error()
+}
+
+// tablePrinter collects the decisions and prints them as a table on flush.
+type tablePrinter struct {
+	c    *cc.CommonCtx
+	rows [][]string
+}
+
+func (p *tablePrinter) print(r *record) error {
+	p.rows = append(p.rows, r.row())
+	return nil
+}
+
+func (p *tablePrinter) flush() error {
+	if len(p.rows) == 0 {
+		return nil
+	}
+
+	table := p.c.UI.Normal().WithTable(columns...)
+	table.WithTableNoAutoWrapText()
+	for _, row := range p.rows {
+		table.WithTableRow(row...)
+	}
+	table.Do()
+
+	p.rows = nil
+	return nil
+}
@@ -0,0 +1,53 @@
+package decisions
+
+import (
+	"errors"
+
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+)
+
+var errLimitReached = errors.New("limit reached")
+
+type QueryCmd struct {
+	LogFileFlags
+	FilterFlags
+	Format string `name:"format" short:"o" enum:"table,json,csv" default:"table" help:"output format (table, json, csv)"`
+	Limit  int    `name:"limit" short:"n" default:"0" help:"maximum number of decisions, 0 for all"`
+}
+
+func (cmd *QueryCmd) Run(c *cc.CommonCtx) error {
+	logPath, err := cmd.LogPath(c)
+	if err != nil {
+		return err
+	}
+
+	match, err := cmd.filter()
+	if err != nil {
+		return err
+	}
+
+	p := newPrinter(c, cmd.Format)
+
+	count := 0
+	err = readLogFiles(logPath, func(r *record) error {
+		if !match(r) {
+			return nil
+		}
+
+		if err := p.print(r); err != nil {
+			return err
+		}
+
+		count++
+		if cmd.Limit > 0 && count >= cmd.Limit {
+			return errLimitReached
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errLimitReached) {
+		return err
+	}
+
+	return p.flush()
+}
@@ -0,0 +1,86 @@
+package decisions
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+)
+
+type StatsCmd struct {
+	LogFileFlags
+	FilterFlags
+	By string `name:"by" enum:"path,user" default:"path" help:"summarize decisions by path or user"`
+}
+
+type outcomeCount struct {
+	allow int
+	deny  int
+}
+
+func (cmd *StatsCmd) Run(c *cc.CommonCtx) error {
+	logPath, err := cmd.LogPath(c)
+	if err != nil {
+		return err
+	}
+
+	match, err := cmd.filter()
+	if err != nil {
+		return err
+	}
+
+	counts := map[string]*outcomeCount{}
+	total := outcomeCount{}
+
+	err = readLogFiles(logPath, func(r *record) error {
+		if !match(r) || !r.isDecision() {
+			return nil
+		}
+
+		key := r.Path
+		if cmd.By == "user" {
+			key = r.user()
+		}
+
+		count, ok := counts[key]
+		if !ok {
+			count = &outcomeCount{}
+			counts[key] = count
+		}
+
+		if r.allowed() {
+			count.allow++
+			total.allow++
+		} else {
+			count.deny++
+			total.deny++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	header := "Path"
+	if cmd.By == "user" {
+		header = "User"
+	}
+
+	table := c.UI.Normal().WithTable(header, "Allow", "Deny", "Total")
+	table.WithTableNoAutoWrapText()
+	for _, key := range keys {
+		count := counts[key]
+		table.WithTableRow(key, fmt.Sprint(count.allow), fmt.Sprint(count.deny), fmt.Sprint(count.allow+count.deny))
+	}
+	table.WithTableRow("TOTAL", fmt.Sprint(total.allow), fmt.Sprint(total.deny), fmt.Sprint(total.allow+total.deny))
+	table.Do()
+
+	return nil
+}
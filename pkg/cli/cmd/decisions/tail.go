@@ -0,0 +1,215 @@
+package decisions
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+)
+
+const followInterval = 500 * time.Millisecond
+
+type TailCmd struct {
+	LogFileFlags
+	FilterFlags
+	Follow bool   `name:"follow" short:"f" help:"follow new decisions, across log file rotations"`
+	Lines  int    `name:"lines" short:"n" default:"10" help:"number of latest decisions to print"`
+	Format string `name:"format" short:"o" enum:"table,json,csv" default:"table" help:"output format (table, json, csv)"`
+}
+
+func (cmd *TailCmd) Run(c *cc.CommonCtx) error {
+	logPath, err := cmd.LogPath(c)
+	if err != nil {
+		return err
+	}
+
+	match, err := cmd.filter()
+	if err != nil {
+		return err
+	}
+
+	p := newPrinter(c, cmd.Format)
+
+	latest := make([]*record, 0, cmd.Lines)
+	err = readLogFiles(logPath, func(r *record) error {
+		if !match(r) || cmd.Lines <= 0 {
+			return nil
+		}
+		if len(latest) == cmd.Lines {
+			latest = latest[1:]
+		}
+		latest = append(latest, r)
+		return nil
+	})
+	if err != nil && !(cmd.Follow && errors.Is(err, errNoLogFile)) {
+		return err
+	}
+
+	for _, r := range latest {
+		if err := p.print(r); err != nil {
+			return err
+		}
+	}
+
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	if !cmd.Follow {
+		return nil
+	}
+
+	return follow(c.Context, logPath, func(r *record) error {
+		if !match(r) {
+			return nil
+		}
+		return p.print(r)
+	}, p.flush)
+}
+
+// follow calls fn for each decision appended to the log file, starting at its current end, and flush
+// after each poll of the log file. When the log file is rotated, the remainder of the rotated file is
+// read before following the new log file.
+func follow(ctx context.Context, logPath string, fn func(*record) error, flush func() error) error {
+	t := &tailer{path: logPath, fn: fn}
+	defer t.close()
+
+	if err := t.open(io.SeekEnd); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := t.poll(); err != nil {
+				return err
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type tailer struct {
+	path    string
+	fn      func(*record) error
+	file    *os.File
+	info    os.FileInfo
+	reader  *bufio.Reader
+	offset  int64
+	partial []byte
+}
+
+func (t *tailer) open(whence int) error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	offset, err := f.Seek(0, whence)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	t.file, t.info, t.offset, t.partial = f, info, offset, nil
+	t.reader = bufio.NewReader(f)
+
+	return nil
+}
+
+func (t *tailer) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+func (t *tailer) poll() error {
+	if t.file == nil {
+		if err := t.open(io.SeekStart); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	info, err := os.Stat(t.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// the log file is being rotated.
+		return t.read()
+	case err != nil:
+		return err
+	case !os.SameFile(info, t.info):
+		// the log file was rotated, finish reading the rotated file and follow the new log file.
+		if err := t.read(); err != nil {
+			return err
+		}
+		t.close()
+		if err := t.open(io.SeekStart); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return t.read()
+	case info.Size() < t.offset:
+		// the log file was truncated.
+		t.close()
+		if err := t.open(io.SeekStart); err != nil {
+			return err
+		}
+		return t.read()
+	default:
+		return t.read()
+	}
+}
+
+// read reads the complete lines appended to the file.
+func (t *tailer) read() error {
+	if t.file == nil {
+		return nil
+	}
+
+	for {
+		line, err := t.reader.ReadBytes('\n')
+		t.offset += int64(len(line))
+
+		if errors.Is(err, io.EOF) {
+			t.partial = append(t.partial, line...)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(t.partial) > 0 {
+			line = append(t.partial, line...)
+			t.partial = nil
+		}
+
+		rec, err := parseRecord(bytes.TrimSpace(line))
+		if err != nil {
+			continue
+		}
+		if err := t.fn(rec); err != nil {
+			return err
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 
 	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/filter"
 	"github.com/mitchellh/mapstructure"
 	"github.com/open-policy-agent/opa/plugins"
 	"github.com/open-policy-agent/opa/util"
@@ -13,17 +14,22 @@ import (
 
 type Factory struct {
 	logger decisionlog.DecisionLogger
+	filter *filter.Filter
+	apis   []string
 }
 
-func NewFactory(logger decisionlog.DecisionLogger) Factory {
+// NewFactory returns the decision log plugin factory, apis lists the authorizer APIs whose decisions are logged (default: is).
+func NewFactory(logger decisionlog.DecisionLogger, filter *filter.Filter, apis []string) Factory {
 	return Factory{
 		logger: logger,
+		filter: filter,
+		apis:   apis,
 	}
 }
 
 func (f Factory) New(m *plugins.Manager, config interface{}) plugins.Plugin {
 	cfg := config.(*Config)
-	return newDecisionLogger(cfg, m, f.logger)
+	return newDecisionLogger(cfg, m, f.logger, f.filter, f.apis)
 }
 
 func (Factory) Validate(m *plugins.Manager, config []byte) (interface{}, error) {
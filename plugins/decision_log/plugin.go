@@ -5,6 +5,7 @@ import (
 
 	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
 	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/filter"
 
 	"github.com/open-policy-agent/opa/plugins"
 )
@@ -28,14 +29,28 @@ type DecisionLogsPlugin struct {
 	manager *plugins.Manager
 	cfg     *Config
 	logger  decisionlog.DecisionLogger
+	filter  *filter.Filter
+	apis    map[string]bool
 }
 
-func newDecisionLogger(cfg *Config, manager *plugins.Manager, logger decisionlog.DecisionLogger) *DecisionLogsPlugin {
-	return &DecisionLogsPlugin{
+func newDecisionLogger(cfg *Config, manager *plugins.Manager, logger decisionlog.DecisionLogger, filter *filter.Filter, apis []string) *DecisionLogsPlugin {
+	plugin := &DecisionLogsPlugin{
 		manager: manager,
 		cfg:     cfg,
 		logger:  logger,
+		filter:  filter,
+		apis:    map[string]bool{},
 	}
+
+	if apis == nil {
+		apis = []string{decisionlog.APIIs}
+	}
+
+	for _, api := range apis {
+		plugin.apis[api] = true
+	}
+
+	return plugin
 }
 func (plugin *DecisionLogsPlugin) Start(ctx context.Context) error {
 	plugin.manager.UpdatePluginStatus(PluginName, &plugins.Status{State: plugins.StateOK})
@@ -48,6 +63,11 @@ func (plugin *DecisionLogsPlugin) Reconfigure(ctx context.Context, config interf
 	plugin.cfg = config.(*Config)
 }
 
+// Audits reports whether the decisions of the authorizer API are logged.
+func (plugin *DecisionLogsPlugin) Audits(api string) bool {
+	return plugin.cfg.Enabled && plugin.logger != nil && plugin.apis[api]
+}
+
 func (plugin *DecisionLogsPlugin) Log(ctx context.Context, d *api.Decision) error {
 	if !plugin.cfg.Enabled || plugin.logger == nil {
 		return nil
@@ -58,6 +78,17 @@ func (plugin *DecisionLogsPlugin) Log(ctx context.Context, d *api.Decision) erro
 	d.Policy.RegistryTag = plugin.cfg.PolicyInfo.RegistryTag
 	d.Policy.RegistryDigest = plugin.cfg.PolicyInfo.Digest
 
+	// drop, sample and redact the decision before any decision logger sees it.
+	// a decision that can't be redacted is not logged, it must not fail the evaluation.
+	d, err := plugin.filter.Apply(d)
+	if err != nil {
+		plugin.manager.Logger().Error("failed to filter decision: %v", err)
+		return nil
+	}
+	if d == nil {
+		return nil
+	}
+
 	return plugin.logger.Log(d)
 }
 
@@ -9,6 +9,7 @@ import (
 	"github.com/aserto-dev/go-edge-ds/pkg/datasync"
 	"github.com/aserto-dev/go-edge-ds/pkg/directory"
 	"github.com/aserto-dev/go-grpc/aserto/api/v2"
+	topazdir "github.com/aserto-dev/topaz/directory"
 	topaz "github.com/aserto-dev/topaz/pkg/cc/config"
 	"google.golang.org/grpc"
 
@@ -238,6 +239,9 @@ func (p *Plugin) task(mode api.SyncMode) {
 		p.logger.Error().Err(err).Msg(syncTask)
 	}
 
+	// a failed sync can still have written part of the directory.
+	topazdir.NotifyChange()
+
 	p.logger.Info().Str(status, finished).Msg(syncTask)
 }
 
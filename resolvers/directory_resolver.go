@@ -9,3 +9,16 @@ import (
 type DirectoryResolver interface {
 	GetDS(ctx context.Context) (dsr3.ReaderClient, error)
 }
+
+type builtinKey struct{}
+
+// WithBuiltin returns the context of the directory calls made by the builtin.
+func WithBuiltin(ctx context.Context, builtin string) context.Context {
+	return context.WithValue(ctx, builtinKey{}, builtin)
+}
+
+// BuiltinFromContext returns the builtin making the directory calls of the context, empty when it is not known.
+func BuiltinFromContext(ctx context.Context) string {
+	builtin, _ := ctx.Value(builtinKey{}).(string)
+	return builtin
+}
@@ -2,6 +2,7 @@ package resolvers
 
 import (
 	"context"
+	"sync"
 
 	runtime "github.com/aserto-dev/runtime"
 )
@@ -13,4 +14,48 @@ type RuntimeResolver interface {
 	ReloadRuntime(ctx context.Context, tenantID, policyName, instanceLabel string) error
 	ListRuntimes(ctx context.Context) (map[string]*runtime.Runtime, error)
 	UnloadRuntime(ctx context.Context, tenantID, policyName, instanceLabel string)
+	// ShadowRuntime returns the shadow runtime of the policy instance, nil when the policy instance is not shadowed.
+	ShadowRuntime(ctx context.Context, policyName, instanceLabel string) (*runtime.Runtime, error)
+}
+
+type stoppedCallback struct {
+	fn func(*runtime.Runtime)
+}
+
+var stopped struct {
+	mu        sync.RWMutex
+	callbacks []*stoppedCallback
+}
+
+// OnRuntimeStopped registers a function called with every runtime stopped by a runtime resolver,
+// so that the state kept per runtime can be released. The returned function unregisters it.
+func OnRuntimeStopped(fn func(*runtime.Runtime)) func() {
+	stopped.mu.Lock()
+	defer stopped.mu.Unlock()
+
+	cb := &stoppedCallback{fn: fn}
+	stopped.callbacks = append(stopped.callbacks, cb)
+
+	return func() {
+		stopped.mu.Lock()
+		defer stopped.mu.Unlock()
+
+		for i, c := range stopped.callbacks {
+			if c == cb {
+				stopped.callbacks = append(stopped.callbacks[:i:i], stopped.callbacks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// RuntimeStopped calls the functions registered with OnRuntimeStopped, it is called by runtime resolvers
+// after a runtime is reloaded, unloaded or cleaned up.
+func RuntimeStopped(rt *runtime.Runtime) {
+	stopped.mu.RLock()
+	defer stopped.mu.RUnlock()
+
+	for _, cb := range stopped.callbacks {
+		cb.fn(rt)
+	}
 }